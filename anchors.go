@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultAnchors is the conventional name of the configuration value holding shared YAML
+// anchors. See WithAnchors.
+const DefaultAnchors = "_anchors.yaml"
+
+// WithAnchors enables shared YAML anchors. The anchors defined in configuration value name
+// can be referenced with aliases, and merged with "<<", in every value decoded by
+// InterfaceYaml and YamlNode. For example, with WithAnchors(DefaultAnchors):
+//
+//		# _anchors.yaml
+//		defaults: &defaults
+//		  timeout: 5s
+//		  retries: 3
+//
+//		# billing.yaml
+//		client:
+//		  <<: *defaults
+//		  retries: 5
+//
+// Decoding billing.yaml fails with an *AnchorError if it references an anchor that is
+// not defined. If the named value does not exist, values are decoded as usual. An empty
+// name disables shared anchors, which is the default.
+func WithAnchors(name string) Option {
+	return func(o *options) {
+		o.anchors = name
+	}
+}
+
+// AnchorError is returned when a YAML value references an anchor that is defined neither
+// in it nor in the shared anchors.
+type AnchorError struct {
+	Name    string // Name is the name of the configuration value.
+	Anchor  string // Anchor is the undefined anchor.
+	Anchors string // Anchors is the name of the configuration value holding the shared anchors.
+}
+
+func (e *AnchorError) Error() string {
+	return fmt.Sprintf("config: %s references anchor %q, which is not defined in it or in %s", e.Name, e.Anchor, e.Anchors)
+}
+
+func (e *AnchorError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+const (
+	anchorsDocKey = "_config_anchors"
+	valueDocKey   = "_config_value"
+)
+
+var unknownAnchorRegexp = regexp.MustCompile(`unknown anchor '([^']*)' referenced`)
+
+// parseAnchored parses data, the YAML of configuration value n, with the shared anchors
+// in anchors available to it. Both documents are nested in a single document, since
+// anchors cannot be referenced across documents, and line numbers are then restored.
+func parseAnchored(n, anchorsName string, anchors, data []byte) (*yaml.Node, error) {
+	var buf bytes.Buffer
+	buf.WriteString(anchorsDocKey + ":\n")
+	indent(&buf, anchors)
+	buf.WriteString(valueDocKey + ":\n")
+	indent(&buf, data)
+	offset := bytes.Count(anchors, []byte("\n")) + 2
+	if len(anchors) > 0 && anchors[len(anchors)-1] != '\n' {
+		offset++
+	}
+
+	var root yaml.Node
+	err := yaml.Unmarshal(buf.Bytes(), &root)
+	if err != nil {
+		if m := unknownAnchorRegexp.FindStringSubmatch(err.Error()); m != nil {
+			return nil, &AnchorError{Name: n, Anchor: m[1], Anchors: anchorsName}
+		}
+		return nil, shiftYamlError(err, offset, anchorsName)
+	}
+
+	value := root.Content[0].Content[3]
+	shiftNode(value, offset)
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{value}}, nil
+}
+
+// indent writes each line of b to buf, indented by two spaces.
+func indent(buf *bytes.Buffer, b []byte) {
+	for _, l := range strings.SplitAfter(string(b), "\n") {
+		if l == "" {
+			continue
+		}
+		buf.WriteString("  ")
+		buf.WriteString(l)
+	}
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+}
+
+// shiftNode moves n and its descendants up by offset lines and left by the indentation
+// added by indent. Aliased nodes belong to the shared anchors and are left unchanged.
+func shiftNode(n *yaml.Node, offset int) {
+	n.Line -= offset
+	if n.Column > 2 {
+		n.Column -= 2
+	}
+	for _, c := range n.Content {
+		shiftNode(c, offset)
+	}
+}
+
+// shiftYamlError restores the line numbers in err, which was returned while parsing a
+// value nested by parseAnchored.
+func shiftYamlError(err error, offset int, anchorsName string) error {
+	msg := yamlLineRegexp.ReplaceAllStringFunc(err.Error(), func(s string) string {
+		m := yamlLineRegexp.FindStringSubmatch(s)
+		line, _ := strconv.Atoi(m[1])
+		if line < offset {
+			return fmt.Sprintf("%s line %d", anchorsName, line-1)
+		}
+		return "line " + strconv.Itoa(line-offset)
+	})
+	return errors.New(msg)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithAnchors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		DefaultAnchors: "defaults: &defaults\n  timeout: 5s\n  retries: 3\nhosts: &hosts [a, b]\n",
+		"billing.yaml": "client:\n  <<: *defaults\n  retries: 5\nhosts: *hosts\n",
+		"local.yaml":   "base: &base {x: 1}\ncopy: *base\n",
+		"unknown.yaml": "client: *missing\n",
+		"broken.yaml":  "a: 1\nb: [\n",
+	})
+	c := New(WithPath(dir), WithAnchors(DefaultAnchors))
+
+	type client struct {
+		Timeout string `yaml:"timeout"`
+		Retries int    `yaml:"retries"`
+	}
+	var got struct {
+		Client client   `yaml:"client"`
+		Hosts  []string `yaml:"hosts"`
+	}
+	if err := c.InterfaceYaml("billing.yaml", &got); err != nil {
+		t.Fatalf("InterfaceYaml() error = %v", err)
+	}
+	if want := (client{Timeout: "5s", Retries: 5}); got.Client != want || !reflect.DeepEqual(got.Hosts, []string{"a", "b"}) {
+		t.Errorf("InterfaceYaml() = %+v, want client %+v and hosts [a b]", got, want)
+	}
+
+	var local map[string]map[string]int
+	if err := c.InterfaceYaml("local.yaml", &local); err != nil || local["copy"]["x"] != 1 {
+		t.Errorf("InterfaceYaml() = %v, %v, want local anchors resolved", local, err)
+	}
+
+	node, err := c.YamlNode("billing.yaml")
+	if err != nil {
+		t.Fatalf("YamlNode() error = %v", err)
+	}
+	if k := node.Content[0].Content[2]; k.Value != "hosts" || k.Line != 4 || k.Column != 1 {
+		t.Errorf("YamlNode() key = %q at %d:%d, want %q at 4:1", k.Value, k.Line, k.Column, "hosts")
+	}
+
+	var v interface{}
+	var ae *AnchorError
+	if err := c.InterfaceYaml("unknown.yaml", &v); !errors.As(err, &ae) || ae.Anchor != "missing" || ae.Name != "unknown.yaml" {
+		t.Errorf("InterfaceYaml() error = %v, want *AnchorError for %q", err, "missing")
+	}
+
+	var de *DecodeError
+	if err := c.InterfaceYaml("broken.yaml", &v); !errors.As(err, &de) || de.Line != 2 {
+		t.Errorf("InterfaceYaml() error = %v, want *DecodeError on line 2", err)
+	}
+
+	if err := New(WithPath(dir)).InterfaceYaml("billing.yaml", &v); err == nil {
+		t.Errorf("InterfaceYaml() without anchors error = %v, wantErr %v", err, true)
+	}
+}
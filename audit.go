@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AuditRecord records a change to the loaded configuration values. See WithAudit.
+type AuditRecord struct {
+	Time       time.Time    `json:"time"`               // Time is when the values were committed.
+	Trigger    string       `json:"trigger"`            // Trigger is what caused the change, as in ChangeEvent, or "Load".
+	Tenant     string       `json:"tenant,omitempty"`   // Tenant is the tenant whose values changed, if any.
+	Generation uint64       `json:"generation"`         // Generation is the new value of Generation.
+	Path       string       `json:"path"`               // Path is the search path the values were loaded from.
+	Added      []string     `json:"added,omitempty"`    // Added are the names of the new values.
+	Removed    []string     `json:"removed,omitempty"`  // Removed are the names of the values that are gone.
+	Modified   []string     `json:"modified,omitempty"` // Modified are the names of the values whose data changed.
+	Entries    []AuditEntry `json:"entries"`            // Entries are every value now loaded, sorted by name.
+	Prev       string       `json:"prev"`               // Prev is the Hash of the previous record, or "" for the first.
+	Hash       string       `json:"hash"`               // Hash is the hex SHA-256 hash of the record, computed with Hash empty.
+}
+
+// AuditEntry records the provenance and content hash of a configuration value.
+type AuditEntry struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Source string `json:"source"`
+	SHA256 string `json:"sha256"`
+}
+
+// WithAudit registers fn to be called with an AuditRecord each time new values are
+// committed, including the initial load. Records only hold hashes of the data, so secrets
+// are not disclosed. Each record includes the hash of the one before it, so a trail of
+// records cannot be altered, reordered or have records removed from its middle without
+// VerifyAuditLog detecting it. Each Config starts a new chain.
+func WithAudit(fn func(AuditRecord)) Option {
+	return func(o *options) {
+		o.audit = append(o.audit, fn)
+	}
+}
+
+// WithAuditLog is WithAudit with a function that writes each record to w as a line of
+// JSON. Write errors are logged.
+func WithAuditLog(w io.Writer) Option {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return WithAudit(func(r AuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		err := enc.Encode(r)
+		if err != nil {
+			log.Printf("config: failed to write audit record: %v", err)
+		}
+	})
+}
+
+// VerifyAuditLog reads the JSON lines written by WithAuditLog from r and checks that each
+// record matches its hash and follows the record before it. A record with an empty Prev
+// starts a new chain, as a restarted process does.
+func VerifyAuditLog(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 64<<20)
+	prev := ""
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var rec AuditRecord
+		err := json.Unmarshal(sc.Bytes(), &rec)
+		if err != nil {
+			return fmt.Errorf("config: invalid audit record on line %d: %w", line, err)
+		}
+		if rec.Prev != "" && rec.Prev != prev {
+			return fmt.Errorf("config: audit record on line %d does not follow the record before it", line)
+		}
+		if hashAuditRecord(rec) != rec.Hash {
+			return fmt.Errorf("config: audit record on line %d does not match its hash", line)
+		}
+		prev = rec.Hash
+	}
+	return sc.Err()
+}
+
+// audit calls the audit functions of s with a record of the change from prev to st.
+func (s *store) audit(trigger string, prev map[string]entry, st *Staged, generation uint64, at time.Time) {
+	if len(s.options.audit) == 0 {
+		return
+	}
+
+	rec := AuditRecord{Time: at, Trigger: trigger, Tenant: s.tenant, Generation: generation, Path: st.path}
+	for _, n := range changedNames(prev, st.val) {
+		_, before := prev[n]
+		_, after := st.val[n]
+		switch {
+		case !before:
+			rec.Added = append(rec.Added, n)
+		case !after:
+			rec.Removed = append(rec.Removed, n)
+		default:
+			rec.Modified = append(rec.Modified, n)
+		}
+	}
+	rec.Entries = make([]AuditEntry, 0, len(st.val))
+	for n, e := range st.val {
+		rec.Entries = append(rec.Entries, AuditEntry{Name: n, Path: e.path, Source: e.source, SHA256: hashEntry(st.val, n)})
+	}
+	sort.Slice(rec.Entries, func(i, j int) bool { return rec.Entries[i].Name < rec.Entries[j].Name })
+
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	rec.Prev = s.auditHash
+	rec.Hash = hashAuditRecord(rec)
+	s.auditHash = rec.Hash
+	for _, fn := range s.options.audit {
+		fn(rec)
+	}
+}
+
+// hashAuditRecord returns the hex SHA-256 hash of the JSON encoding of rec with Hash empty.
+func hashAuditRecord(rec AuditRecord) string {
+	rec.Hash = ""
+	b, _ := json.Marshal(rec)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithAudit(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a": "1", "b": "2"})
+	var recs []AuditRecord
+	var buf bytes.Buffer
+	c := New(WithPath(dir), WithAudit(func(r AuditRecord) { recs = append(recs, r) }), WithAuditLog(&buf))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "a"), []byte("3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "c"), []byte("4"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recs) != 2 {
+		t.Fatalf("got %d audit records, want 2", len(recs))
+	}
+	first, second := recs[0], recs[1]
+	if first.Trigger != "Load" || !reflect.DeepEqual(first.Added, []string{"a", "b"}) || first.Prev != "" {
+		t.Errorf("first record = %+v, want a Load adding a and b", first)
+	}
+	if second.Trigger != "Reload" || second.Generation != 2 || second.Prev != first.Hash ||
+		!reflect.DeepEqual(second.Added, []string{"c"}) ||
+		!reflect.DeepEqual(second.Removed, []string{"b"}) ||
+		!reflect.DeepEqual(second.Modified, []string{"a"}) {
+		t.Errorf("second record = %+v, want a Reload following the first", second)
+	}
+	want := []AuditEntry{
+		{Name: "a", Path: filepath.Join(dir, "a"), Source: dir, SHA256: "4e07408562bedb8b60ce05c1decfe3ad16b72230967de01f640b7e4729b49fce"},
+		{Name: "c", Path: filepath.Join(dir, "c"), Source: dir, SHA256: "4b227777d4dd1fc61c6f884f48641d02b4d121d3fd328cb08b5531fcacdabf8a"},
+	}
+	if !reflect.DeepEqual(second.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", second.Entries, want)
+	}
+
+	if err := VerifyAuditLog(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Errorf("VerifyAuditLog() error = %v", err)
+	}
+	lines := strings.SplitAfter(buf.String(), "\n")
+	if err := VerifyAuditLog(strings.NewReader(lines[1])); err == nil {
+		t.Errorf("VerifyAuditLog() error = nil, want a missing record to be detected")
+	}
+	tampered := strings.Replace(buf.String(), `"trigger":"Reload"`, `"trigger":"poll"`, 1)
+	if err := VerifyAuditLog(strings.NewReader(tampered)); err == nil {
+		t.Errorf("VerifyAuditLog() error = nil, want a modified record to be detected")
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auth configures how a remote source authenticates to its backend. It returns a client
+// based on base that authenticates its requests, without modifying base. Remote sources
+// (HTTPSource, SpringCloudSource and AzureBlobSource) have an Auth field, and WithAuth sets
+// the Auth of the remote sources on the search path, so credentials for the config system
+// itself are configured the same way for every backend.
+type Auth func(base *http.Client) (*http.Client, error)
+
+// WithAuth sets the Auth of the remote sources created from search path entries, such as
+// "https://config.example.com/app.yaml".
+func WithAuth(a Auth) Option {
+	return func(o *options) {
+		o.auth = a
+	}
+}
+
+// authSource is implemented by sources whose Auth can be set by WithAuth.
+type authSource interface {
+	setAuth(a Auth)
+}
+
+// Auths returns an Auth that applies each of auths in order, e.g. a client certificate and
+// a bearer token.
+func Auths(auths ...Auth) Auth {
+	return func(base *http.Client) (*http.Client, error) {
+		result := base
+		for _, a := range auths {
+			var err error
+			result, err = a(result)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+}
+
+// BearerToken returns an Auth that sends token as a bearer token.
+func BearerToken(token string) Auth {
+	return bearerAuth(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// TokenFile returns an Auth that sends the content of file p as a bearer token. The file is
+// read for every request, so tokens that are rotated on disk, such as Kubernetes projected
+// service account tokens, are picked up.
+func TokenFile(p string) Auth {
+	return bearerAuth(func(context.Context) (string, error) {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	})
+}
+
+// TokenExchange returns an Auth for workload identity federation. It exchanges the token
+// in file subjectTokenFile (e.g. a Kubernetes projected service account token) for an
+// access token for audience at the OAuth 2.0 token exchange (RFC 8693) endpoint, and sends
+// the access token as a bearer token. Access tokens are reused until shortly before they
+// expire.
+func TokenExchange(endpoint, audience, subjectTokenFile string) Auth {
+	x := &tokenExchange{endpoint: endpoint, audience: audience, subject: subjectTokenFile}
+	return bearerAuth(x.token)
+}
+
+// ClientCertificate returns an Auth that presents the TLS client certificate and private
+// key in PEM format from configuration values cert and key of c. They are read whenever a
+// client is created, so rotated certificates are used once c is reloaded.
+func ClientCertificate(c *Config, cert, key string) Auth {
+	return func(base *http.Client) (*http.Client, error) {
+		certPEM, err := c.Bytes(cert)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := c.Bytes(key)
+		if err != nil {
+			return nil, err
+		}
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid client certificate %s: %w", cert, err)
+		}
+
+		var t *http.Transport
+		switch rt := base.Transport.(type) {
+		case nil:
+			t = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			t = rt.Clone()
+		default:
+			return nil, fmt.Errorf("config: cannot set a client certificate on transport %T", rt)
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.Certificates = []tls.Certificate{pair}
+
+		result := *base
+		result.Transport = t
+		return &result, nil
+	}
+}
+
+// authClient returns the client a remote source uses: base, or http.DefaultClient if it is
+// nil, authenticated by a, if it is not nil.
+func authClient(base *http.Client, a Auth) (*http.Client, error) {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	if a == nil {
+		return base, nil
+	}
+
+	result, err := a(base)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to authenticate: %w", err)
+	}
+	return result, nil
+}
+
+// bearerAuth returns an Auth that sends the token returned by token as a bearer token.
+func bearerAuth(token func(context.Context) (string, error)) Auth {
+	return func(base *http.Client) (*http.Client, error) {
+		result := *base
+		result.Transport = &authTransport{base: base.Transport, token: token}
+		return &result, nil
+	}
+}
+
+// authTransport is an http.RoundTripper that adds a bearer token to requests.
+type authTransport struct {
+	base  http.RoundTripper
+	token func(context.Context) (string, error)
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to get token: %w", err)
+	}
+
+	// RoundTrippers must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// tokenExchangeMargin is how long before they expire exchanged tokens are replaced.
+const tokenExchangeMargin = time.Minute
+
+// tokenExchange exchanges a subject token for an access token and caches it.
+type tokenExchange struct {
+	endpoint string
+	audience string
+	subject  string
+
+	mu      sync.Mutex
+	access  string
+	expires time.Time
+}
+
+func (x *tokenExchange) token(ctx context.Context) (string, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.access != "" && time.Until(x.expires) > tokenExchangeMargin {
+		return x.access, nil
+	}
+
+	subject, err := ioutil.ReadFile(x.subject)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"audience":             {x.audience},
+		"subject_token":        {strings.TrimSpace(string(subject))},
+		"subject_token_type":   {"urn:ietf:params:oauth:token-type:jwt"},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+	}
+	req, err := http.NewRequest(http.MethodPost, x.endpoint, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token exchange failed: %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode token exchange response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token exchange response has no access token")
+	}
+
+	x.access = token.AccessToken
+	x.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return x.access, nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("value"))
+	}))
+	defer srv.Close()
+
+	dir := writeFiles(t, map[string]string{"token": "secret\n"})
+	tests := []struct {
+		name    string
+		auth    Auth
+		wantErr bool
+	}{
+		{name: "bearer token", auth: BearerToken("secret")},
+		{name: "token file", auth: TokenFile(filepath.Join(dir, "token"))},
+		{name: "chained", auth: Auths(BearerToken("secret"), TokenFile(filepath.Join(dir, "token")))},
+		{name: "wrong token", auth: BearerToken("wrong"), wantErr: true},
+		{name: "missing token file", auth: TokenFile(filepath.Join(dir, "missing")), wantErr: true},
+		{name: "none", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithPath(srv.URL+"/name"), WithAuth(tt.auth))
+			got, err := c.String("name")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("String() = %q, %v, wantErr %v", got, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != "value" {
+				t.Errorf("String() = %q, want %q", got, "value")
+			}
+		})
+	}
+}
+
+func TestTokenExchange(t *testing.T) {
+	exchanges := 0
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		if r.FormValue("subject_token") != "jwt" || r.FormValue("audience") != "config" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"access_token": "access%d", "expires_in": 3600}`, exchanges)
+	}))
+	defer sts.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	dir := writeFiles(t, map[string]string{"jwt": "jwt"})
+	s := &HTTPSource{URL: srv.URL + "/name", Auth: TokenExchange(sts.URL, "config", filepath.Join(dir, "jwt"))}
+	c := New(WithPath(""), WithSource(s))
+	for i := 0; i < 2; i++ {
+		if got, err := c.String("name"); err != nil || got != "Bearer access1" {
+			t.Errorf("String() = %q, %v, want %q", got, err, "Bearer access1")
+		}
+		if err := c.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("token exchanges = %d, want %d", exchanges, 1)
+	}
+}
+
+// testKeyPair returns a PEM encoded self-signed certificate for usage and its private key.
+func testKeyPair(t *testing.T, usage x509.ExtKeyUsage) (certPEM, keyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "config"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestClientCertificate(t *testing.T) {
+	cert, key := testKeyPair(t, x509.ExtKeyUsageClientAuth)
+	creds := New(WithPath(writeFiles(t, map[string]string{
+		"client.pem": cert,
+		"client.key": key,
+	})))
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	s := &HTTPSource{URL: srv.URL + "/name", Client: srv.Client(), Auth: ClientCertificate(creds, "client.pem", "client.key")}
+	fs, err := s.Files(context.Background())
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	if got := string(fs[0].Data); got != "config" {
+		t.Errorf("Files() = %q, want %q", got, "config")
+	}
+
+	s = &HTTPSource{URL: srv.URL + "/name", Client: srv.Client(), Auth: ClientCertificate(creds, "client.pem", "missing.key")}
+	if _, err := s.Files(context.Background()); err == nil {
+		t.Errorf("Files() error = %v, wantErr %v", err, true)
+	}
+}
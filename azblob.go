@@ -0,0 +1,293 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+func init() {
+	pathSources["azblob"] = newAzureBlobSource
+}
+
+// AzureBlobSource is a Source that provides the blobs in an Azure Blob Storage container
+// whose names start with Prefix. Blobs are named by the remainder of their name after
+// Prefix; blobs in virtual subdirectories are ignored, like subdirectories of search
+// path directories.
+//
+// Search path entries of the form "azblob://container/prefix" are read with an
+// AzureBlobSource. The storage account is taken from the "account" query parameter or the
+// AZURE_STORAGE_ACCOUNT environment variable, and the SAS token from the "sas" query
+// parameter or the AZURE_STORAGE_SAS_TOKEN environment variable. The "sas" parameter
+// must come last, as everything after it is taken as the token, which may be written as
+// is or query-escaped: "azblob://cfg/app/?account=acct&sas=sv=2020-04-08&sig=...".
+type AzureBlobSource struct {
+	Account   string
+	Container string
+	Prefix    string
+
+	// SASToken authorizes requests with a shared access signature. If it and Auth are
+	// empty, requests are authorized with a token for the managed identity of the host,
+	// selected by ClientID if there is more than one.
+	SASToken string
+	ClientID string
+
+	// Endpoint is the blob service endpoint. It defaults to
+	// "https://<Account>.blob.core.windows.net".
+	Endpoint string
+
+	// Client is used to make requests. It defaults to http.DefaultClient.
+	Client *http.Client
+	// Auth authenticates requests instead of a managed identity, if it is not nil and
+	// SASToken is empty. See WithAuth.
+	Auth Auth
+}
+
+// azureIMDSEndpoint is the Azure Instance Metadata Service endpoint that issues managed
+// identity tokens.
+var azureIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+const (
+	azureStorageResource = "https://storage.azure.com/"
+	azureStorageVersion  = "2020-04-08"
+)
+
+func newAzureBlobSource(u *url.URL) (Source, error) {
+	raw, sas, err := splitSASToken(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid search path entry %q: %w", "azblob://"+u.Host+u.Path, err)
+	}
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid search path entry %q: %w", "azblob://"+u.Host+u.Path, err)
+	}
+	s := &AzureBlobSource{
+		Account:   q.Get("account"),
+		Container: u.Host,
+		Prefix:    strings.TrimPrefix(u.Path, "/"),
+		SASToken:  sas,
+		ClientID:  os.Getenv("AZURE_CLIENT_ID"),
+	}
+	if s.Account == "" {
+		s.Account = os.Getenv("AZURE_STORAGE_ACCOUNT")
+	}
+	if s.SASToken == "" {
+		s.SASToken = os.Getenv("AZURE_STORAGE_SAS_TOKEN")
+	}
+	if s.Account == "" || s.Container == "" {
+		return nil, fmt.Errorf("config: search path entry %q requires a storage account and container", "azblob://"+u.Host+u.Path)
+	}
+	return s, nil
+}
+
+// splitSASToken splits the "sas" parameter, and everything after it, from the raw query
+// of an azblob search path entry. SAS tokens are themselves queries, so a token that
+// contains "=" or "&" is taken as written, and one that does not is unescaped.
+func splitSASToken(raw string) (query, sas string, err error) {
+	i := strings.Index("&"+raw, "&sas=")
+	if i < 0 {
+		return raw, "", nil
+	}
+	query, sas = strings.TrimSuffix(raw[:i], "&"), raw[i+len("sas="):]
+	if !strings.ContainsAny(sas, "=&") {
+		sas, err = url.QueryUnescape(sas)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid SAS token: %w", err)
+		}
+	}
+	return query, sas, nil
+}
+
+func (s *AzureBlobSource) Files(ctx context.Context) ([]File, error) {
+	auth, err := s.authorize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to authorize azure blob storage requests: %w", err)
+	}
+
+	names, err := s.list(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []File
+	for _, n := range names {
+		u := s.url(s.Container+"/"+n, nil)
+		d, err := s.get(ctx, auth, u)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, File{
+			Name: strings.TrimPrefix(n, s.Prefix),
+			Path: "azblob://" + s.Container + "/" + n,
+			Data: d,
+		})
+	}
+	return result, nil
+}
+
+// azureBlobList is the response body of the List Blobs operation.
+type azureBlobList struct {
+	Blobs struct {
+		Blob []struct {
+			Name string
+		}
+	}
+	NextMarker string
+}
+
+// list returns the names of the blobs directly under s.Prefix.
+func (s *AzureBlobSource) list(ctx context.Context, auth func(*http.Request)) ([]string, error) {
+	var result []string
+	marker := ""
+	for {
+		q := url.Values{
+			"restype":   {"container"},
+			"comp":      {"list"},
+			"prefix":    {s.Prefix},
+			"delimiter": {"/"},
+		}
+		if marker != "" {
+			q.Set("marker", marker)
+		}
+
+		b, err := s.get(ctx, auth, s.url(s.Container, q))
+		if err != nil {
+			return nil, err
+		}
+
+		var l azureBlobList
+		err = xml.Unmarshal(b, &l)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to list azure blob container %q: %w", s.Container, err)
+		}
+		for _, b := range l.Blobs.Blob {
+			result = append(result, b.Name)
+		}
+
+		if l.NextMarker == "" {
+			return result, nil
+		}
+		marker = l.NextMarker
+	}
+}
+
+// url returns the URL of resource p with query q, including the SAS token if there is one.
+func (s *AzureBlobSource) url(p string, q url.Values) string {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + s.Account + ".blob.core.windows.net"
+	}
+
+	var segments []string
+	for _, seg := range strings.Split(p, "/") {
+		segments = append(segments, url.PathEscape(seg))
+	}
+
+	query := q.Encode()
+	if s.SASToken != "" {
+		if query != "" {
+			query += "&"
+		}
+		query += strings.TrimPrefix(s.SASToken, "?")
+	}
+
+	result := strings.TrimSuffix(endpoint, "/") + "/" + strings.Join(segments, "/")
+	if query != "" {
+		result += "?" + query
+	}
+	return result
+}
+
+func (s *AzureBlobSource) get(ctx context.Context, auth func(*http.Request), u string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	auth(req)
+
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("config: azure blob storage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read azure blob storage response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("config: azure blob storage request for %q failed: %s", req.URL.Path, resp.Status)
+	}
+	return b, nil
+}
+
+// authorize returns a function that adds authorization to requests. SAS tokens are part
+// of the request URL, and an Auth is applied by the client, so they need nothing further.
+func (s *AzureBlobSource) authorize(ctx context.Context) (func(*http.Request), error) {
+	if s.SASToken != "" || s.Auth != nil {
+		return func(*http.Request) {}, nil
+	}
+
+	q := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {azureStorageResource},
+	}
+	if s.ClientID != "" {
+		q.Set("client_id", s.ClientID)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, azureIMDSEndpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Metadata", "true")
+
+	client, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("managed identity token request failed: %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode managed identity token: %w", err)
+	}
+
+	return func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	}, nil
+}
+
+func (s *AzureBlobSource) client() (*http.Client, error) {
+	return authClient(s.Client, s.Auth)
+}
+
+func (s *AzureBlobSource) setAuth(a Auth) {
+	if s.Auth == nil {
+		s.Auth = a
+	}
+}
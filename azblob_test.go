@@ -0,0 +1,160 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newAzureBlobServer(t *testing.T, authorized func(r *http.Request) bool) *httptest.Server {
+	t.Helper()
+
+	blobs := map[string]string{
+		"app/db_url":       "postgres://azure",
+		"app/timeout":      "5s",
+		"app/nested/value": "ignored",
+		"other/db_url":     "ignored",
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-ms-version") == "" || !authorized(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		q := r.URL.Query()
+		if r.URL.Path == "/cfg" && q.Get("comp") == "list" {
+			if q.Get("marker") == "" {
+				fmt.Fprintf(w, `<EnumerationResults><Blobs><Blob><Name>%s</Name></Blob><BlobPrefix><Name>app/nested/</Name></BlobPrefix></Blobs><NextMarker>m1</NextMarker></EnumerationResults>`, "app/db_url")
+				return
+			}
+			fmt.Fprint(w, `<EnumerationResults><Blobs><Blob><Name>app/timeout</Name></Blob></Blobs><NextMarker/></EnumerationResults>`)
+			return
+		}
+
+		if b, ok := blobs[strings.TrimPrefix(r.URL.Path, "/cfg/")]; ok {
+			fmt.Fprint(w, b)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAzureBlobSource_sas(t *testing.T) {
+	srv := newAzureBlobServer(t, func(r *http.Request) bool {
+		return r.URL.Query().Get("sig") == "secret"
+	})
+
+	c := New(WithPath(""), WithSource(&AzureBlobSource{
+		Account:   "acct",
+		Container: "cfg",
+		Prefix:    "app/",
+		SASToken:  "?sv=2020-04-08&sig=secret",
+		Endpoint:  srv.URL,
+	}))
+
+	for n, want := range map[string]string{"db_url": "postgres://azure", "timeout": "5s"} {
+		if got, err := c.String(n); err != nil || got != want {
+			t.Errorf("String(%q) = %q, %v, want %q", n, got, err, want)
+		}
+	}
+	if _, err := c.Bytes("value"); err == nil {
+		t.Errorf("Bytes() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestAzureBlobSource_managedIdentity(t *testing.T) {
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != azureStorageResource {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "token"}`)
+	}))
+	defer imds.Close()
+	defer func(v string) { azureIMDSEndpoint = v }(azureIMDSEndpoint)
+	azureIMDSEndpoint = imds.URL
+
+	srv := newAzureBlobServer(t, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer token"
+	})
+
+	c := New(WithPath(""), WithSource(&AzureBlobSource{
+		Account:   "acct",
+		Container: "cfg",
+		Prefix:    "app/",
+		Endpoint:  srv.URL,
+	}))
+	if got, err := c.Duration("timeout"); err != nil || got.String() != "5s" {
+		t.Errorf("Duration() = %v, %v, want %v", got, err, "5s")
+	}
+}
+
+func Test_newAzureBlobSource(t *testing.T) {
+	defer func(v string, ok bool) {
+		if ok {
+			_ = os.Setenv("AZURE_STORAGE_ACCOUNT", v)
+		} else {
+			_ = os.Unsetenv("AZURE_STORAGE_ACCOUNT")
+		}
+	}(os.LookupEnv("AZURE_STORAGE_ACCOUNT"))
+	if err := os.Setenv("AZURE_STORAGE_ACCOUNT", "envacct"); err != nil {
+		t.Fatal(err)
+	}
+
+	p := strings.Join([]string{"testdata/1", "azblob://cfg/app/?sas=sig%3Dx"}, string(os.PathListSeparator))
+	ps := splitPath(p)
+	if want := []string{"testdata/1", "azblob://cfg/app/?sas=sig%3Dx"}; !reflect.DeepEqual(ps, want) {
+		t.Fatalf("splitPath() = %q, want %q", ps, want)
+	}
+
+	src, err := pathSource(ps[1])
+	if err != nil {
+		t.Fatalf("pathSource() error = %v", err)
+	}
+	got, ok := src.(*AzureBlobSource)
+	if !ok {
+		t.Fatalf("pathSource() = %T, want %T", src, got)
+	}
+	if got.Account != "envacct" || got.Container != "cfg" || got.Prefix != "app/" || got.SASToken != "sig=x" {
+		t.Errorf("pathSource() = %+v", got)
+	}
+
+	if _, err := pathSource("azblob:///app?account="); err == nil {
+		t.Errorf("pathSource() error = %v, wantErr %v", err, true)
+	}
+}
+
+func Test_splitSASToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		wantQuery string
+		wantSAS   string
+		wantErr   bool
+	}{
+		{name: "escaped", raw: "account=acct&sas=sv%3D2020-04-08%26sig%3Da%252Bb", wantQuery: "account=acct", wantSAS: "sv=2020-04-08&sig=a%2Bb"},
+		{name: "as is", raw: "account=acct&sas=sv=2020-04-08&sig=a%2Bb", wantQuery: "account=acct", wantSAS: "sv=2020-04-08&sig=a%2Bb"},
+		{name: "not last", raw: "sas=sig=x&account=acct", wantSAS: "sig=x&account=acct"},
+		{name: "suffix of another parameter", raw: "xsas=1&account=acct", wantQuery: "xsas=1&account=acct"},
+		{name: "none", raw: "account=acct", wantQuery: "account=acct"},
+		{name: "invalid escape", raw: "account=acct&sas=sig%3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, sas, err := splitSASToken(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitSASToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if query != tt.wantQuery || sas != tt.wantSAS {
+				t.Errorf("splitSASToken() = %q, %q, want %q, %q", query, sas, tt.wantQuery, tt.wantSAS)
+			}
+		})
+	}
+}
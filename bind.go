@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sync"
+)
+
+// Binding keeps a value decoded from a configuration value up to date. It is created
+// with Register.
+type Binding struct {
+	c        *Config
+	name     string
+	target   reflect.Value
+	onUpdate func(error)
+	cancel   func()
+
+	mu sync.RWMutex
+}
+
+// BindOption configures a Binding.
+type BindOption func(*Binding)
+
+// OnUpdate registers fn to be called each time a Binding decodes its configuration value
+// after it changes, with the decoding error, if any. fn is called after the target is
+// updated, so it can act on the new settings.
+func OnUpdate(fn func(err error)) BindOption {
+	return func(b *Binding) {
+		b.onUpdate = fn
+	}
+}
+
+// Register calls c.Load() then decodes configuration value n into target, which must be a
+// non-nil pointer, and decodes it again each time a reload changes n, in the same way as
+// OnChange. Values whose names end in ".json" are decoded with InterfaceJson, and others
+// with InterfaceYaml. If decoding a changed value fails, target keeps its previous contents
+// and the error is passed to the OnUpdate hook.
+//
+// Target is replaced while the Binding is write-locked, so it can be read safely while
+// holding the read lock:
+//		var limits Limits
+//		b, err := c.Register("limits.yaml", &limits)
+//		...
+//		b.RLock()
+//		max := limits.MaxRequests
+//		b.RUnlock()
+//
+// Call Close to stop updating target.
+func (c *Config) Register(n string, target interface{}, opts ...BindOption) (*Binding, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("config: cannot register %s into %T: target must be a non-nil pointer", n, target)
+	}
+
+	b := &Binding{c: c, name: n, target: v}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	err := b.update()
+	if err != nil {
+		return nil, err
+	}
+
+	b.cancel = c.OnChange(func(ev ChangeEvent) {
+		for _, changed := range ev.Changed {
+			if changed == n {
+				err := b.update()
+				if b.onUpdate != nil {
+					b.onUpdate(err)
+				}
+				return
+			}
+		}
+	})
+	return b, nil
+}
+
+// update decodes the configuration value of b into a new value and, if that succeeds,
+// replaces the target of b with it.
+func (b *Binding) update() error {
+	v := reflect.New(b.target.Type().Elem())
+
+	var err error
+	if path.Ext(b.name) == ".json" {
+		err = b.c.InterfaceJson(b.name, v.Interface())
+	} else {
+		err = b.c.InterfaceYaml(b.name, v.Interface())
+	}
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.target.Elem().Set(v.Elem())
+	return nil
+}
+
+// RLock locks b for reading its target.
+func (b *Binding) RLock() {
+	b.mu.RLock()
+}
+
+// RUnlock undoes a single RLock call.
+func (b *Binding) RUnlock() {
+	b.mu.RUnlock()
+}
+
+// Close stops updating the target of b.
+func (b *Binding) Close() {
+	b.cancel()
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Register(t *testing.T) {
+	type limits struct {
+		MaxRequests int `json:"max_requests" yaml:"maxRequests"`
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"limits.json": `{"max_requests": 10}`,
+		"limits.yaml": "maxRequests: 20\n",
+	})
+	c := New(WithPath(dir))
+
+	var fromJSON, fromYAML limits
+	var updates []error
+	b, err := c.Register("limits.json", &fromJSON, OnUpdate(func(err error) { updates = append(updates, err) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Register("limits.yaml", &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	if fromJSON.MaxRequests != 10 || fromYAML.MaxRequests != 20 {
+		t.Errorf("Register() decoded %+v and %+v, want 10 and 20", fromJSON, fromYAML)
+	}
+
+	write := func(data string) {
+		t.Helper()
+		if err := ioutil.WriteFile(filepath.Join(dir, "limits.json"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"max_requests": 30}`)
+	b.RLock()
+	got := fromJSON.MaxRequests
+	b.RUnlock()
+	if got != 30 || len(updates) != 1 || updates[0] != nil {
+		t.Errorf("after reload, MaxRequests = %d, updates = %v, want 30 and one successful update", got, updates)
+	}
+
+	write(`{"max_requests": "many"}`)
+	if fromJSON.MaxRequests != 30 || len(updates) != 2 || updates[1] == nil {
+		t.Errorf("after invalid reload, MaxRequests = %d, updates = %v, want 30 and a failed update", fromJSON.MaxRequests, updates)
+	}
+
+	b.Close()
+	write(`{"max_requests": 40}`)
+	if fromJSON.MaxRequests != 30 || len(updates) != 2 {
+		t.Errorf("after Close(), MaxRequests = %d, updates = %v, want no more updates", fromJSON.MaxRequests, updates)
+	}
+
+	if _, err := c.Register("limits.json", fromJSON); err == nil {
+		t.Errorf("Register() error = nil, want an error for a non-pointer target")
+	}
+	if _, err := c.Register("missing.json", &fromJSON); err == nil {
+		t.Errorf("Register() error = nil, want an error for a missing value")
+	}
+}
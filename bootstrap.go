@@ -0,0 +1,256 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BootstrapFile is the name of the file that configures the loader itself. See
+// NewFromBootstrap.
+const BootstrapFile = "config.bootstrap.yaml"
+
+// BootstrapEnvVar is the name of the environment variable that, if set, is the location of
+// the bootstrap file, instead of looking for BootstrapFile on the search path.
+const BootstrapEnvVar = "CONFIG_BOOTSTRAP"
+
+// NewFromBootstrap is like New, but first reads the options of the Config from a bootstrap
+// file, so the loader can be tuned per environment without recompiling. The file is the
+// one named by BootstrapEnvVar if it is set, and otherwise BootstrapFile in the first
+// directory of the search path (as configured by opts) that contains one. If there is no
+// bootstrap file, NewFromBootstrap returns New(opts...).
+//
+// The bootstrap file is YAML. Every field is optional, and fields that are not set keep
+// the value given by opts:
+//
+//		path: /etc/myapp:/etc/myapp/local?
+//		pathPriority: 0
+//		shadow: true
+//		trimSpace: true
+//		maxDepth: 2
+//		prune: [".*", "testdata"]
+//		secrets: ["*password*", "*.key"]
+//		required: [db_url]
+//		manifest: SHA256SUMS
+//		errorPolicy: keep             # fail, skip or keep
+//		removalPolicies:
+//		  - {pattern: "db_*", policy: keep}   # remove, keep or fail
+//		pollInterval: 30s
+//		loadRetry: 1s
+//		expiryWarning: 168h
+//		humanNumbers: true
+//		sources:
+//		  - {uri: "https://config.example.com/myapp", priority: 10}
+//
+// Options read from the file are applied after opts, so they take precedence. Unknown
+// fields are an error, so typos do not go unnoticed. The bootstrap file is not itself
+// provided as a configuration value. The package-level functions use Default(), which
+// does not read a bootstrap file.
+func NewFromBootstrap(opts ...Option) (*Config, error) {
+	bopts, err := readBootstrap(opts)
+	return New(append(opts, bopts...)...), err
+}
+
+// readBootstrap returns the Options read from the bootstrap file for opts, if there is
+// one.
+func readBootstrap(opts []Option) ([]Option, error) {
+	f, err := bootstrapFile(newOptions(opts))
+	if err != nil || f == "" {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, &SourceError{Source: f, Err: fmt.Errorf("config: failed to read bootstrap file: %w", err)}
+	}
+
+	bopts, err := parseBootstrap(b)
+	if err != nil {
+		return nil, errorf(ErrDecode, "config: failed to parse bootstrap file %q: %w", f, err)
+	}
+
+	bopts = append(bopts, func(o *options) {
+		o.bootstrap = f
+	})
+	return bopts, nil
+}
+
+// bootstrapFile returns the absolute path of the bootstrap file for o, or "" if there is
+// none.
+func bootstrapFile(o options) (string, error) {
+	if f, ok := os.LookupEnv(BootstrapEnvVar); ok && f != "" {
+		return filepath.Abs(f)
+	}
+
+	p := o.path
+	if !o.pathSet {
+		p = Path()
+	}
+	for _, e := range splitPath(p) {
+		e, _ = optionalPath(e)
+		if _, ok := pathScheme(e); ok || e == StdinPath {
+			continue
+		}
+		f := filepath.Join(e, BootstrapFile)
+		if fi, err := os.Stat(f); err == nil && fi.Mode().IsRegular() {
+			return filepath.Abs(f)
+		}
+	}
+	return "", nil
+}
+
+// bootstrapSpec is the content of a bootstrap file.
+type bootstrapSpec struct {
+	Path            *string           `yaml:"path"`
+	PathPriority    *int              `yaml:"pathPriority"`
+	Shadow          *bool             `yaml:"shadow"`
+	TrimSpace       *bool             `yaml:"trimSpace"`
+	MaxDepth        *int              `yaml:"maxDepth"`
+	Prune           []string          `yaml:"prune"`
+	Secrets         []string          `yaml:"secrets"`
+	Required        []string          `yaml:"required"`
+	Manifest        *string           `yaml:"manifest"`
+	ErrorPolicy     *string           `yaml:"errorPolicy"`
+	RemovalPolicies []bootstrapRemove `yaml:"removalPolicies"`
+	PollInterval    *string           `yaml:"pollInterval"`
+	LoadRetry       *string           `yaml:"loadRetry"`
+	ExpiryWarning   *string           `yaml:"expiryWarning"`
+	HumanNumbers    *bool             `yaml:"humanNumbers"`
+	Sources         []bootstrapSource `yaml:"sources"`
+}
+
+type bootstrapRemove struct {
+	Pattern string `yaml:"pattern"`
+	Policy  string `yaml:"policy"`
+}
+
+type bootstrapSource struct {
+	URI      string `yaml:"uri"`
+	Priority int    `yaml:"priority"`
+}
+
+// parseBootstrap returns the Options configured by bootstrap file b.
+func parseBootstrap(b []byte) ([]Option, error) {
+	var spec bootstrapSpec
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	err := dec.Decode(&spec)
+	if err != nil && len(bytes.TrimSpace(b)) > 0 {
+		return nil, err
+	}
+
+	var opts []Option
+	if spec.Path != nil {
+		opts = append(opts, WithPath(*spec.Path))
+	}
+	if spec.PathPriority != nil {
+		opts = append(opts, WithPathPriority(*spec.PathPriority))
+	}
+	if spec.Shadow != nil {
+		opts = append(opts, WithShadow(*spec.Shadow))
+	}
+	if spec.TrimSpace != nil {
+		opts = append(opts, WithTrimSpace(*spec.TrimSpace))
+	}
+	if spec.MaxDepth != nil {
+		opts = append(opts, WithMaxDepth(*spec.MaxDepth))
+	}
+	if spec.Prune != nil {
+		opts = append(opts, WithPrune(spec.Prune...))
+	}
+	if spec.Secrets != nil {
+		opts = append(opts, WithSecrets(spec.Secrets...))
+	}
+	if spec.Required != nil {
+		opts = append(opts, WithRequired(spec.Required...))
+	}
+	if spec.Manifest != nil {
+		opts = append(opts, WithManifest(*spec.Manifest))
+	}
+	if spec.HumanNumbers != nil {
+		opts = append(opts, WithHumanNumbers(*spec.HumanNumbers))
+	}
+
+	if spec.ErrorPolicy != nil {
+		p, ok := parseErrorPolicy(*spec.ErrorPolicy)
+		if !ok {
+			return nil, fmt.Errorf("invalid errorPolicy %q", *spec.ErrorPolicy)
+		}
+		opts = append(opts, WithErrorPolicy(p))
+	}
+	for _, r := range spec.RemovalPolicies {
+		p, ok := parseRemovalPolicy(r.Policy)
+		if !ok {
+			return nil, fmt.Errorf("invalid removal policy %q for %q", r.Policy, r.Pattern)
+		}
+		opts = append(opts, WithRemovalPolicy(r.Pattern, p))
+	}
+
+	durations := []struct {
+		field string
+		value *string
+		opt   func(time.Duration) Option
+	}{
+		{"pollInterval", spec.PollInterval, WithPollInterval},
+		{"loadRetry", spec.LoadRetry, WithLoadRetry},
+		{"expiryWarning", spec.ExpiryWarning, WithExpiryWarning},
+	}
+	for _, d := range durations {
+		if d.value == nil {
+			continue
+		}
+		v, err := time.ParseDuration(*d.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.field, err)
+		}
+		opts = append(opts, d.opt(v))
+	}
+
+	for _, src := range spec.Sources {
+		s, err := pathSource(src.URI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source %q: %w", src.URI, err)
+		}
+		opts = append(opts, WithSourcePriority(s, src.Priority))
+	}
+	return opts, nil
+}
+
+// parseErrorPolicy returns the ErrorPolicy whose String is s.
+func parseErrorPolicy(s string) (ErrorPolicy, bool) {
+	for _, p := range []ErrorPolicy{FailOnError, SkipOnError, KeepOnError} {
+		if p.String() == s {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// parseRemovalPolicy returns the RemovalPolicy whose String is s.
+func parseRemovalPolicy(s string) (RemovalPolicy, bool) {
+	for _, p := range []RemovalPolicy{RemoveMissing, KeepMissing, FailOnMissing} {
+		if p.String() == s {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+// skipBootstrap returns fs without bootstrap file f, an absolute path, recording it as
+// skipped in sr.
+func skipBootstrap(f string, fs []File, sr *SourceReport) []File {
+	result := fs[:0]
+	for _, file := range fs {
+		if p, err := filepath.Abs(file.Path); err == nil && p == f {
+			sr.Files = append(sr.Files, FileReport{Name: file.Name, Path: file.Path, Skipped: "bootstrap file"})
+			continue
+		}
+		result = append(result, file)
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewFromBootstrap(t *testing.T) {
+	tests := []struct {
+		name      string
+		bootstrap string // bootstrap is the content of the bootstrap file, if any.
+		wantErr   error
+		want      string
+		check     func(t *testing.T, o *options)
+	}{
+		{name: "none", want: "value"},
+		{name: "empty", bootstrap: "", want: "value"},
+		{name: "trim", bootstrap: "trimSpace: false\n", want: " value\n"},
+		{
+			name:      "options",
+			bootstrap: "pollInterval: 30s\nsecrets: [\"*.pw\"]\nerrorPolicy: keep\nremovalPolicies:\n  - {pattern: \"db_*\", policy: fail}\n",
+			want:      "value",
+			check: func(t *testing.T, o *options) {
+				if o.pollInterval != 30*time.Second {
+					t.Errorf("pollInterval = %v, want 30s", o.pollInterval)
+				}
+				if len(o.secrets) != 1 || o.secrets[0] != "*.pw" {
+					t.Errorf("secrets = %v, want [*.pw]", o.secrets)
+				}
+				if o.errorPolicy != KeepOnError {
+					t.Errorf("errorPolicy = %v, want keep", o.errorPolicy)
+				}
+				if o.removalPolicy("db_url") != FailOnMissing {
+					t.Errorf("removalPolicy() = %v, want fail", o.removalPolicy("db_url"))
+				}
+			},
+		},
+		{name: "unknown field", bootstrap: "pollIntervall: 30s\n", wantErr: ErrDecode},
+		{name: "invalid policy", bootstrap: "errorPolicy: ignore\n", wantErr: ErrDecode},
+		{name: "invalid duration", bootstrap: "loadRetry: soon\n", wantErr: ErrDecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"key": " value\n"}
+			if tt.name != "none" {
+				files[BootstrapFile] = tt.bootstrap
+			}
+			dir := writeFiles(t, files)
+
+			c, err := NewFromBootstrap(WithPath(dir))
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("NewFromBootstrap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := c.String("key")
+			if err != nil || got != tt.want {
+				t.Errorf("String() got = %q, %v, want %q", got, err, tt.want)
+			}
+			if _, err := c.Bytes(BootstrapFile); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Bytes(%q) error = %v, want ErrNotFound", BootstrapFile, err)
+			}
+			if tt.check != nil {
+				tt.check(t, &c.s.options)
+			}
+		})
+	}
+}
+
+func TestNewFromBootstrap_envVar(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"key": " value\n"})
+	f := filepath.Join(writeFiles(t, nil), "bootstrap.yaml")
+	if err := ioutil.WriteFile(f, []byte("path: "+dir+"\ntrimSpace: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	old, ok := os.LookupEnv(BootstrapEnvVar)
+	if err := os.Setenv(BootstrapEnvVar, f); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(BootstrapEnvVar, old)
+		} else {
+			_ = os.Unsetenv(BootstrapEnvVar)
+		}
+	})
+
+	c, err := NewFromBootstrap()
+	if err != nil {
+		t.Fatalf("NewFromBootstrap() error = %v", err)
+	}
+	got, err := c.String("key")
+	if err != nil || got != " value\n" {
+		t.Errorf("String() got = %q, %v, want %q", got, err, " value\n")
+	}
+}
+
+func TestNewFromBootstrap_relativeEnvVar(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"key": " value\n", "boot.yaml": "trimSpace: false\n"})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	old, ok := os.LookupEnv(BootstrapEnvVar)
+	if err := os.Setenv(BootstrapEnvVar, "boot.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(BootstrapEnvVar, old)
+		} else {
+			_ = os.Unsetenv(BootstrapEnvVar)
+		}
+	})
+
+	c, err := NewFromBootstrap(WithPath(dir))
+	if err != nil {
+		t.Fatalf("NewFromBootstrap() error = %v", err)
+	}
+	if got, err := c.String("key"); err != nil || got != " value\n" {
+		t.Errorf("String() got = %q, %v, want %q", got, err, " value\n")
+	}
+	if _, err := c.Bytes("boot.yaml"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Bytes() error = %v, want %v for the bootstrap file", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a BreakerSource.
+type BreakerState int
+
+const (
+	// BreakerClosed is the normal state, in which every read is passed to the source.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen is the state after too many consecutive failures, in which reads are
+	// served from the cache without calling the source.
+	BreakerOpen
+	// BreakerHalfOpen is the state after the cooldown, in which the next read probes the
+	// source to decide whether to close or reopen the breaker.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+const (
+	// DefaultBreakerThreshold is the number of consecutive failures that open a
+	// BreakerSource whose Threshold is not set.
+	DefaultBreakerThreshold = 3
+	// DefaultBreakerCooldown is how long a BreakerSource whose Cooldown is not set stays
+	// open before probing its source again.
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// BreakerSource is a Source that wraps a remote Source in a circuit breaker, so a failing
+// backend is not hammered by reloads during an incident. Failures are returned as usual
+// until Threshold consecutive reads have failed, which opens the breaker. While it is
+// open, reads are served from the files of the last successful read without calling the
+// source (or fail, if there was none). After Cooldown, the next read probes the source:
+// success closes the breaker, and failure opens it again.
+//
+// If the wrapped Source implements Notifier, its notifications are passed through.
+type BreakerSource struct {
+	Source    Source
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	cached   []File
+	ok       bool // ok is true if cached holds the files of a successful read.
+	err      error
+
+	now func() time.Time
+}
+
+// CircuitBreaker returns a BreakerSource wrapping src with the default threshold and
+// cooldown.
+func CircuitBreaker(src Source) *BreakerSource {
+	return &BreakerSource{Source: src}
+}
+
+func (s *BreakerSource) Files(ctx context.Context) ([]File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == BreakerOpen {
+		if s.clock().Sub(s.openedAt) < s.cooldown() {
+			return s.fallback()
+		}
+		s.state = BreakerHalfOpen
+	}
+
+	fs, err := s.Source.Files(ctx)
+	if err == nil {
+		if s.state != BreakerClosed {
+			log.Printf("config: circuit breaker for %s closed", s)
+		}
+		s.state, s.failures, s.err = BreakerClosed, 0, nil
+		s.cached, s.ok = append([]File(nil), fs...), true
+		return fs, nil
+	}
+
+	s.failures++
+	s.err = err
+	if s.state == BreakerHalfOpen || s.failures >= s.threshold() {
+		if s.state == BreakerClosed {
+			log.Printf("config: circuit breaker for %s opened after %d failures: %v", s, s.failures, err)
+		}
+		s.state, s.openedAt = BreakerOpen, s.clock()
+		return s.fallback()
+	}
+	return nil, err
+}
+
+// fallback returns the cached files while the breaker is open.
+func (s *BreakerSource) fallback() ([]File, error) {
+	if !s.ok {
+		return nil, fmt.Errorf("config: circuit breaker for %s is open: %w", s, s.err)
+	}
+	return append([]File(nil), s.cached...), nil
+}
+
+// State returns the current state of the breaker.
+func (s *BreakerSource) State() BreakerState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Failures returns the number of consecutive failed reads of the source, and the error
+// of the most recent one.
+func (s *BreakerSource) Failures() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failures, s.err
+}
+
+// Changed implements Notifier by passing through the notifications of the wrapped source,
+// if it implements Notifier.
+func (s *BreakerSource) Changed() <-chan struct{} {
+	if n, ok := s.Source.(Notifier); ok {
+		return n.Changed()
+	}
+	return nil
+}
+
+func (s *BreakerSource) String() string {
+	return describeSource(s.Source)
+}
+
+func (s *BreakerSource) threshold() int {
+	if s.Threshold <= 0 {
+		return DefaultBreakerThreshold
+	}
+	return s.Threshold
+}
+
+func (s *BreakerSource) cooldown() time.Duration {
+	if s.Cooldown <= 0 {
+		return DefaultBreakerCooldown
+	}
+	return s.Cooldown
+}
+
+func (s *BreakerSource) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
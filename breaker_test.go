@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakySource is a Source that fails while err is set, counting its reads.
+type flakySource struct {
+	err   error
+	reads int
+}
+
+func (s *flakySource) Files(context.Context) ([]File, error) {
+	s.reads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []File{{Name: "name", Path: "flaky:name", Data: []byte("value")}}, nil
+}
+
+func TestBreakerSource(t *testing.T) {
+	src := &flakySource{}
+	now := time.Unix(0, 0)
+	b := &BreakerSource{Source: src, Threshold: 2, Cooldown: time.Minute, now: func() time.Time { return now }}
+	errDown := errors.New("down")
+
+	steps := []struct {
+		name      string
+		err       error
+		advance   time.Duration
+		wantErr   bool
+		wantState BreakerState
+		wantReads int
+	}{
+		{name: "success", wantState: BreakerClosed, wantReads: 1},
+		{name: "first failure", err: errDown, wantErr: true, wantState: BreakerClosed, wantReads: 2},
+		{name: "trips", err: errDown, wantState: BreakerOpen, wantReads: 3},
+		{name: "open serves cache", err: errDown, advance: time.Second, wantState: BreakerOpen, wantReads: 3},
+		{name: "half-open probe fails", err: errDown, advance: time.Minute, wantState: BreakerOpen, wantReads: 4},
+		{name: "reopened", advance: time.Second, wantState: BreakerOpen, wantReads: 4},
+		{name: "half-open probe succeeds", advance: time.Minute, wantState: BreakerClosed, wantReads: 5},
+	}
+	for _, tt := range steps {
+		src.err = tt.err
+		now = now.Add(tt.advance)
+
+		fs, err := b.Files(context.Background())
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: Files() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !tt.wantErr && (len(fs) != 1 || string(fs[0].Data) != "value") {
+			t.Errorf("%s: Files() = %v, want the cached value", tt.name, fs)
+		}
+		if got := b.State(); got != tt.wantState {
+			t.Errorf("%s: State() = %v, want %v", tt.name, got, tt.wantState)
+		}
+		if src.reads != tt.wantReads {
+			t.Errorf("%s: source reads = %d, want %d", tt.name, src.reads, tt.wantReads)
+		}
+	}
+	if n, err := b.Failures(); n != 0 || err != nil {
+		t.Errorf("Failures() = %d, %v, want 0, nil", n, err)
+	}
+}
+
+func TestBreakerSource_noCache(t *testing.T) {
+	errDown := errors.New("down")
+	b := &BreakerSource{Source: &flakySource{err: errDown}, Threshold: 1}
+
+	_, err := b.Files(context.Background())
+	if !errors.Is(err, errDown) || b.State() != BreakerOpen {
+		t.Errorf("Files() error = %v, state %v, want %v, %v", err, b.State(), errDown, BreakerOpen)
+	}
+	if _, err := New(WithPath(""), WithSource(b)).String("name"); !errors.Is(err, errDown) {
+		t.Errorf("String() error = %v, want %v", err, errDown)
+	}
+}
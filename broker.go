@@ -0,0 +1,131 @@
+package config
+
+import (
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SASL mechanisms accepted by Config.Broker.
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// BrokerSpec is the settings of a message broker client, such as a Kafka or NATS client,
+// parsed by Config.Broker.
+type BrokerSpec struct {
+	Brokers []string    // Brokers are the addresses of the brokers to bootstrap from.
+	Topic   string      // Topic is the topic or subject to produce to or consume from.
+	Group   string      // Group is the consumer group or queue group.
+	TLS     *tls.Config // TLS is the transport security configuration, or nil for plaintext.
+	SASL    *SASLSpec   // SASL is the authentication configuration, or nil for none.
+}
+
+// SASLSpec is the SASL authentication settings of a BrokerSpec.
+type SASLSpec struct {
+	Mechanism string // Mechanism is SASLPlain, SASLScramSHA256 or SASLScramSHA512.
+	Username  string
+	Password  string
+}
+
+type brokerSpec struct {
+	Brokers []string    `yaml:"brokers"`
+	Topic   string      `yaml:"topic"`
+	Group   string      `yaml:"group"`
+	TLS     *tlsEntries `yaml:"tls"`
+	SASL    *struct {
+		Mechanism string `yaml:"mechanism"`
+		Username  string `yaml:"username"`
+		Password  string `yaml:"password"`
+	} `yaml:"sasl"`
+}
+
+// Broker parses configuration value n into a BrokerSpec. It expects a yaml or json object
+// of the form
+//		brokers: [kafka-0.internal:9093, kafka-1.internal:9093]
+//		topic: orders
+//		group: billing
+//		tls:
+//			ca: kafka_ca
+//		sasl:
+//			mechanism: SCRAM-SHA-512
+//			username: billing
+//			password: kafka_password
+//
+// Only brokers is required. Brokers are "host:port" addresses or URLs with a host, such as
+// "nats://nats.internal:4222"; duplicates are an error. The tls ca, cert and key and the
+// sasl password are the names of the configuration values holding them, so they can be
+// kept with the other secrets; the sasl username is given as it is. The sasl mechanism is
+// one of PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512, and defaults to PLAIN. PLAIN sends the
+// password in the clear, so it requires tls.
+func (c *Config) Broker(n string) (BrokerSpec, error) {
+	var raw brokerSpec
+	err := c.InterfaceYaml(n, &raw)
+	if err != nil {
+		return BrokerSpec{}, err
+	}
+
+	if len(raw.Brokers) == 0 {
+		return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: missing brokers", n)
+	}
+	result := BrokerSpec{Topic: raw.Topic, Group: raw.Group}
+	seen := map[string]bool{}
+	for _, b := range raw.Brokers {
+		if !validBroker(b) {
+			return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: invalid broker %q: expected host:port or a URL", n, b)
+		}
+		if seen[b] {
+			return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: duplicate broker %q", n, b)
+		}
+		seen[b] = true
+		result.Brokers = append(result.Brokers, b)
+	}
+
+	if raw.TLS != nil {
+		result.TLS, err = c.tlsConfig(*raw.TLS)
+		if err != nil {
+			return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: %w", n, err)
+		}
+	}
+
+	if raw.SASL != nil {
+		sasl := &SASLSpec{Mechanism: strings.ToUpper(raw.SASL.Mechanism), Username: raw.SASL.Username}
+		switch sasl.Mechanism {
+		case "":
+			sasl.Mechanism = SASLPlain
+		case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		default:
+			return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: sasl mechanism is %q, which is not one of the allowed values: %q, %q, %q", n, raw.SASL.Mechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+		}
+		if sasl.Mechanism == SASLPlain && result.TLS == nil {
+			return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: sasl mechanism %s requires tls", n, SASLPlain)
+		}
+		if sasl.Username == "" || raw.SASL.Password == "" {
+			return BrokerSpec{}, errorf(ErrValidation, "config: invalid broker settings in %s: sasl needs both username and password", n)
+		}
+		sasl.Password, err = c.String(raw.SASL.Password)
+		if err != nil {
+			return BrokerSpec{}, err
+		}
+		result.SASL = sasl
+	}
+	return result, nil
+}
+
+// validBroker reports whether b is a host:port address or a URL with a host.
+func validBroker(b string) bool {
+	if strings.Contains(b, "://") {
+		u, err := url.Parse(b)
+		return err == nil && u.Hostname() != ""
+	}
+	host, port, err := net.SplitHostPort(b)
+	if err != nil {
+		return false
+	}
+	p, err := strconv.Atoi(port)
+	return err == nil && host != "" && p >= 1 && p <= 65535
+}
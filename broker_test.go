@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/x509"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_Broker(t *testing.T) {
+	cert, _ := testKeyPair(t, x509.ExtKeyUsageServerAuth)
+	dir := writeFiles(t, map[string]string{
+		"orders":         "brokers: [kafka-0.internal:9093, kafka-1.internal:9093]\ntopic: orders\ngroup: billing\ntls:\n  ca: kafka_ca\nsasl:\n  mechanism: scram-sha-512\n  username: billing\n  password: kafka_password\n",
+		"events":         `{"brokers": ["nats://nats.internal:4222"], "topic": "events.>", "group": "workers"}`,
+		"kafka_ca":       cert,
+		"kafka_password": "hunter2\n",
+		"missing":        "topic: orders\n",
+		"address":        "brokers: [kafka-0.internal]\n",
+		"duplicate":      "brokers: [k:9092, k:9092]\n",
+		"mechanism":      "brokers: [k:9092]\ntls: {}\nsasl:\n  mechanism: GSSAPI\n  username: u\n  password: kafka_password\n",
+		"plaintext":      "brokers: [k:9092]\nsasl:\n  username: u\n  password: kafka_password\n",
+		"password":       "brokers: [k:9092]\ntls: {}\nsasl:\n  username: u\n  password: nopassword\n",
+	})
+	c := New(WithPath(dir))
+
+	got, err := c.Broker("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TLS == nil || got.TLS.RootCAs == nil {
+		t.Errorf("Broker().TLS = %+v, want the kafka CA", got.TLS)
+	}
+	got.TLS = nil
+	want := BrokerSpec{
+		Brokers: []string{"kafka-0.internal:9093", "kafka-1.internal:9093"},
+		Topic:   "orders",
+		Group:   "billing",
+		SASL:    &SASLSpec{Mechanism: SASLScramSHA512, Username: "billing", Password: "hunter2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Broker() = %+v, want %+v", got, want)
+	}
+
+	got, err = c.Broker("events")
+	want = BrokerSpec{Brokers: []string{"nats://nats.internal:4222"}, Topic: "events.>", Group: "workers"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Broker() = %+v, %v, want %+v", got, err, want)
+	}
+
+	for _, n := range []string{"missing", "address", "duplicate", "mechanism", "plaintext", "password"} {
+		if _, err := c.Broker(n); err == nil {
+			t.Errorf("Broker(%q) error = nil, want an error", n)
+		}
+	}
+}
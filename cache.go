@@ -0,0 +1,151 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// decodeKey identifies a decoded configuration value in the decode cache.
+type decodeKey struct {
+	format string
+	name   string
+	typ    reflect.Type
+}
+
+// valueKey identifies a parsed configuration value in the value cache.
+type valueKey struct {
+	kind string
+	name string
+}
+
+// parsedValue is a parsed configuration value along with the source it was parsed from.
+type parsedValue struct {
+	src string
+	val interface{}
+}
+
+// The decode cache memoizes the results of InterfaceJson and InterfaceYaml. It maps
+// decodeKey to the reflect.Value that was decoded.
+//
+// The value cache memoizes the results of Url and Userinfo. It maps valueKey to
+// parsedValue. Values are re-parsed if their source no longer matches the
+// configuration value.
+//
+// The decode status records the outcome of the most recent decode of each value for
+// Report. It maps the value's name to "ok" or an error message.
+//
+// All three are reset whenever a new set of configuration values is loaded.
+
+// resetCaches discards everything derived from the previously loaded configuration values.
+func (s *store) resetCaches() {
+	for _, m := range []*sync.Map{&s.decodeCache, &s.valueCache, &s.decodeStatus, &s.deprecatedRead} {
+		m.Range(func(k, _ interface{}) bool {
+			m.Delete(k)
+			return true
+		})
+	}
+	s.unpacked.reset()
+}
+
+// cachedParse returns the value parsed from src by parse, memoizing the result per
+// (kind, n) for as long as src does not change.
+func (s *store) cachedParse(kind, n, src string, parse func(src string) (interface{}, error)) (interface{}, error) {
+	k := valueKey{kind: kind, name: n}
+	if cached, ok := s.valueCache.Load(k); ok && cached.(parsedValue).src == src {
+		return cached.(parsedValue).val, nil
+	}
+
+	result, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	s.valueCache.Store(k, parsedValue{src: src, val: result})
+	return result, nil
+}
+
+// cachedDecode decodes configuration value n into v using decode, memoizing the result per
+// (format, n, type of v). Cached values are deep copied into v so callers never share
+// memory. Only targets that point to a zero value are served from the cache, since
+// decoding into a populated value merges into it rather than replacing it.
+func (s *store) cachedDecode(format, n string, v interface{}, decode func(v interface{}) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !rv.Elem().IsZero() {
+		err := decode(v)
+		s.recordDecode(n, err)
+		return err
+	}
+
+	k := decodeKey{format: format, name: n, typ: rv.Type()}
+	if cached, ok := s.decodeCache.Load(k); ok {
+		rv.Elem().Set(deepCopy(cached.(reflect.Value)))
+		return nil
+	}
+
+	err := decode(v)
+	s.recordDecode(n, err)
+	if err != nil {
+		return err
+	}
+
+	s.decodeCache.Store(k, deepCopy(rv.Elem()))
+	return nil
+}
+
+// deepCopy returns a copy of v that shares no maps, slices or pointers with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	result := reflect.New(v.Type()).Elem()
+	copyInto(result, v)
+	return result
+}
+
+func copyInto(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		copyInto(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(deepCopy(src.Elem()))
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			copyInto(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyInto(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		// Assigning the whole struct first carries over unexported fields,
+		// which cannot be set individually.
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				copyInto(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
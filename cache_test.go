@@ -0,0 +1,193 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestInterfaceYaml_cached(t *testing.T) {
+	type app struct {
+		Server   map[string]interface{} `yaml:"server"`
+		Features []string               `yaml:"features"`
+	}
+
+	var first app
+	if err := InterfaceYaml("app.yaml", &first); err != nil {
+		t.Fatalf("InterfaceYaml() error = %v", err)
+	}
+	first.Features[0] = "mutated"
+	first.Server["host"] = "mutated"
+
+	var second app
+	if err := InterfaceYaml("app.yaml", &second); err != nil {
+		t.Fatalf("InterfaceYaml() error = %v", err)
+	}
+	want := app{
+		Server:   map[string]interface{}{"host": "localhost", "port": 8080},
+		Features: []string{"alpha", "beta"},
+	}
+	if !reflect.DeepEqual(second, want) {
+		t.Errorf("InterfaceYaml() got = %#v, want %#v", second, want)
+	}
+}
+
+func TestInterfaceJson_cachedPopulatedTarget(t *testing.T) {
+	type user struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	var zero user
+	if err := InterfaceJson("user.json", &zero); err != nil {
+		t.Fatalf("InterfaceJson() error = %v", err)
+	}
+
+	got := user{Password: "default"}
+	if err := InterfaceJson("user.json", &got); err != nil {
+		t.Fatalf("InterfaceJson() error = %v", err)
+	}
+	if want := (user{Username: "user", Password: "default"}); got != want {
+		t.Errorf("InterfaceJson() got = %#v, want %#v", got, want)
+	}
+}
+
+func TestConfig_Interface_cachedScoped(t *testing.T) {
+	c := New(WithPath(writeFiles(t, map[string]string{
+		"a.json":   `{"v": "root"}`,
+		"b.a.json": `{"v": "scoped"}`,
+		"a.yaml":   "v: root\n",
+		"b.a.yaml": "v: scoped\n",
+	})))
+	type value struct {
+		V string `json:"v" yaml:"v"`
+	}
+
+	tests := []struct {
+		name   string
+		decode func(c *Config, n string, v interface{}) error
+		file   string
+	}{
+		{name: "json", decode: (*Config).InterfaceJson, file: "a.json"},
+		{name: "yaml", decode: (*Config).InterfaceYaml, file: "a.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var root, scoped value
+			if err := tt.decode(c, tt.file, &root); err != nil {
+				t.Fatalf("decode(%q) error = %v", tt.file, err)
+			}
+			if err := tt.decode(c.Scoped("b."), tt.file, &scoped); err != nil {
+				t.Fatalf("Scoped().decode(%q) error = %v", tt.file, err)
+			}
+			if root.V != "root" || scoped.V != "scoped" {
+				t.Errorf("decode(%q) = %q, Scoped(\"b.\").decode(%q) = %q, want %q and %q", tt.file, root.V, tt.file, scoped.V, "root", "scoped")
+			}
+		})
+	}
+}
+
+func Test_deepCopy(t *testing.T) {
+	type inner struct {
+		Values []int
+		hidden string
+	}
+	type outer struct {
+		Ptr   *inner
+		Map   map[string]*inner
+		Any   interface{}
+		Array [2][]int
+	}
+
+	src := outer{
+		Ptr:   &inner{Values: []int{1}, hidden: "h"},
+		Map:   map[string]*inner{"a": {Values: []int{2}}},
+		Any:   []string{"x"},
+		Array: [2][]int{{3}, {4}},
+	}
+	got := deepCopy(reflect.ValueOf(src)).Interface().(outer)
+	if !reflect.DeepEqual(got, src) {
+		t.Fatalf("deepCopy() got = %#v, want %#v", got, src)
+	}
+
+	got.Ptr.Values[0] = 0
+	got.Map["a"].Values[0] = 0
+	got.Any.([]string)[0] = ""
+	got.Array[0][0] = 0
+	if src.Ptr.Values[0] != 1 || src.Map["a"].Values[0] != 2 || src.Any.([]string)[0] != "x" || src.Array[0][0] != 3 {
+		t.Errorf("deepCopy() shares memory with its source: %#v", src)
+	}
+	if got.Ptr.hidden != "h" {
+		t.Errorf("deepCopy() dropped unexported field: %#v", got.Ptr)
+	}
+}
+
+func BenchmarkInterfaceYaml(b *testing.B) {
+	type app struct {
+		Server   map[string]interface{} `yaml:"server"`
+		Features []string               `yaml:"features"`
+	}
+	for i := 0; i < b.N; i++ {
+		var v app
+		if err := InterfaceYaml("app.yaml", &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestUrl_cached(t *testing.T) {
+	first, err := Url("db_url")
+	if err != nil {
+		t.Fatalf("Url() error = %v", err)
+	}
+	want := first.String()
+
+	first.Host = "mutated"
+	*first.User = *url.User("mutated")
+
+	second, err := Url("db_url")
+	if err != nil {
+		t.Fatalf("Url() error = %v", err)
+	}
+	if got := second.String(); got != want {
+		t.Errorf("Url() got = %v, want %v", got, want)
+	}
+	if first == second || first.User == second.User {
+		t.Errorf("Url() returned shared memory")
+	}
+}
+
+func TestUserinfo_cached(t *testing.T) {
+	first, err := Userinfo("userpass.json")
+	if err != nil {
+		t.Fatalf("Userinfo() error = %v", err)
+	}
+	*first = *url.User("mutated")
+
+	second, err := Userinfo("userpass.json")
+	if err != nil {
+		t.Fatalf("Userinfo() error = %v", err)
+	}
+	if want := url.UserPassword("user", "pass"); !reflect.DeepEqual(second, want) {
+		t.Errorf("Userinfo() got = %v, want %v", second, want)
+	}
+}
+
+func Test_cachedParse(t *testing.T) {
+	s := new(store)
+	calls := 0
+	parse := func(src string) (interface{}, error) {
+		calls++
+		return src + "!", nil
+	}
+
+	for _, src := range []string{"a", "a", "b", "b"} {
+		got, err := s.cachedParse("test", "name", src, parse)
+		if err != nil || got != src+"!" {
+			t.Errorf("cachedParse(%q) = %v, %v, want %v", src, got, err, src+"!")
+		}
+	}
+	if calls != 2 {
+		t.Errorf("cachedParse() parsed %d times, want %d", calls, 2)
+	}
+}
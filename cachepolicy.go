@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Eviction is the strategy a cache uses to choose which entries to evict when it is full.
+type Eviction string
+
+const (
+	EvictLRU  Eviction = "lru"  // EvictLRU evicts the least recently used entry.
+	EvictLFU  Eviction = "lfu"  // EvictLFU evicts the least frequently used entry.
+	EvictFIFO Eviction = "fifo" // EvictFIFO evicts the oldest entry.
+)
+
+// CacheSpec is the size and lifetime limits of a cache, parsed by Config.CachePolicy.
+// Zero limits mean unlimited.
+type CacheSpec struct {
+	MaxEntries int           // MaxEntries is the maximum number of entries.
+	MaxBytes   int64         // MaxBytes is the maximum total size of the entries.
+	TTL        time.Duration // TTL is how long an entry is kept after it is added.
+	Eviction   Eviction      // Eviction is the strategy used when the cache is full.
+}
+
+type cachePolicy struct {
+	MaxEntries int    `yaml:"maxEntries"`
+	MaxBytes   string `yaml:"maxBytes"`
+	TTL        string `yaml:"ttl"`
+	Eviction   string `yaml:"eviction"`
+}
+
+// CachePolicy parses configuration value n into a CacheSpec. It expects a yaml or
+// json object of the form
+//		maxEntries: 10000
+//		maxBytes: 64MiB
+//		ttl: 5m
+//		eviction: lru
+//
+// All fields are optional. maxBytes is a number of bytes with an optional unit (B, KB, MB,
+// GB or TB, or KiB, MiB, GiB or TiB), ttl is parsed by time.ParseDuration, and eviction
+// is one of lru, lfu or fifo, and defaults to lru.
+func (c *Config) CachePolicy(n string) (CacheSpec, error) {
+	var raw cachePolicy
+	err := c.InterfaceYaml(n, &raw)
+	if err != nil {
+		return CacheSpec{}, err
+	}
+
+	result := CacheSpec{MaxEntries: raw.MaxEntries, Eviction: EvictLRU}
+	if result.MaxEntries < 0 {
+		return CacheSpec{}, errorf(ErrValidation, "config: failed to unmarshal %s into a cache policy: maxEntries is negative", n)
+	}
+	if raw.MaxBytes != "" {
+		result.MaxBytes, err = parseByteSize(raw.MaxBytes)
+		if err != nil {
+			return CacheSpec{}, errorf(ErrDecode, "config: failed to unmarshal %s into a cache policy: maxBytes: %w", n, err)
+		}
+	}
+	if raw.TTL != "" {
+		result.TTL, err = time.ParseDuration(raw.TTL)
+		if err != nil {
+			return CacheSpec{}, errorf(ErrDecode, "config: failed to unmarshal %s into a cache policy: ttl: %w", n, err)
+		}
+		if result.TTL < 0 {
+			return CacheSpec{}, errorf(ErrValidation, "config: failed to unmarshal %s into a cache policy: ttl is negative", n)
+		}
+	}
+	switch e := Eviction(strings.ToLower(raw.Eviction)); e {
+	case "":
+	case EvictLRU, EvictLFU, EvictFIFO:
+		result.Eviction = e
+	default:
+		return CacheSpec{}, errorf(ErrValidation, "config: failed to unmarshal %s into a cache policy: eviction is %q, which is not one of the allowed values: %q, %q, %q", n, raw.Eviction, EvictLRU, EvictLFU, EvictFIFO)
+	}
+	return result, nil
+}
+
+// byteUnits are the units accepted by parseByteSize, from longest to shortest suffix.
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+	{"b", 1},
+}
+
+// parseByteSize parses a non-negative number of bytes with an optional unit, such as
+// "512", "1.5GB" or "64 MiB".
+func parseByteSize(s string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	size := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v, size = strings.TrimSpace(strings.TrimSuffix(v, u.suffix)), u.size
+			break
+		}
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 || math.IsNaN(f) || f*float64(size) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	return int64(f * float64(size)), nil
+}
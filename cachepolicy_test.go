@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_CachePolicy(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"sessions": "maxEntries: 10000\nmaxBytes: 64MiB\nttl: 5m\neviction: LFU\n",
+		"plain":    `{"maxBytes": 1024}`,
+		"decimal":  "maxBytes: 1.5 GB\n",
+		"empty":    "{}",
+		"entries":  "maxEntries: -1\n",
+		"bytes":    "maxBytes: 12 parsecs\n",
+		"ttl":      "ttl: soon\n",
+		"eviction": "eviction: random\n",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    CacheSpec
+		wantErr bool
+	}{
+		{name: "sessions", want: CacheSpec{MaxEntries: 10000, MaxBytes: 64 << 20, TTL: 5 * time.Minute, Eviction: EvictLFU}},
+		{name: "plain", want: CacheSpec{MaxBytes: 1024, Eviction: EvictLRU}},
+		{name: "decimal", want: CacheSpec{MaxBytes: 1.5e9, Eviction: EvictLRU}},
+		{name: "empty", want: CacheSpec{Eviction: EvictLRU}},
+		{name: "entries", wantErr: true},
+		{name: "bytes", wantErr: true},
+		{name: "ttl", wantErr: true},
+		{name: "eviction", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CachePolicy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CachePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("CachePolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
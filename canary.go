@@ -0,0 +1,155 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Canary is a staged configuration set that is live for a fraction of the work of a
+// process, such as a fraction of its requests or workers, before it is committed for all
+// of it. It is created with Staged.Canary and finished with Promote or Abandon.
+//
+// Work is selected by a key supplied by the caller, such as a user or worker ID, so the
+// same key is always served by the same configuration while the fraction is unchanged,
+// and keys that are selected stay selected when the fraction is raised:
+//
+//		canary, err := staged.Canary(0.05)
+//		...
+//		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+//			cfg := canary.For(r.Header.Get("X-User-Id"))
+//			...
+//		})
+//		...
+//		err = canary.Promote()
+type Canary struct {
+	live      *Config
+	staged    *Staged
+	candidate *Config
+
+	mu       sync.RWMutex
+	fraction float64
+	done     bool
+}
+
+// Canary makes the staged values live for fraction of the work of the process, between 0
+// and 1, without committing them. The Config views returned by For for the selected work
+// read the staged values; they are a snapshot and are not reloaded. The staged values
+// cannot be committed directly while the canary is running; use Promote.
+func (st *Staged) Canary(fraction float64) (*Canary, error) {
+	if err := validFraction(fraction); err != nil {
+		return nil, err
+	}
+
+	s := st.s
+	s.mu.Lock()
+	if st.committed {
+		s.mu.Unlock()
+		return nil, errors.New("config: staged config already committed")
+	}
+	st.committed = true
+	generation := s.generation + 1
+	s.mu.Unlock()
+
+	candidate := &store{
+		options:    s.options,
+		parent:     s.parent,
+		tenant:     s.tenant,
+		resolved:   st.path,
+		val:        st.val,
+		report:     st.report,
+		generation: generation,
+		loadedAt:   time.Now(),
+	}
+	candidate.once.MarkLoaded()
+
+	return &Canary{
+		live:      &Config{s: s},
+		staged:    st,
+		candidate: &Config{s: candidate},
+		fraction:  fraction,
+	}, nil
+}
+
+// Fraction returns the fraction of work that c selects.
+func (c *Canary) Fraction() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fraction
+}
+
+// SetFraction changes the fraction of work that c selects, for example to ramp it up.
+func (c *Canary) SetFraction(fraction float64) error {
+	if err := validFraction(fraction); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fraction = fraction
+	return nil
+}
+
+// Selected reports whether the work identified by key is served by the staged values.
+// Nothing is selected once c is promoted or abandoned.
+func (c *Canary) Selected(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.done {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return float64(binary.BigEndian.Uint64(sum[:])>>11)/(1<<53) < c.fraction
+}
+
+// For returns the Config that the work identified by key should read: a view of the staged
+// values if it is selected, and the live Config otherwise.
+func (c *Canary) For(key string) *Config {
+	if c.Selected(key) {
+		return c.candidate
+	}
+	return c.live
+}
+
+// Promote commits the staged values for all work, as Staged.Commit does. Afterwards, For
+// returns the live Config for every key.
+func (c *Canary) Promote() error {
+	c.mu.Lock()
+	if c.done {
+		c.mu.Unlock()
+		return errors.New("config: canary already finished")
+	}
+	c.done = true
+	c.mu.Unlock()
+
+	// Promoting counts as the initial load, if it has not happened yet, unless the
+	// promotion fails.
+	s := c.live.s
+	loaded := s.once.setLoaded(true)
+	err := c.staged.commit("Promote")
+	if err != nil {
+		s.once.setLoaded(loaded)
+		return fmt.Errorf("config: encountered while promoting config: %w", err)
+	}
+	return nil
+}
+
+// Abandon discards the staged values. Afterwards, For returns the live Config for every
+// key.
+func (c *Canary) Abandon() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done = true
+}
+
+// validFraction checks that fraction is between 0 and 1.
+func validFraction(fraction float64) error {
+	if !(fraction >= 0 && fraction <= 1) {
+		return fmt.Errorf("config: canary fraction %v is outside the allowed range [0, 1]", fraction)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestStaged_Canary(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"level": "info"})
+	c := New(WithPath(dir))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "level"), []byte("debug"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	staged, err := c.Stage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := staged.Canary(1.5); err == nil {
+		t.Errorf("Canary() error = nil, want an out of range error")
+	}
+	canary, err := staged.Canary(0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := staged.Commit(); err == nil {
+		t.Errorf("Commit() error = nil, want the canary to own the staged config")
+	}
+
+	selected := 0
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		got, err := canary.For(key).String("level")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "info"
+		if canary.Selected(key) {
+			selected++
+			want = "debug"
+		}
+		if got != want {
+			t.Fatalf("For(%q).String() = %q, want %q", key, got, want)
+		}
+	}
+	if selected < 200 || selected > 300 {
+		t.Errorf("selected %d of 1000 keys, want about 250", selected)
+	}
+
+	if err := canary.SetFraction(1); err != nil || !canary.Selected("7") || canary.Fraction() != 1 {
+		t.Errorf("SetFraction() = %v, want every key to be selected", err)
+	}
+	if err := canary.SetFraction(0); err != nil || canary.Selected("7") {
+		t.Errorf("SetFraction() = %v, want no key to be selected", err)
+	}
+
+	gen := c.Generation()
+	if err := canary.Promote(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.String("level"); got != "debug" || c.Generation() != gen+1 {
+		t.Errorf("String() = %q, Generation() = %d, want %q, %d", got, c.Generation(), "debug", gen+1)
+	}
+	if canary.For("7") != canary.live {
+		t.Errorf("For() after Promote() did not return the live config")
+	}
+	if err := canary.Promote(); err == nil {
+		t.Errorf("Promote() error = nil, want an already finished error")
+	}
+}
+
+func TestCanary_Abandon(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"level": "info"})
+	c := New(WithPath(dir))
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "level"), []byte("debug"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	staged, err := c.Stage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canary, err := staged.Canary(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := canary.For("a").String("level"); got != "debug" {
+		t.Errorf("For().String() = %q, want %q", got, "debug")
+	}
+
+	canary.Abandon()
+	if got, _ := canary.For("a").String("level"); got != "info" {
+		t.Errorf("For().String() after Abandon() = %q, want %q", got, "info")
+	}
+	if err := canary.Promote(); err == nil {
+		t.Errorf("Promote() after Abandon() error = nil, want an error")
+	}
+}
+
+func TestCanary_Promote_initialRejected(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"level": "debug"})
+	c := New(WithPath(dir))
+	reject := true
+	c.OnLoad(func(*Config) error {
+		if reject {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	staged, err := c.Stage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canary, err := staged.Canary(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hookErr *HookError
+	if err := canary.Promote(); !errors.As(err, &hookErr) {
+		t.Fatalf("Promote() error = %v, want a *HookError", err)
+	}
+
+	reject = false
+	if err := ioutil.WriteFile(filepath.Join(dir, "level"), []byte("info"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.String("level"); err != nil || got != "info" {
+		t.Errorf("String() = %q, %v, want %q; a rejected Promote() must not count as the initial load", got, err, "info")
+	}
+}
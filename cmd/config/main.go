@@ -0,0 +1,127 @@
+// Command config inspects configuration using the same loader as services built with
+// github.com/ajjensen13/config.
+//
+// Usage:
+//
+//		config env [-path path] [-prefix prefix] [-redact] name...
+//		config manifests [-path path] [-namespace namespace] name
+//
+// The env subcommand prints an export statement for each environment variable that
+// ExportEnv would set for the named values, so shell scripts and Makefiles can use them:
+//
+//		eval "$(config env -prefix APP db_url app.yaml)"
+//
+// Values are single-quoted for POSIX shells. With -redact, the values of secrets (see
+// config.DefaultSecrets) are replaced with REDACTED.
+//
+// The manifests subcommand prints a Kubernetes ConfigMap and Secret with the given name
+// that hold every value on the search path, with secrets in the Secret, so the values
+// tested locally are the ones mounted in the cluster:
+//
+//		config manifests -path ./config -namespace billing billing-config | kubectl apply -f -
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ajjensen13/config"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err == flag.ErrHelp {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+const usage = `usage: config <command> [flags] [args]
+
+commands:
+  env        print shell export statements for configuration values
+  manifests  print Kubernetes ConfigMap and Secret manifests holding configuration values`
+
+// run runs the command line args, writing output to stdout and diagnostics to stderr.
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, usage)
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "env":
+		return runEnv(args[1:], stdout, stderr)
+	case "manifests":
+		return runManifests(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(stderr, usage)
+		return flag.ErrHelp
+	}
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func runEnv(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("env", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	path := fs.String("path", config.Path(), "the search path")
+	prefix := fs.String("prefix", "", "the prefix of the environment variable names")
+	redact := fs.Bool("redact", false, "replace the values of secrets with "+config.Redacted)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: config env [-path path] [-prefix prefix] [-redact] name...")
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	c := config.New(config.WithPath(*path))
+	for _, n := range fs.Args() {
+		vars, err := c.Environ(*prefix, n)
+		if err != nil {
+			return err
+		}
+		for _, kv := range vars {
+			i := strings.IndexByte(kv, '=')
+			k, v := kv[:i], kv[i+1:]
+			if *redact && c.IsSecret(n) {
+				v = config.Redacted
+			}
+			fmt.Fprintf(stdout, "export %s=%s\n", k, shellQuote(v))
+		}
+	}
+	return nil
+}
+
+func runManifests(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("manifests", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	path := fs.String("path", config.Path(), "the search path")
+	namespace := fs.String("namespace", "", "the namespace of the manifests")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: config manifests [-path path] [-namespace namespace] name")
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	return config.New(config.WithPath(*path)).WriteKubernetesManifests(stdout, fs.Arg(0), *namespace)
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
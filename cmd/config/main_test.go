@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_env(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for n, d := range map[string]string{
+		"db_url":      "postgres://db\n",
+		"db_password": "it's secret",
+		"app.yaml":    "server:\n  port: 8080\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, n), []byte(d), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "export",
+			args: []string{"env", "-path", dir, "-prefix", "APP", "db_url", "db_password", "app.yaml"},
+			want: "export APP_DB_URL='postgres://db'\n" +
+				"export APP_DB_PASSWORD='it'\\''s secret'\n" +
+				"export APP_SERVER_PORT='8080'\n",
+		},
+		{
+			name: "redact",
+			args: []string{"env", "-path", dir, "-redact", "db_url", "db_password"},
+			want: "export DB_URL='postgres://db'\n" +
+				"export DB_PASSWORD='REDACTED'\n",
+		},
+		{name: "missing", args: []string{"env", "-path", dir, "missing"}, wantErr: true},
+		{
+			name: "manifests",
+			args: []string{"manifests", "-path", dir, "-namespace", "billing", "app"},
+			want: "apiVersion: v1\n" +
+				"kind: ConfigMap\n" +
+				"metadata:\n" +
+				"  name: app\n" +
+				"  namespace: billing\n" +
+				"data:\n" +
+				"  app.yaml: |\n" +
+				"    server:\n" +
+				"      port: 8080\n" +
+				"  db_url: |\n" +
+				"    postgres://db\n" +
+				"---\n" +
+				"apiVersion: v1\n" +
+				"kind: Secret\n" +
+				"metadata:\n" +
+				"  name: app\n" +
+				"  namespace: billing\n" +
+				"type: Opaque\n" +
+				"data:\n" +
+				"  db_password: aXQncyBzZWNyZXQ=\n",
+		},
+		{name: "manifests without name", args: []string{"manifests", "-path", dir}, wantErr: true},
+		{name: "unknown command", args: []string{"bogus"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tt.args, &stdout, &stderr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("run() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"compress/flate"
+	"container/list"
+	"io/ioutil"
+	"sync"
+)
+
+// DefaultCompressionCache is the number of decompressed values kept by WithCompression
+// when it is given a cache size of 0.
+const DefaultCompressionCache = 16
+
+// WithCompression keeps the loaded configuration values of at least minSize bytes
+// compressed in memory (with DEFLATE, from compress/flate), and decompresses them when
+// they are read. The cacheSize most recently read values stay decompressed, so values
+// that are read often are not decompressed each time; it defaults to
+// DefaultCompressionCache if it is 0. This cuts the steady-state memory of services that
+// load large, rarely read reference files, at the cost of an allocation and some CPU
+// whenever a value that is not in the cache is read. If cacheSize is negative, no values
+// are cached. Compression is disabled by default, and if minSize is 0 or less.
+//
+// Values are compressed with DEFLATE rather than zstd because the standard library has no
+// zstd encoder, and this package avoids depending on one for an in-memory format that is
+// never stored or exchanged. DEFLATE compresses text about as well at a lower speed.
+//
+//		c := config.New(config.WithCompression(64<<10, 8))
+func WithCompression(minSize, cacheSize int) Option {
+	return func(o *options) {
+		if cacheSize == 0 {
+			cacheSize = DefaultCompressionCache
+		}
+		o.compressMin = minSize
+		o.compressCache = cacheSize
+	}
+}
+
+// pack compresses the data of the values in m that are at least s.compressMin bytes
+// long, if compression is enabled.
+func (s *store) pack(m map[string]entry) {
+	if s.compressMin <= 0 {
+		return
+	}
+	for n, e := range m {
+		if e.packed != nil || len(e.data) < s.compressMin {
+			continue
+		}
+
+		var b bytes.Buffer
+		w, _ := flate.NewWriter(&b, flate.DefaultCompression)
+		_, _ = w.Write(e.data)
+		_ = w.Close()
+
+		e.packed = b.Bytes()
+		e.size = len(e.data)
+		e.data, e.str = nil, nil
+		m[n] = e
+	}
+}
+
+// unpacked returns e with its data decompressed, if it is compressed.
+func (e entry) unpacked() entry {
+	if e.packed == nil {
+		return e
+	}
+	d, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(e.packed)))
+	if err != nil {
+		panic("config: corrupt compressed value " + e.path + ": " + err.Error())
+	}
+	e.data, e.packed, e.size = d, nil, 0
+	return e
+}
+
+// length returns the length of the data of e, whether or not it is compressed.
+func (e entry) length() int {
+	if e.packed != nil {
+		return e.size
+	}
+	return len(e.data)
+}
+
+// unpackCache holds the most recently read decompressed values of a store.
+type unpackCache struct {
+	mu    sync.Mutex
+	order *list.List // order holds the *unpackedValue elements, most recently read first.
+	m     map[string]*list.Element
+}
+
+// unpackedValue is a decompressed value in an unpackCache.
+type unpackedValue struct {
+	name   string
+	packed []byte // packed is the compressed data, which identifies the version of the value.
+	data   []byte
+	str    *lazyString
+}
+
+// unpack returns e, the entry for configuration value n, with its data decompressed,
+// using the cache of s.
+func (s *store) unpack(n string, e entry) entry {
+	if e.packed == nil {
+		return e
+	}
+
+	s.unpacked.mu.Lock()
+	defer s.unpacked.mu.Unlock()
+	if s.unpacked.m == nil {
+		s.unpacked.m = map[string]*list.Element{}
+		s.unpacked.order = list.New()
+	}
+
+	if el, ok := s.unpacked.m[n]; ok {
+		v := el.Value.(*unpackedValue)
+		if &v.packed[0] == &e.packed[0] {
+			s.unpacked.order.MoveToFront(el)
+			e.data, e.str, e.packed = v.data, v.str, nil
+			return e
+		}
+		s.unpacked.order.Remove(el)
+		delete(s.unpacked.m, n)
+	}
+
+	packed := e.packed
+	e = e.unpacked()
+	e.str = &lazyString{}
+	s.unpacked.m[n] = s.unpacked.order.PushFront(&unpackedValue{name: n, packed: packed, data: e.data, str: e.str})
+	for s.unpacked.order.Len() > 0 && s.unpacked.order.Len() > s.compressCache {
+		el := s.unpacked.order.Back()
+		s.unpacked.order.Remove(el)
+		delete(s.unpacked.m, el.Value.(*unpackedValue).name)
+	}
+	return e
+}
+
+// reset removes every value from c.
+func (c *unpackCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m, c.order = nil, nil
+}
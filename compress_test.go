@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithCompression(t *testing.T) {
+	large := strings.Repeat("reference data\n", 100)
+	tests := []struct {
+		name       string
+		minSize    int
+		cacheSize  int
+		wantPacked bool
+	}{
+		{name: "disabled", minSize: 0},
+		{name: "below minimum", minSize: len(large) + 1},
+		{name: "cached", minSize: 64, cacheSize: 1, wantPacked: true},
+		{name: "uncached", minSize: 64, cacheSize: -1, wantPacked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"large": large, "other": large, "small": "x"})
+			c := New(WithPath(dir), WithTrimSpace(false), WithCompression(tt.minSize, tt.cacheSize))
+			if err := c.Load(); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			e := c.s.val["large"]
+			if got := e.packed != nil; got != tt.wantPacked {
+				t.Errorf("packed = %v, want %v", got, tt.wantPacked)
+			}
+			if tt.wantPacked && (e.data != nil || len(e.packed) >= len(large)) {
+				t.Errorf("packed %d bytes into %d, data = %d bytes", len(large), len(e.packed), len(e.data))
+			}
+			if c.s.val["small"].packed != nil {
+				t.Errorf("small value was packed")
+			}
+
+			for _, n := range []string{"large", "other", "large"} {
+				got, err := c.String(n)
+				if err != nil || got != large {
+					t.Errorf("String(%q) got = %d bytes, %v, want %d bytes", n, len(got), err, len(large))
+				}
+			}
+
+			var dump bytes.Buffer
+			if err := c.Dump(&dump); err != nil || !strings.Contains(dump.String(), "reference data") {
+				t.Errorf("Dump() error = %v", err)
+			}
+
+			var changed []string
+			c.OnChange(func(ev ChangeEvent) { changed = ev.Changed })
+			if err := ioutil.WriteFile(filepath.Join(dir, "large"), []byte(large+"more\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Reload(); err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			if len(changed) != 1 || changed[0] != "large" {
+				t.Errorf("changed = %v, want [large]", changed)
+			}
+			if got, err := c.String("large"); err != nil || got != large+"more\n" {
+				t.Errorf("String() after reload got = %d bytes, %v", len(got), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stressConcurrently runs each of ops repeatedly from its own goroutines until d has
+// passed, failing t with the first error returned.
+func stressConcurrently(t *testing.T, d time.Duration, ops map[string]func(i int) error) {
+	t.Helper()
+	deadline := time.Now().Add(d)
+	errs := make(chan error, len(ops)*2)
+	var wg sync.WaitGroup
+	for name, op := range ops {
+		for g := 0; g < 2; g++ {
+			wg.Add(1)
+			go func(name string, op func(int) error) {
+				defer wg.Done()
+				for i := 0; time.Now().Before(deadline); i++ {
+					err := op(i)
+					if err != nil {
+						errs <- fmt.Errorf("%s: %w", name, err)
+						return
+					}
+				}
+			}(name, op)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestConfig_concurrentUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"enabled": true}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "sources", opts: []Option{
+			WithSource(CircuitBreaker(TTL(staticSource{"remote": "1"}, time.Nanosecond))),
+			WithSource(HTTP(srv.URL + "/features.json")),
+		}},
+		{name: "compressed", opts: []Option{WithCompression(1, 2)}},
+		{name: "snapshot", opts: []Option{WithSnapshot(filepath.Join(t.TempDir(), "snapshot"))}},
+		{name: "normalized", opts: []Option{WithNameNormalizer(strings.ToLower), WithMigration("old", "name", nil)}},
+		{name: "validated", opts: []Option{WithRequired("name"), WithValidator("port", func(n string, b []byte) error {
+			_, err := strconv.Atoi(string(b))
+			return err
+		})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConcurrentUse(t, tt.opts)
+		})
+	}
+}
+
+// testConcurrentUse stresses the API of a Config created with opts from many goroutines,
+// to be run with the race detector.
+func testConcurrentUse(t *testing.T, opts []Option) {
+	dir := writeFiles(t, map[string]string{
+		"name":              "shared",
+		"port":              "8080",
+		"db.json":           `{"host": "db1", "pool": 4}`,
+		"rules.yaml":        "limit: 10",
+		"tenants/acme/name": "acme",
+	})
+	tmp := t.TempDir()
+	c := New(append([]Option{WithPath(dir), WithLoadRetry(0), WithWarnings(func(Warning) {})}, opts...)...)
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	type db struct {
+		Host string `json:"host"`
+		Pool int    `json:"pool"`
+	}
+	var bound db
+	b, err := c.Register("db.json", &bound)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watched := make(chan error, 1)
+	go func() { watched <- c.Watch(ctx) }()
+
+	ops := map[string]func(i int) error{
+		"Load": func(int) error { return c.Load() },
+		"Reload": func(int) error {
+			return c.Reload()
+		},
+		"write": func(i int) error {
+			// Replace the file atomically, as deployments do, so it is never read half-written.
+			tmp, err := ioutil.TempFile(tmp, "port")
+			if err != nil {
+				return err
+			}
+			_, err = tmp.WriteString(strconv.Itoa(8000 + i%10))
+			if cerr := tmp.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			return os.Rename(tmp.Name(), filepath.Join(dir, "port"))
+		},
+		"SetDefault": func(i int) error {
+			c.SetDefaultString("timeout", strconv.Itoa(i))
+			return nil
+		},
+		"Stage": func(int) error {
+			st, err := c.Stage()
+			if err != nil {
+				return err
+			}
+			return st.Commit()
+		},
+		"read": func(int) error {
+			if _, err := c.Port("port"); err != nil {
+				return err
+			}
+			if _, err := c.String("name"); err != nil {
+				return err
+			}
+			var v db
+			if err := c.InterfaceJson("db.json", &v); err != nil {
+				return err
+			}
+			var r map[string]int
+			if err := c.InterfaceYaml("rules.yaml", &r); err != nil {
+				return err
+			}
+			_, err := c.Origin("name")
+			return err
+		},
+		"views": func(i int) error {
+			if _, err := c.ForTenant("acme").String("name"); err != nil {
+				return err
+			}
+			if _, err := c.Scoped("db.").Bytes("json"); err != nil {
+				return err
+			}
+			ctx := WithValues(context.Background(), map[string]interface{}{"port": i})
+			_, err := c.WithContext(ctx).Int("port")
+			return err
+		},
+		"fresh": func(int) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, _, err := c.BytesFresh(ctx, "port", time.Millisecond)
+			return err
+		},
+		"subscribe": func(int) error {
+			cancelChange := c.OnChange(func(ChangeEvent) {})
+			cancelLoad := c.OnLoad(func(c *Config) error {
+				_, err := c.Bytes("name")
+				return err
+			})
+			cancelChange()
+			cancelLoad()
+			return nil
+		},
+		"inspect": func(int) error {
+			_ = c.Generation()
+			_ = c.LoadedAt()
+			_ = c.Report()
+			if _, err := c.Group("*.yaml").Names(); err != nil {
+				return err
+			}
+			if _, err := c.Verify(); err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			if err := c.Dump(&buf); err != nil {
+				return err
+			}
+			return c.Export(&buf)
+		},
+		"binding": func(int) error {
+			b.RLock()
+			defer b.RUnlock()
+			if bound.Host != "db1" {
+				return fmt.Errorf("bound host = %q, want db1", bound.Host)
+			}
+			return nil
+		},
+	}
+	stressConcurrently(t, 200*time.Millisecond, ops)
+
+	cancel()
+	if err := <-watched; err != nil && err != context.Canceled {
+		t.Errorf("Watch() error = %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConditionKey is the key that makes a JSON object or YAML mapping conditional when
+// conditions are enabled with WithConditions.
+const ConditionKey = "when"
+
+// WithConditions enables conditional blocks in the values decoded by InterfaceJson,
+// InterfaceYaml and YamlNode. An object or mapping with a ConditionKey member is kept
+// only if its condition is true, and is removed from its parent object or array
+// otherwise. The condition member itself is always removed. Conditions are evaluated
+// against vars; for example, with vars {"env": "prod"}:
+//
+//		servers:
+//		  - when: env == "prod"
+//		    host: db.internal
+//		  - when: env != "prod"
+//		    host: localhost
+//
+// decodes as a single server with host db.internal. Conditions compare variables and
+// quoted strings with == and !=, match regular expressions with =~, and combine
+// comparisons with &&, || and ! and parentheses. A variable on its own is true unless it
+// is empty, "0" or "false". Variables missing from vars are empty.
+func WithConditions(vars map[string]string) Option {
+	return func(o *options) {
+		o.conditions = map[string]string{}
+		for k, v := range vars {
+			o.conditions[k] = v
+		}
+	}
+}
+
+// filterJson returns the JSON document b with its conditional blocks resolved.
+func filterJson(b []byte, vars map[string]string) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	err := d.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err = filterValue(v, vars)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// filterValue resolves the conditional blocks in v, a value decoded from JSON. It reports
+// whether v itself should be kept.
+func filterValue(v interface{}, vars map[string]string) (interface{}, bool, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if cond, ok := v[ConditionKey]; ok {
+			keep, err := evalCondition(fmt.Sprint(cond), vars)
+			if err != nil || !keep {
+				return nil, false, err
+			}
+			delete(v, ConditionKey)
+		}
+		for k, e := range v {
+			e, keep, err := filterValue(e, vars)
+			if err != nil {
+				return nil, false, err
+			}
+			if keep {
+				v[k] = e
+			} else {
+				delete(v, k)
+			}
+		}
+	case []interface{}:
+		result := v[:0]
+		for _, e := range v {
+			e, keep, err := filterValue(e, vars)
+			if err != nil {
+				return nil, false, err
+			}
+			if keep {
+				result = append(result, e)
+			}
+		}
+		return result, true, nil
+	}
+	return v, true, nil
+}
+
+// filterYaml resolves the conditional blocks in n. It reports whether n itself should be
+// kept.
+func filterYaml(n *yaml.Node, vars map[string]string) (bool, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			keep, err := filterYaml(c, vars)
+			if err != nil {
+				return false, err
+			}
+			if !keep {
+				n.Content = nil
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value != ConditionKey {
+				continue
+			}
+			keep, err := evalCondition(n.Content[i+1].Value, vars)
+			if err != nil {
+				return false, fmt.Errorf("line %d: %w", n.Content[i+1].Line, err)
+			}
+			if !keep {
+				return false, nil
+			}
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			break
+		}
+
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			keep, err := filterYaml(n.Content[i+1], vars)
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				content = append(content, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		content := n.Content[:0]
+		for _, c := range n.Content {
+			keep, err := filterYaml(c, vars)
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				content = append(content, c)
+			}
+		}
+		n.Content = content
+	}
+	return true, nil
+}
+
+// evalCondition evaluates condition expr against vars. Conditions are parsed like Eval
+// expressions, but their operands are strings and identifiers may contain dashes.
+func evalCondition(expr string, vars map[string]string) (bool, error) {
+	x, err := parseExpr(expr, "_.-")
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	result, err := conditionTrue(x, vars)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	return result, nil
+}
+
+// conditionTrue evaluates condition x against vars. The right operands of && and || are
+// evaluated only if the left ones do not decide the result.
+func conditionTrue(x exprNode, vars map[string]string) (bool, error) {
+	switch x := x.(type) {
+	case exprUnary:
+		if x.op == "!" {
+			result, err := conditionTrue(x.x, vars)
+			return !result, err
+		}
+	case exprBinary:
+		switch x.op {
+		case "&&", "||":
+			l, err := conditionTrue(x.x, vars)
+			if err != nil || l == (x.op == "||") {
+				return l, err
+			}
+			return conditionTrue(x.y, vars)
+		case "==", "!=", "=~":
+			l, err := conditionOperand(x.x, vars)
+			if err != nil {
+				return false, err
+			}
+			r, err := conditionOperand(x.y, vars)
+			if err != nil {
+				return false, err
+			}
+			switch x.op {
+			case "==":
+				return l == r, nil
+			case "!=":
+				return l != r, nil
+			}
+			re, err := regexp.Compile(r)
+			if err != nil {
+				return false, err
+			}
+			return re.MatchString(l), nil
+		}
+	}
+	v, err := conditionOperand(x, vars)
+	return v != "" && v != "0" && v != "false", err
+}
+
+// conditionOperand returns the value of x, which must be a literal or a variable.
+func conditionOperand(x exprNode, vars map[string]string) (string, error) {
+	switch x := x.(type) {
+	case exprLiteral:
+		return x.text, nil
+	case exprIdent:
+		return vars[x.name], nil
+	case exprUnary:
+		return "", fmt.Errorf("unsupported operator %q", x.op)
+	case exprBinary:
+		return "", fmt.Errorf("unsupported operator %q", x.op)
+	}
+	return "", errors.New(`unsupported operator "?"`)
+}
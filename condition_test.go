@@ -0,0 +1,107 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_evalCondition(t *testing.T) {
+	vars := map[string]string{"env": "prod", "hostname": "web-3", "debug": "false", "region": "eu"}
+	tests := []struct {
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{expr: `env == "prod"`, want: true},
+		{expr: `env != 'prod'`, want: false},
+		{expr: `hostname =~ "^web-[0-9]+$"`, want: true},
+		{expr: `env == "prod" && region == "us"`, want: false},
+		{expr: `env == "dev" || region == "eu"`, want: true},
+		{expr: `!(env == "dev") && !debug`, want: true},
+		{expr: `env == "dev" && hostname =~ "("`, want: false},
+		{expr: `region == "eu" || hostname =~ "("`, want: true},
+		{expr: `missing`, want: false},
+		{expr: `true`, want: true},
+		{expr: `env ==`, wantErr: true},
+		{expr: `env == "prod`, wantErr: true},
+		{expr: `(env == "prod"`, wantErr: true},
+		{expr: `env == "prod" region`, wantErr: true},
+		{expr: `hostname =~ "("`, wantErr: true},
+		{expr: `env + "x"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := evalCondition(tt.expr, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evalCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("evalCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConditions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"servers.yaml": `
+servers:
+  - when: env == "prod"
+    host: db.internal
+  - when: env != "prod"
+    host: localhost
+  - host: always
+debug:
+  when: env == "dev"
+  level: 5
+`,
+		"servers.json": `{
+  "servers": [
+    {"when": "env == \"prod\"", "host": "db.internal"},
+    {"when": "env != \"prod\"", "host": "localhost"},
+    {"host": "always"}
+  ],
+  "debug": {"when": "env == \"dev\"", "level": 5}
+}`,
+		"bad.yaml": "a:\n  when: env ==\n",
+	})
+	c := New(WithPath(dir), WithConditions(map[string]string{"env": "prod"}))
+
+	type server struct {
+		Host string `json:"host" yaml:"host"`
+	}
+	type config struct {
+		Servers []server       `json:"servers" yaml:"servers"`
+		Debug   map[string]int `json:"debug" yaml:"debug"`
+	}
+	want := config{Servers: []server{{Host: "db.internal"}, {Host: "always"}}}
+
+	var got config
+	if err := c.InterfaceYaml("servers.yaml", &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("InterfaceYaml() = %+v, %v, want %+v", got, err, want)
+	}
+
+	got = config{}
+	if err := c.InterfaceJson("servers.json", &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("InterfaceJson() = %+v, %v, want %+v", got, err, want)
+	}
+
+	node, err := c.YamlNode("servers.yaml")
+	if err != nil {
+		t.Fatalf("YamlNode() error = %v", err)
+	}
+	if got := len(node.Content[0].Content); got != 2 {
+		t.Errorf("YamlNode() has %d mapping entries, want 2", got)
+	}
+
+	var v interface{}
+	err = c.InterfaceYaml("bad.yaml", &v)
+	if de, ok := err.(*DecodeError); !ok || de.Line != 2 {
+		t.Errorf("InterfaceYaml() error = %v, want *DecodeError on line 2", err)
+	}
+
+	var raw map[string]interface{}
+	if err := New(WithPath(dir)).InterfaceYaml("servers.yaml", &raw); err != nil || raw["debug"] == nil {
+		t.Errorf("InterfaceYaml() without conditions = %v, %v, want debug block kept", raw, err)
+	}
+}
@@ -2,21 +2,73 @@
 // It reads a search path from the CONFIG_PATH environment variable. All files found along the
 // search path are read and cached and are accessible by file name.
 //
-// Currently, the config package does not support recursive searching; directories found on the
-// search path are ignored.
+// The package-level functions operate on a default *Config created on first use. Additional
+// Configs with their own search path and options can be created with New.
+//
+// By default, the config package does not search recursively; directories found on the
+// search path are ignored. WithMaxDepth enables recursive searching.
+//
+// Search path entries are usually directories, but may also be individual files, which are
+// loaded under their base name. This allows a single file to be mounted without a
+// directory to contain it.
+//
+// Entries that are URLs with a registered scheme are read by the corresponding Source:
+// "file:///etc/config" is a directory (or file) like "/etc/config", "https://host/name"
+// is an HTTPSource, "azblob://container/prefix" is an AzureBlobSource, and
+// "exec:command" is an ExecSource. The entry "-" is standard input; see StdinSource.
+// Any other entry is a directory (or file) path.
+//
+// Search path entries ending in "?" are optional: if the directory (or file) does not
+// exist, it is skipped rather than causing Load to fail. For example, with a CONFIG_PATH
+// of "./config.local?:/etc/config", a developer can override values locally without a
+// local directory being required in production.
+//
+// Loading is deterministic regardless of the operating system, file system or Source
+// implementation. Search path entries are read in order, followed by sources added with
+// WithSource in the order they were added. The files of each are processed sorted by name
+// (then by path), so when two files share a name, the one that wins, or the one that is
+// reported first in a *DuplicateError, is always the same: the one from the
+// higher-priority source, then, with Shadow, the one from the earlier source.
+//
+// A file named after another with ".meta" appended, such as "tls.crt.meta", holds metadata
+// about it rather than a value of its own; see Expiries.
+//
+// Concurrency
+//
+// A Config, and the views derived from it with Scoped, ForTenant and WithContext, is safe
+// for concurrent use: Load, Reload, Stage and Commit, Watch, SetDefault, the registration
+// methods such as OnChange, OnLoad and Register, and every accessor may be called from any
+// number of goroutines at once, and so may the package-level functions. A value read by an
+// accessor is either its version from before a reload or the one from after it, never a
+// partial one. Overlapping reloads each commit a complete set of values, and the last to
+// finish wins.
+//
+// Functions passed to a Config, such as OnChange subscribers, OnLoad hooks, Validators,
+// Transformers and warning handlers, and custom Sources, may be called concurrently by
+// overlapping reloads, so they must be safe for concurrent use too; the Sources provided
+// by the package are. Data returned by accessors such as Bytes is shared and must not be
+// modified, and Options and package-level variables such as TrimSpace must not be changed
+// while the Configs using them are in use.
 package config
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"gopkg.in/yaml.v2"
-	"io/ioutil"
+	"gopkg.in/yaml.v3"
 	"log"
+	"math"
+	"math/big"
 	"net/url"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
+	"time"
 )
 
 // IsKubernetes determines if we are running in a kubernetes cluster. It checks for the presence of
@@ -51,82 +103,731 @@ func Path() string {
 	return env
 }
 
-var (
-	pkgOnce sync.Once
-	pkgVal  map[string][]byte
-	pkgErr  error
-)
+// entry is a single loaded configuration value along with the file it was read from.
+type entry struct {
+	data  []byte
+	path  string
+	index int // index is the position of the directory or Source containing path; see DuplicateError.
 
-// Load loads the configuration into memory. After it has been called once, calling
-// it again will have no effect.
-func Load() error {
-	pkgOnce.Do(func() {
-		result := map[string][]byte{}
-		p := Path()
-		log.Printf("config: %s=%s", EnvVar, p)
-		ps := filepath.SplitList(p)
-
-		var files []string
-		for _, p := range ps {
-			fis, err := ioutil.ReadDir(p)
-			if err != nil {
-				pkgErr = fmt.Errorf("config: error reading directory %q: %w", p, err)
-				return
-			}
-			for _, fi := range fis {
-				f := filepath.Join(p, fi.Name())
-				if fi.IsDir() {
-					continue
-				}
+	source   string // source describes the directory or Source containing path.
+	priority int    // priority is the priority of the directory or Source; see WithSourcePriority.
 
-				b := filepath.Base(f)
-				if result[b] != nil {
-					pkgErr = fmt.Errorf("config: multiple config entries with name: %q", b)
-					return
-				}
+	expires time.Time   // expires is when the value expires, if it declares it; see Expiries.
+	sum     []byte      // sum is the SHA-256 hash of data when it was loaded; see WithMutationPolicy.
+	str     *lazyString // str caches data converted to a string, so String does not allocate.
+	stale   bool        // stale is true if the file of the value is gone; see KeepMissing.
+	packed  []byte      // packed is data compressed, in which case data is empty; see WithCompression.
+	size    int         // size is the length of data before it was compressed.
+}
+
+// DuplicateError is returned by Load when two files on the search path have the same name.
+// Directories are numbered by their position in the search path, and sources added with
+// WithSource are numbered after them in the order they were added.
+type DuplicateError struct {
+	Name    string    // Name is the name shared by both files.
+	Paths   [2]string // Paths are the conflicting files, in the order they were found.
+	Indices [2]int    // Indices are the positions of the directories or sources containing Paths.
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("config: multiple config entries with name %q: %q (path entry %d) and %q (path entry %d)",
+		e.Name, e.Paths[0], e.Indices[0], e.Paths[1], e.Indices[1])
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// TrimSpace controls whether the scalar accessors (String, Int, Duration and Url) trim
+// surrounding whitespace, including trailing newlines, from configuration values. It is
+// true by default because files written with `echo value > file` and values round-tripped
+// through Kubernetes Secrets routinely end in a newline. Bytes is never affected.
+//
+// TrimSpace is the default for WithTrimSpace. It must be set before the package-level
+// functions are first used to affect them.
+var TrimSpace = true
+
+// Shadow controls how Load handles files with the same name in different search path
+// directories. If it is false (the default), Load fails with a *DuplicateError. If it is
+// true, files in earlier directories shadow files in later ones, like executable lookup
+// in PATH. This allows a local override directory to be prepended to the search path.
+//
+// Shadow is the default for WithShadow. It must be set before the package-level
+// functions are first used to affect them.
+var Shadow = false
+
+// Config is a set of configuration values loaded from a search path. Configs are created
+// with New and are safe for concurrent use; see the package documentation.
+type Config struct {
+	s      *store
+	prefix string
+	names  *nameCache    // names caches the prefixed names of a Scoped view.
+	values contextValues // values are the overrides of a view returned by WithContext.
+}
+
+// maxCachedNames bounds the number of names cached by each Scoped view and each name
+// normalizer, so those that read an unbounded set of names do not grow without limit.
+const maxCachedNames = 1024
+
+// nameCache maps the names read through a Scoped view or a name normalizer to their
+// prefixed or normalized names, so reads do not compute them each time.
+type nameCache struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// options holds the settings of a Config, as configured by Options.
+type options struct {
+	path      string // path is the search path if pathSet, otherwise Path() is used at load time.
+	pathSet   bool
+	trimSpace bool
+	shadow    bool
+	sources   []weightedSource
+	manifest  string
+	bootstrap string // bootstrap is the bootstrap file the options were read from, if any.
+	snapshot  string // snapshot is the file the values are persisted to; see WithSnapshot.
+
+	pathPriority int
+	pollInterval time.Duration
+	secrets      []string
+	maxDepth     int
+	prune        []string
+	normalize    func(string) string // normalize normalizes value names; see WithNameNormalizer.
+
+	transformers []Transformer
+	migrations   []migration
+	pgp          *PGPKeys
+	auth         Auth
+
+	templates     bool
+	templateFuncs template.FuncMap
+
+	conditions     map[string]string // conditions are the variables of conditional blocks, or nil if they are disabled.
+	anchors        string
+	extends        string
+	listMerges     map[string]map[string]ListMerge
+	overridePrefix string
+	expiryWarning  time.Duration
+	loadRetry      time.Duration
+	removals       []removalRule
+	humanNumbers   bool
+	compressMin    int
+	compressCache  int
+
+	required   []string
+	validators map[string][]Validator
+
+	errorPolicy    ErrorPolicy
+	errorPolicySet bool
+	mutationPolicy MutationPolicy
+	audit          []func(AuditRecord)
+	warnings       []func(Warning)
+	reloadHooks    []ReloadHook
+	deprecated     map[string]string
+}
+
+// store holds the state shared by a Config and the views derived from it.
+type store struct {
+	// nextSwitch is when the next scheduled value takes effect, in Unix nanoseconds, or 0.
+	// It is accessed atomically, so it comes first to be 64-bit aligned.
+	nextSwitch int64
 
-				d, err := ioutil.ReadFile(f)
-				if err != nil {
+	options
+
+	// parent is the store that values not found in this one fall back to, if any.
+	// Tenant stores derive their search path from it.
+	parent *store
+	tenant string
+
+	once loadOnce
+
+	mu       sync.RWMutex
+	resolved string // resolved is the search path the current values were loaded from.
+	val      map[string]entry
+	err      error
+	tenants  map[string]*store
+	report   *LoadReport
+	subs     map[int]func(ChangeEvent)
+	nextSub  int
+	onLoad   []loadHook        // onLoad are the functions registered with OnLoad, in order.
+	defaults map[string][]byte // defaults are the values registered with SetDefault.
+	frozen   bool              // frozen is true once Freeze has been called.
+	loadedAt time.Time         // loadedAt is when val was last replaced.
+	schedule []scheduledEntry  // schedule are the scheduled versions of val that have not taken effect.
+
+	generation uint64 // generation counts the times val has been replaced.
+
+	refresh *refreshCall // refresh is the reload started by BytesFresh that is in progress, or nil.
+
+	snapshotMu sync.Mutex // snapshotMu serializes writes to the snapshot file.
+
+	auditMu   sync.Mutex
+	auditHash string // auditHash is the Hash of the last AuditRecord.
+
+	decodeCache  sync.Map
+	valueCache   sync.Map
+	decodeStatus sync.Map
+	accessed     sync.Map // accessed holds the names of the values that have been looked up.
+
+	deprecatedRead sync.Map // deprecatedRead holds the deprecated values read since the last load.
+	unpacked       unpackCache
+	entries        entrySources // entries holds the Sources of the search path entries read by s.
+	normalized     nameCache    // normalized caches the names passed through normalize.
+}
+
+// New returns a Config that loads its values according to opts. Options that are not
+// provided default to the values of the package-level variables (Path(), TrimSpace and
+// Shadow) at the time New is called, except for the search path, which is resolved when
+// the Config is loaded.
+func New(opts ...Option) *Config {
+	return &Config{s: &store{options: newOptions(opts)}}
+}
+
+// newOptions returns the default options with opts applied.
+func newOptions(opts []Option) options {
+	o := options{
+		trimSpace:      TrimSpace,
+		shadow:         Shadow,
+		manifest:       DefaultManifest,
+		secrets:        DefaultSecrets,
+		prune:          DefaultPrune,
+		overridePrefix: OverridePrefix,
+		expiryWarning:  DefaultExpiryWarning,
+		loadRetry:      DefaultLoadRetry,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Scoped returns a view of c in which every configuration value name is prefixed with
+// prefix. For example, c.Scoped("billing/").String("url") returns c.String("billing/url").
+// This allows libraries to accept a *Config and read their own namespaced values without
+// knowledge of the application's layout. Views share loaded values and caches with c.
+func (c *Config) Scoped(prefix string) *Config {
+	return &Config{s: c.s, prefix: c.prefix + prefix, names: &nameCache{m: map[string]string{}}, values: c.values}
+}
+
+// fullName returns n prefixed with the prefix of c and normalized; see
+// WithNameNormalizer.
+func (c *Config) fullName(n string) string {
+	if c.prefix == "" || c.names == nil {
+		return c.s.normalizeName(c.prefix + n)
+	}
+
+	c.names.mu.RLock()
+	full, ok := c.names.m[n]
+	c.names.mu.RUnlock()
+	if ok {
+		return full
+	}
+
+	full = c.s.normalizeName(c.prefix + n)
+	c.names.mu.Lock()
+	if len(c.names.m) < maxCachedNames {
+		c.names.m[n] = full
+	}
+	c.names.mu.Unlock()
+	return full
+}
+
+// Load loads the configuration into memory. After it has succeeded once, calling it
+// again will have no effect. Use Reload to read the search path again. If it fails, the
+// error is returned by later calls until the retry interval set by WithLoadRetry has
+// passed, at which point the next call tries again.
+func (c *Config) Load() error {
+	return c.load(c.s.loadRetry)
+}
+
+// load implements Load, retrying a failed initial load once retry has passed.
+func (c *Config) load(retry time.Duration) error {
+	s := c.s
+	if s.parent != nil {
+		err := (&Config{s: s.parent}).Load()
+		if err != nil {
+			return err
+		}
+	}
+
+	s.once.Do(func() error {
+		if s.warmStart() {
+			return nil
+		}
+		err := s.reload(context.Background(), "Load")
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		return err
+	}, retry)
+
+	s.mu.RLock()
+	err := s.err
+	s.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("config: encountered while loading config: %w", err)
+	}
+
+	s.switchScheduleOrLog()
+	return nil
+}
+
+// Reload reads the search path again and replaces the loaded configuration values,
+// discarding everything cached from the previous ones. If reading fails, the previous
+// values are kept and the error is returned.
+func (c *Config) Reload() error {
+	return c.reload("Reload")
+}
+
+// Generation returns the number of times the values of c have been loaded, counting the
+// initial load and every successful reload or commit, or 0 if c has not been loaded. It
+// does not load c. Derived state can record the generation it was computed from to
+// cheaply check whether it is stale.
+func (c *Config) Generation() uint64 {
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	return c.s.generation
+}
+
+// LoadedAt returns when the values of c were last loaded, or the zero time if c has not
+// been loaded. It does not load c.
+func (c *Config) LoadedAt() time.Time {
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	return c.s.loadedAt
+}
+
+// reload implements Reload, attributing the resulting ChangeEvent to trigger.
+func (c *Config) reload(trigger string) error {
+	if !c.s.once.Loaded() {
+		return c.load(0)
+	}
+
+	c.s.reloading()
+	err := c.s.reload(context.Background(), trigger)
+	c.s.reloaded(err)
+	if err != nil {
+		return fmt.Errorf("config: encountered while reloading config: %w", err)
+	}
+	return nil
+}
+
+// reload reads the search path with ctx and, if successful, replaces the loaded values of
+// s and reloads the tenants derived from it. Subscribers are told that trigger caused the
+// change.
+func (s *store) reload(ctx context.Context, trigger string) error {
+	st, err := s.stage(ctx)
+	if err != nil {
+		return err
+	}
+	st.committed = true
+	return st.commit(trigger)
+}
+
+// searchPath returns the search path s should be loaded from.
+func (s *store) searchPath() (string, error) {
+	if s.parent != nil {
+		return s.parent.tenantPath(s.tenant)
+	}
+	if s.pathSet {
+		return s.path, nil
+	}
+
+	p := Path()
+	log.Printf("config: %s=%s", EnvVar, p)
+	return p, nil
+}
+
+// loadPath reads every file in the entries of search path p, followed by the files of
+// o.sources, passing ctx to the sources. The Sources of the search path entries are taken
+// from entries, if it is not nil, so that they keep their state, such as HTTP caches,
+// across loads. It returns the entries keyed by file name along with a report of what was
+// read. The report is returned even if loading fails. Files with the same name as one
+// already read replace it if their source has a higher priority, and are skipped if it has
+// a lower one. At the same priority, they are recorded as skipped and logged as warnings
+// if o.shadow is true, and fail the load with a *DuplicateError otherwise.
+func loadPath(ctx context.Context, p string, o *options, entries *entrySources) (map[string]entry, *LoadReport, error) {
+	report := &LoadReport{Path: p, Start: time.Now()}
+	result, err := loadSources(ctx, p, o, entries, report)
+	report.Duration = time.Since(report.Start)
+	report.Err = err
+	if err != nil {
+		return nil, report, err
+	}
+	return result, report, nil
+}
+
+func loadSources(ctx context.Context, p string, o *options, entries *entrySources, report *LoadReport) (map[string]entry, error) {
+	var all []weightedSource
+	var names []string
+	var optional []bool
+	for _, p := range splitPath(p) {
+		p, opt := optionalPath(p)
+		src, err := entries.get(p)
+		if err != nil {
+			return nil, &SourceError{Source: p, Err: err}
+		}
+		if as, ok := src.(authSource); ok && o.auth != nil {
+			as.setAuth(o.auth)
+		}
+		all = append(all, weightedSource{Source: src, priority: o.pathPriority})
+		names = append(names, p)
+		optional = append(optional, opt)
+	}
+	for _, src := range o.sources {
+		all = append(all, src)
+		names = append(names, describeSource(src.Source))
+	}
+
+	result := map[string]entry{}
+	for i, src := range all {
+		report.Sources = append(report.Sources, SourceReport{Source: names[i], Priority: src.priority})
+		sr := &report.Sources[len(report.Sources)-1]
+
+		start := time.Now()
+		fs, err := readSource(ctx, src.Source, o, sr)
+		sr.Duration = time.Since(start)
+		if err != nil && i < len(optional) && optional[i] && errors.Is(err, os.ErrNotExist) {
+			sr.Missing = true
+			continue
+		}
+		sr.Err = err
+		if err != nil {
+			return nil, &SourceError{Source: names[i], Err: err}
+		}
+		o.warnSkipped(sr.Files)
+		if o.normalize != nil {
+			fs = normalizeFiles(fs, o.normalize)
+		}
+		sortFiles(fs)
+
+		for _, f := range fs {
+			e := entry{data: f.Data, path: f.Path, index: i, source: names[i], priority: src.priority}
+			if prev, ok := result[f.Name]; ok {
+				switch {
+				case prev.priority > e.priority:
+					sr.Files = append(sr.Files, FileReport{Name: f.Name, Path: f.Path, Size: len(f.Data), Skipped: "overridden by " + prev.path})
 					continue
+				case prev.priority < e.priority:
+					report.skip(prev, "overridden by "+f.Path)
+				case o.shadow:
+					o.warn(Warning{Kind: WarnShadowed, Name: f.Name, Path: f.Path, Message: fmt.Sprintf("%s shadows %s", prev.path, f.Path)}, true)
+					sr.Files = append(sr.Files, FileReport{Name: f.Name, Path: f.Path, Size: len(f.Data), Skipped: "shadowed by " + prev.path})
+					continue
+				default:
+					return nil, &DuplicateError{
+						Name:    f.Name,
+						Paths:   [2]string{prev.path, f.Path},
+						Indices: [2]int{prev.index, i},
+					}
 				}
-
-				result[b] = d
-				files = append(files, f)
 			}
+
+			result[f.Name] = e
+			sr.Files = append(sr.Files, FileReport{Name: f.Name, Path: f.Path, Size: len(f.Data)})
 		}
-		pkgVal = result
+	}
+	o.warnNearDuplicates(result)
+	return result, nil
+}
 
-		log.Printf("config: files loaded: %v", strings.Join(files, ", "))
+// sortFiles sorts fs by name, then by path, so that sources which return their files in
+// an unspecified order (such as from a map) load reproducibly.
+func sortFiles(fs []File) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		if fs[i].Name != fs[j].Name {
+			return fs[i].Name < fs[j].Name
+		}
+		return fs[i].Path < fs[j].Path
 	})
+}
 
-	if pkgErr != nil {
-		return fmt.Errorf("config: encountered while loading config: %w", pkgErr)
+// readSource returns the files of src, recording the files it skips in sr.
+func readSource(ctx context.Context, src Source, o *options, sr *SourceReport) ([]File, error) {
+	d, ok := src.(dirSource)
+	if !ok {
+		return src.Files(ctx)
 	}
 
-	return nil
+	fs, skipped, err := readDir(string(d), o.maxDepth, o.prune)
+	sr.Files = append(sr.Files, skipped...)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.bootstrap != "" {
+		fs = skipBootstrap(o.bootstrap, fs, sr)
+	}
+
+	if o.manifest != "" {
+		var manifests []File
+		fs, manifests, err = verifyManifest(o.manifest, fs)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range manifests {
+			sr.Files = append(sr.Files, FileReport{Name: m.Name, Path: m.Path, Skipped: "checksum manifest"})
+		}
+	}
+	return fs, nil
+}
+
+// Bytes calls c.Load() then returns the data for the configuration value named n. The
+// result shares its memory with the loaded value, so it must not be modified; see
+// WithMutationPolicy. Once c is loaded, Bytes does not allocate, so it can be called on
+// every request. Views created with Scoped allocate the first time each name is read.
+func (c *Config) Bytes(n string) ([]byte, error) {
+	e, err := c.lookup(n)
+	if err != nil {
+		return nil, err
+	}
+	return e.data, nil
+}
+
+// lookup calls c.Load() then returns the entry for the configuration value named n.
+func (c *Config) lookup(n string) (entry, error) {
+	err := c.Load()
+	if err != nil {
+		return entry{}, fmt.Errorf("config: failed to get value %q because there was a load error: %w", n, err)
+	}
+
+	full := c.fullName(n)
+	if v, ok := c.values[full]; ok {
+		if v.err != nil {
+			return entry{}, fmt.Errorf("config: failed to encode context value %q: %w", n, v.err)
+		}
+		return v.e, nil
+	}
+	if e, ok := c.s.get(full); ok {
+		c.s.warnDeprecated(full)
+		err := c.s.checkMutation(n, e)
+		if err != nil {
+			return entry{}, err
+		}
+		return e, nil
+	}
+
+	return entry{}, c.notFound(n)
+}
+
+// FirstOf calls c.Load() then returns the first of names that is a configuration value, for
+// use with any other accessor. This supports optional overrides:
+//		n, err := c.FirstOf("db.local.json", "db.json")
+//		if err != nil {
+//			return err
+//		}
+//		err = c.InterfaceJson(n, &db)
+//
+// If none of names exist, the error wraps os.ErrNotExist.
+func (c *Config) FirstOf(names ...string) (string, error) {
+	err := c.Load()
+	if err != nil {
+		return "", fmt.Errorf("config: failed to get values %s because there was a load error: %w", strings.Join(names, ", "), err)
+	}
+
+	for _, n := range names {
+		full := c.fullName(n)
+		if _, ok := c.values[full]; ok {
+			return n, nil
+		}
+		if _, ok := c.s.get(full); ok {
+			return n, nil
+		}
+	}
+
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = strconv.Quote(n)
+	}
+	return "", errorf(ErrNotFound, "config: no config entry with any of the names %s: %w", strings.Join(quoted, ", "), os.ErrNotExist)
+}
+
+// get returns the entry named n from s or, if it is not found, from the parents of s.
+func (s *store) get(n string) (entry, bool) {
+	for ; s != nil; s = s.parent {
+		s.mu.RLock()
+		e, ok := s.val[n]
+		s.mu.RUnlock()
+		if ok {
+			s.markAccessed(n)
+			return s.unpack(n, e), true
+		}
+	}
+	return entry{}, false
+}
+
+// names returns the sorted names of the values in s and its parents.
+func (s *store) names() []string {
+	seen := map[string]bool{}
+	var result []string
+	for ; s != nil; s = s.parent {
+		s.mu.RLock()
+		for n := range s.val {
+			if !seen[n] {
+				seen[n] = true
+				result = append(result, n)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	sort.Strings(result)
+	return result
+}
+
+// String calls c.Bytes(n) and converts the result to a string. Surrounding whitespace is
+// removed unless trimming was disabled with WithTrimSpace(false). The string is converted
+// the first time each loaded value is read, so later calls, like Bytes, do not allocate.
+func (c *Config) String(n string) (string, error) {
+	e, err := c.lookup(n)
+	if err != nil {
+		return "", err
+	}
+
+	s := e.text()
+	if c.s.trimSpace {
+		return strings.TrimSpace(s), nil
+	}
+	return s, nil
+}
+
+// Int calls strconv.Atoi(c.String(n)). With WithHumanNumbers, values may also contain
+// thousands separators and unit suffixes, such as "10,000" or "2k".
+func (c *Config) Int(n string) (int, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.s.humanNumbers {
+		r, ok := parseHumanNumber(s)
+		if !ok || !r.IsInt() {
+			return 0, errorf(ErrDecode, "config: failed to unmarshal %s into int: invalid number %q", n, s)
+		}
+		s = r.Num().String()
+	}
+
+	result, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errorf(ErrDecode, "config: failed to unmarshal %s into %T: %w", n, result, err)
+	}
+
+	return result, nil
+}
+
+// IntInRange calls c.Int(n) and checks that the result is between min and max, inclusive.
+func (c *Config) IntInRange(n string, min, max int) (int, error) {
+	result, err := c.Int(n)
+	if err != nil {
+		return 0, err
+	}
+
+	if result < min || result > max {
+		return 0, errorf(ErrValidation, "config: %s is %d, which is outside the allowed range [%d, %d]", n, result, min, max)
+	}
+
+	return result, nil
+}
+
+// Port calls c.IntInRange(n, 1, 65535)
+func (c *Config) Port(n string) (int, error) {
+	return c.IntInRange(n, 1, 65535)
+}
+
+// Decimal calls new(big.Rat).SetString(c.String(n)). The result is exact, so it is suitable
+// for prices, rates and other values that must not be rounded. Values may be written as
+// decimals ("19.99"), in scientific notation ("1.5e-3") or as fractions ("1/3"), and,
+// with WithHumanNumbers, with thousands separators and unit suffixes ("1,299.99", "1.5M").
+func (c *Config) Decimal(n string) (*big.Rat, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.s.humanNumbers {
+		if result, ok := parseHumanNumber(s); ok {
+			return result, nil
+		}
+	}
+
+	result, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errorf(ErrDecode, "config: failed to unmarshal %s into %T: invalid decimal %q", n, result, s)
+	}
+
+	return result, nil
+}
+
+// Percent calls c.String(n) and parses the result as a fraction between 0 and 1, for
+// sampling rates and rollout fractions. Values may be written as percentages ("15%") or as
+// plain numbers. Plain numbers up to 1 are ratios ("0.15"), and greater ones are
+// percentages ("15"); so "1" is 100%, and "1%" must be written with the sign. All of
+// these are returned as 0.15. Values outside 0% to 100% are rejected.
+func (c *Config) Percent(n string) (float64, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return 0, err
+	}
+
+	v := strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	result, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(result) {
+		return 0, errorf(ErrDecode, "config: failed to unmarshal %s into a percentage: invalid number %q", n, s)
+	}
+	if strings.HasSuffix(s, "%") || result > 1 {
+		result /= 100
+	}
+
+	if result < 0 || result > 1 {
+		return 0, errorf(ErrValidation, "config: %s is %s, which is outside the allowed range [0%%, 100%%]", n, s)
+	}
+	return result, nil
 }
 
-// Bytes calls Load() then returns the data for the configuration value named n.
-func Bytes(n string) ([]byte, error) {
-	err := Load()
+// Duration calls time.ParseDuration(c.String(n))
+func (c *Config) Duration(n string) (time.Duration, error) {
+	s, err := c.String(n)
 	if err != nil {
-		return nil, fmt.Errorf("config: failed to get value %q because there was a load error: %w", n, err)
+		return 0, err
 	}
 
-	if v, ok := pkgVal[n]; ok {
-		return v, nil
+	result, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errorf(ErrDecode, "config: failed to unmarshal %s into %T: %w", n, result, err)
 	}
 
-	return nil, os.ErrNotExist
+	return result, nil
+}
+
+// Enum calls c.String(n) and checks that the result is one of allowed. The error lists
+// the allowed values if it is not.
+func (c *Config) Enum(n string, allowed ...string) (string, error) {
+	return c.enum(n, allowed, func(a, b string) bool { return a == b })
 }
 
-// String calls Bytes(n) and converts the result to a string.
-func String(n string) (string, error) {
-	b, err := Bytes(n)
+// EnumFold is like Enum, but compares values case-insensitively. It returns the matching
+// value as written in allowed.
+func (c *Config) EnumFold(n string, allowed ...string) (string, error) {
+	return c.enum(n, allowed, strings.EqualFold)
+}
+
+func (c *Config) enum(n string, allowed []string, equal func(a, b string) bool) (string, error) {
+	s, err := c.String(n)
 	if err != nil {
 		return "", err
 	}
-	return string(b), nil
+
+	for _, a := range allowed {
+		if equal(s, a) {
+			return a, nil
+		}
+	}
+
+	quoted := make([]string, len(allowed))
+	for i, a := range allowed {
+		quoted[i] = strconv.Quote(a)
+	}
+	return "", errorf(ErrValidation, "config: %s is %q, which is not one of the allowed values: %s", n, s, strings.Join(quoted, ", "))
 }
 
 type userinfo struct {
@@ -140,66 +841,275 @@ type userinfo struct {
 //			"username": "string",
 //			"password": "string"
 //		}
-func Userinfo(n string) (*url.Userinfo, error) {
-	b, err := Bytes(n)
+//
+// Parsed values are cached; each call returns a new copy.
+func (c *Config) Userinfo(n string) (*url.Userinfo, error) {
+	e, err := c.lookup(n)
 	if err != nil {
 		return nil, err
 	}
 
-	var ui userinfo
-	err = json.Unmarshal(b, &ui)
+	result, err := c.s.cachedParse("userinfo", n, string(e.data), func(string) (interface{}, error) {
+		var ui userinfo
+		err := json.Unmarshal(e.data, &ui)
+		if err != nil {
+			return nil, newJsonError(n, e, new(url.Userinfo), err)
+		}
+
+		if ui.Password == "" {
+			return url.User(ui.Username), nil
+		}
+
+		return url.UserPassword(ui.Username, ui.Password), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ui := *result.(*url.Userinfo)
+	return &ui, nil
+}
+
+// Url calls url.Parse(c.String(n)). Parsed values are cached; each call returns a new copy.
+func (c *Config) Url(n string) (*url.URL, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.s.cachedParse("url", n, s, func(s string) (interface{}, error) {
+		result, err := url.Parse(s)
+		if err != nil {
+			return nil, errorf(ErrDecode, "config: failed to unmarshal %s into %T: %w", n, new(url.URL), err)
+		}
+		return result, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("config: failed to unmarshal %s into %T: %w", n, new(url.Userinfo), err)
+		return nil, err
 	}
 
-	if ui.Password == "" {
-		return url.User(ui.Username), nil
+	u := *result.(*url.URL)
+	if u.User != nil {
+		ui := *u.User
+		u.User = &ui
+	}
+	return &u, nil
+}
+
+// InterfaceJson calls json.Unmarshal() on c.Bytes(n). Results are cached per value name and
+// type of v, so repeated calls with a pointer to a zero value do not re-parse the data.
+// Values whose types implement encoding.TextUnmarshaler, flag.Value or
+// encoding.BinaryUnmarshaler, but not json.Unmarshaler, are decoded with those methods from
+// the text of any scalar, so a number 42 is passed to UnmarshalText as "42".
+func (c *Config) InterfaceJson(n string, v interface{}) error {
+	e, err := c.lookup(n)
+	if err != nil {
+		return err
 	}
 
-	return url.UserPassword(ui.Username, ui.Password), nil
+	return c.cachedDecode("json", n, v, func(v interface{}) error {
+		if isTextTarget(v, "json") {
+			return c.decodeJsonText(n, e, v)
+		}
+		if c.s.conditions != nil {
+			data, err := filterJson(e.data, c.s.conditions)
+			if err != nil {
+				return newJsonError(n, e, v, err)
+			}
+			// Offsets into the filtered document do not correspond to the file.
+			err = json.Unmarshal(data, v)
+			if err != nil {
+				return newDecodeError(n, e, v, 0, 0, err)
+			}
+			return nil
+		}
+
+		err := json.Unmarshal(e.data, v)
+		if err != nil {
+			return newJsonError(n, e, v, err)
+		}
+		return nil
+	})
 }
 
-// Url calls url.Parse(String(n))
-func Url(n string) (*url.URL, error) {
-	s, err := String(n)
+// RawJson returns the sub-document of configuration value n found at path, without
+// decoding it. The path is a dot-separated list of object keys and array indices
+// (e.g. "servers.0.tls"); an empty path returns the whole document. If there is no
+// value at path, the returned error wraps os.ErrNotExist.
+func (c *Config) RawJson(n string, path string) (json.RawMessage, error) {
+	e, err := c.lookup(n)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := url.Parse(s)
+	var result json.RawMessage
+	err = json.Unmarshal(e.data, &result)
 	if err != nil {
-		return nil, fmt.Errorf("config: failed to unmarshal %s into %T: %w", n, new(url.URL), err)
+		return nil, newJsonError(n, e, result, err)
+	}
+
+	if path == "" {
+		return result, nil
+	}
+
+	for _, k := range strings.Split(path, ".") {
+		switch bytes.TrimLeft(result, " \t\r\n")[0] {
+		case '{':
+			var obj map[string]json.RawMessage
+			err = json.Unmarshal(result, &obj)
+			if err != nil {
+				return nil, newJsonError(n, e, obj, err)
+			}
+			v, ok := obj[k]
+			if !ok {
+				return nil, errorf(ErrNotFound, "config: %s has no value at %q: %w", n, path, os.ErrNotExist)
+			}
+			result = v
+		case '[':
+			var arr []json.RawMessage
+			err = json.Unmarshal(result, &arr)
+			if err != nil {
+				return nil, newJsonError(n, e, arr, err)
+			}
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(arr) {
+				return nil, errorf(ErrNotFound, "config: %s has no value at %q: %w", n, path, os.ErrNotExist)
+			}
+			result = arr[i]
+		default:
+			return nil, errorf(ErrNotFound, "config: %s has no value at %q: %w", n, path, os.ErrNotExist)
+		}
 	}
 
 	return result, nil
 }
 
-// InterfaceJson calls json.Unmarshal() on Bytes(n)
-func InterfaceJson(n string, v interface{}) error {
-	b, err := Bytes(n)
+// JSONLines parses configuration value n as JSON Lines (newline-delimited JSON) and calls fn
+// with each record in order, without decoding them all at once. Blank lines are skipped.
+// If a record is not valid JSON, a *DecodeError locating it is returned. If fn returns an
+// error, iteration stops and the error is returned unchanged.
+func (c *Config) JSONLines(n string, fn func(json.RawMessage) error) error {
+	e, err := c.lookup(n)
 	if err != nil {
 		return err
 	}
 
-	err = json.Unmarshal(b, v)
-	if err != nil {
-		return fmt.Errorf("config: failed to unmarshal %s into %T: %w", n, v, err)
-	}
+	data := e.data
+	for line := 1; len(data) > 0; line++ {
+		l := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			l, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+
+		l = bytes.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+		if !json.Valid(l) {
+			var v interface{}
+			err := json.Unmarshal(l, &v)
+			return newDecodeError(n, e, json.RawMessage(nil), line, 0, err)
+		}
 
+		err := fn(json.RawMessage(l))
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// InterfaceYaml calls yaml.Unmarshal() on Bytes(n)
-func InterfaceYaml(n string, v interface{}) error {
-	b, err := Bytes(n)
+// InterfaceYaml calls yaml.Unmarshal() on c.Bytes(n). Results are cached per value name and
+// type of v, so repeated calls with a pointer to a zero value do not re-parse the data.
+// Values whose types implement encoding.TextUnmarshaler, flag.Value or
+// encoding.BinaryUnmarshaler, but not yaml.Unmarshaler, are decoded with those methods
+// from the text of any scalar, as InterfaceJson does, except for time.Time.
+func (c *Config) InterfaceYaml(n string, v interface{}) error {
+	e, err := c.lookup(n)
 	if err != nil {
 		return err
 	}
 
-	err = yaml.Unmarshal(b, v)
+	return c.cachedDecode("yaml", n, v, func(v interface{}) error {
+		if isTextTarget(v, "yaml") {
+			return c.decodeYamlText(n, e, v)
+		}
+		if _, ok := c.anchors(n); !ok && c.s.conditions == nil {
+			err := yaml.Unmarshal(e.data, v)
+			if err != nil {
+				return newYamlError(n, e, v, err)
+			}
+			return nil
+		}
+
+		node, err := c.yamlNode(n, e, v)
+		if err != nil {
+			return err
+		}
+		err = node.Decode(v)
+		if err != nil {
+			return newYamlError(n, e, v, err)
+		}
+		return nil
+	})
+}
+
+// YamlNode calls yaml.Unmarshal() on c.Bytes(n) and returns the resulting document node.
+// The node preserves comments and line numbers and can be decoded piecemeal with
+// (*yaml.Node).Decode().
+func (c *Config) YamlNode(n string) (*yaml.Node, error) {
+	e, err := c.lookup(n)
 	if err != nil {
-		return fmt.Errorf("config: failed to unmarshal %s into %T: %w", n, v, err)
+		return nil, err
 	}
 
-	return nil
+	result, err := c.yamlNode(n, e, &yaml.Node{})
+	c.s.recordDecode(c.fullName(n), err)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// yamlNode parses e, the entry for configuration value n, with the shared anchors if they
+// are enabled, and resolves its conditional blocks if conditions are enabled. Errors
+// report target as the type being decoded into.
+func (c *Config) yamlNode(n string, e entry, target interface{}) (*yaml.Node, error) {
+	result := &yaml.Node{}
+	if a, ok := c.anchors(n); ok {
+		var err error
+		result, err = parseAnchored(n, c.s.anchors, a.data, e.data)
+		var ae *AnchorError
+		if errors.As(err, &ae) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, newYamlError(n, e, target, err)
+		}
+	} else {
+		err := yaml.Unmarshal(e.data, result)
+		if err != nil {
+			return nil, newYamlError(n, e, target, err)
+		}
+	}
+
+	if c.s.conditions != nil {
+		_, err := filterYaml(result, c.s.conditions)
+		if err != nil {
+			return nil, newYamlError(n, e, target, err)
+		}
+	}
+	return result, nil
+}
+
+// anchors returns the entry holding the shared anchors available to configuration value
+// n, if shared anchors are enabled and the entry exists.
+func (c *Config) anchors(n string) (entry, bool) {
+	if c.s.anchors == "" || c.fullName(n) == c.s.anchors {
+		return entry{}, false
+	}
+	return c.s.get(c.s.anchors)
 }
@@ -1,12 +1,21 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestMain(t *testing.M) {
@@ -185,6 +194,16 @@ func TestUrl(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"1/trailing_url",
+			args{"trailing_url"},
+			&url.URL{
+				Scheme: "https",
+				Host:   "example.com",
+				Path:   "/path",
+			},
+			false,
+		},
 		{
 			"2/db_url",
 			args{"db_url"},
@@ -214,3 +233,860 @@ func TestUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestString_TrimSpace(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      args
+		trimSpace bool
+		want      string
+	}{
+		{
+			"1/port",
+			args{"port"},
+			true,
+			"8080",
+		},
+		{
+			"1/port untrimmed",
+			args{"port"},
+			false,
+			"8080\n",
+		},
+		{
+			"2/timeout",
+			args{"timeout"},
+			true,
+			"1m30s",
+		},
+		{
+			"2/timeout untrimmed",
+			args{"timeout"},
+			false,
+			" 1m30s\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(WithTrimSpace(tt.trimSpace)).String(tt.args.n)
+			if err != nil {
+				t.Errorf("String() error = %v, wantErr %v", err, false)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("String() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    args
+		want    int
+		wantErr bool
+	}{
+		{
+			"1/bytes",
+			args{"bytes"},
+			1234567890,
+			false,
+		},
+		{
+			"1/port",
+			args{"port"},
+			8080,
+			false,
+		},
+		{
+			"2/string",
+			args{"string"},
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Int(tt.args.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Int() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Int() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntInRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        string
+		min, max int
+		want     int
+		wantErr  string
+	}{
+		{"in range", "port", 1024, 49151, 8080, ""},
+		{"at bounds", "port", 8080, 8080, 8080, ""},
+		{"above max", "port", 1, 1023, 0, "[1, 1023]"},
+		{"below min", "port", 10000, 20000, 0, "[10000, 20000]"},
+		{"not an int", "string", 0, 10, 0, "failed to unmarshal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IntInRange(tt.n, tt.min, tt.max)
+			if (err != nil) != (tt.wantErr != "") || err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("IntInRange() error = %v, want %q", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("IntInRange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       string
+		want    int
+		wantErr bool
+	}{
+		{"1/port", "port", 8080, false},
+		{"1/bytes", "bytes", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Port(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Port() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Port() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       string
+		want    string
+		wantErr bool
+	}{
+		{"1/price", "price", "1999/100", false},
+		{"1/port", "port", "8080/1", false},
+		{"2/string", "string", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decimal(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decimal() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Decimal() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"percent":   "15%",
+		"ratio":     "0.15",
+		"plain":     "15",
+		"one":       "1",
+		"small":     "0.5%",
+		"zero":      "0",
+		"full":      "100%",
+		"negative":  "-5%",
+		"over":      "150",
+		"overratio": "101%",
+		"nan":       "NaN",
+		"text":      "half",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    float64
+		wantErr bool
+	}{
+		{name: "percent", want: 0.15},
+		{name: "ratio", want: 0.15},
+		{name: "plain", want: 0.15},
+		{name: "one", want: 1},
+		{name: "small", want: 0.005},
+		{name: "zero", want: 0},
+		{name: "full", want: 1},
+		{name: "negative", wantErr: true},
+		{name: "over", wantErr: true},
+		{name: "overratio", wantErr: true},
+		{name: "nan", wantErr: true},
+		{name: "text", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Percent(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Percent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Percent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    args
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			"2/timeout",
+			args{"timeout"},
+			90 * time.Second,
+			false,
+		},
+		{
+			"1/port",
+			args{"port"},
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Duration(tt.args.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Duration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Duration() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnum(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		fold    bool
+		want    string
+		wantErr bool
+	}{
+		{"exact", []string{"Lenient", "Strict"}, false, "Strict", false},
+		{"case mismatch", []string{"lenient", "strict"}, false, "", true},
+		{"fold", []string{"lenient", "strict"}, true, "strict", false},
+		{"not allowed", []string{"off", "on"}, true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enum := Enum
+			if tt.fold {
+				enum = EnumFold
+			}
+			got, err := enum("mode", tt.allowed...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Enum() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil && !strings.Contains(err.Error(), strconv.Quote(tt.allowed[1])) {
+				t.Errorf("Enum() error = %v, want it to list %q", err, tt.allowed[1])
+			}
+			if got != tt.want {
+				t.Errorf("Enum() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"rules.jsonl": "{\"id\": 1}\n\n{\"id\": 2}\r\n[3]\n",
+		"bad.jsonl":   "{\"id\": 1}\n{\"id\": \n{\"id\": 3}\n",
+	})
+	c := New(WithPath(dir))
+
+	var got []string
+	err := c.JSONLines("rules.jsonl", func(r json.RawMessage) error {
+		got = append(got, string(r))
+		return nil
+	})
+	if want := []string{`{"id": 1}`, `{"id": 2}`, `[3]`}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("JSONLines() = %q, %v, want %q", got, err, want)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = c.JSONLines("rules.jsonl", func(json.RawMessage) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("JSONLines() error = %v after %d calls, want %v after 1", err, calls, stop)
+	}
+
+	var de *DecodeError
+	err = c.JSONLines("bad.jsonl", func(json.RawMessage) error { return nil })
+	if !errors.As(err, &de) || de.Line != 2 {
+		t.Errorf("JSONLines() error = %v, want *DecodeError on line 2", err)
+	}
+}
+
+func TestInterfaceYaml(t *testing.T) {
+	type server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+	type app struct {
+		Server   server   `yaml:"server"`
+		Features []string `yaml:"features"`
+	}
+
+	var got app
+	err := InterfaceYaml("app.yaml", &got)
+	if err != nil {
+		t.Fatalf("InterfaceYaml() error = %v, wantErr %v", err, false)
+	}
+
+	want := app{
+		Server:   server{Host: "localhost", Port: 8080},
+		Features: []string{"alpha", "beta"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InterfaceYaml() got = %#v, want %#v", got, want)
+	}
+}
+
+func TestYamlNode(t *testing.T) {
+	got, err := YamlNode("app.yaml")
+	if err != nil {
+		t.Fatalf("YamlNode() error = %v, wantErr %v", err, false)
+	}
+
+	if got.Kind != yaml.DocumentNode || len(got.Content) != 1 {
+		t.Fatalf("YamlNode() got kind = %v with %d children, want document with 1 child", got.Kind, len(got.Content))
+	}
+
+	root := got.Content[0]
+	if c := root.Content[0].HeadComment; c != "# application settings" {
+		t.Errorf("YamlNode() head comment = %q, want %q", c, "# application settings")
+	}
+
+	server := root.Content[1]
+	if host := server.Content[1]; host.Value != "localhost" || host.Line != 3 || host.LineComment != "# bind address" {
+		t.Errorf("YamlNode() host = %q (line %d, comment %q), want %q (line 3, comment %q)", host.Value, host.Line, host.LineComment, "localhost", "# bind address")
+	}
+
+	var port int
+	if err := server.Content[3].Decode(&port); err != nil || port != 8080 {
+		t.Errorf("YamlNode() port = %v (err %v), want %v", port, err, 8080)
+	}
+
+	if _, err := YamlNode("missing"); err == nil {
+		t.Errorf("YamlNode() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestRawJson(t *testing.T) {
+	type args struct {
+		n    string
+		path string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			"2/services.json",
+			args{"services.json", "search.weights"},
+			`{"title":  2.5}`,
+			nil,
+		},
+		{
+			"2/services.json array index",
+			args{"services.json", "billing.retries.1"},
+			`2`,
+			nil,
+		},
+		{
+			"2/services.json missing key",
+			args{"services.json", "billing.timeout"},
+			"",
+			os.ErrNotExist,
+		},
+		{
+			"2/services.json index out of range",
+			args{"services.json", "billing.retries.3"},
+			"",
+			os.ErrNotExist,
+		},
+		{
+			"2/services.json through scalar",
+			args{"services.json", "billing.url.scheme"},
+			"",
+			os.ErrNotExist,
+		},
+		{
+			"1/user.json whole document",
+			args{"user.json", ""},
+			`{"username": "user"}`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RawJson(tt.args.n, tt.args.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RawJson() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("RawJson() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := RawJson("string", "a"); err == nil {
+		t.Errorf("RawJson() error = %v, wantErr %v", err, true)
+	}
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	for name, data := range files {
+		f := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(f, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func Test_loadPath_duplicate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/name":  "a",
+		"b/other": "b",
+		"c/name":  "c",
+	})
+	p := strings.Join([]string{
+		filepath.Join(dir, "a"),
+		filepath.Join(dir, "b"),
+		filepath.Join(dir, "c"),
+	}, string(os.PathListSeparator))
+
+	_, _, err := loadPath(context.Background(), p, &options{}, nil)
+	var de *DuplicateError
+	if !errors.As(err, &de) {
+		t.Fatalf("loadPath() error = %v, want %T", err, de)
+	}
+
+	want := &DuplicateError{
+		Name:    "name",
+		Paths:   [2]string{filepath.Join(dir, "a", "name"), filepath.Join(dir, "c", "name")},
+		Indices: [2]int{0, 2},
+	}
+	if !reflect.DeepEqual(de, want) {
+		t.Errorf("loadPath() error = %#v, want %#v", de, want)
+	}
+	for _, s := range want.Paths {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("Error() = %q, want it to contain %q", err.Error(), s)
+		}
+	}
+}
+
+func Test_loadPath_shadow(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"override/name": "local",
+		"base/name":     "production",
+		"base/other":    "other",
+	})
+	p := strings.Join([]string{
+		filepath.Join(dir, "override"),
+		filepath.Join(dir, "base"),
+	}, string(os.PathListSeparator))
+
+	got, report, err := loadPath(context.Background(), p, &options{shadow: true}, nil)
+	if err != nil {
+		t.Fatalf("loadPath() error = %v", err)
+	}
+	if e := got["name"]; string(e.data) != "local" || e.index != 0 {
+		t.Errorf("loadPath() name = %q (path entry %d), want %q (path entry %d)", e.data, e.index, "local", 0)
+	}
+	if e := got["other"]; string(e.data) != "other" || e.index != 1 {
+		t.Errorf("loadPath() other = %q (path entry %d), want %q (path entry %d)", e.data, e.index, "other", 1)
+	}
+	if files := report.loaded(); len(files) != 2 {
+		t.Errorf("loadPath() files = %v, want 2 files", files)
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/name": "a\n",
+		"b/name": "b",
+	})
+
+	c := New(WithPath(filepath.Join(dir, "a")))
+	if got, err := c.String("name"); err != nil || got != "a" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "a")
+	}
+	if _, err := c.Bytes("bytes"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Bytes() error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	p := strings.Join([]string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, string(os.PathListSeparator))
+	if err := New(WithPath(p)).Load(); err == nil {
+		t.Errorf("Load() error = %v, wantErr %v", err, true)
+	}
+	if got, err := New(WithPath(p), WithShadow(true)).String("name"); err != nil || got != "a" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "a")
+	}
+}
+
+func TestConfig_Scoped(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"billing.url":     "http://billing",
+		"billing.retries": "3",
+		"url":             "http://app",
+	})
+	c := New(WithPath(dir))
+
+	billing := c.Scoped("billing.")
+	if got, err := billing.Url("url"); err != nil || got.String() != "http://billing" {
+		t.Errorf("Url() = %v, %v, want %v", got, err, "http://billing")
+	}
+	if got, err := billing.Int("retries"); err != nil || got != 3 {
+		t.Errorf("Int() = %v, %v, want %v", got, err, 3)
+	}
+	if _, err := billing.Scoped("x.").Bytes("url"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Bytes() error = %v, want %v", err, os.ErrNotExist)
+	}
+	if got, err := c.Url("url"); err != nil || got.String() != "http://app" {
+		t.Errorf("Url() = %v, %v, want %v", got, err, "http://app")
+	}
+}
+
+func TestConfig_Reload(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"url": "http://before",
+	})
+	c := New(WithPath(dir))
+
+	if got, err := c.Url("url"); err != nil || got.String() != "http://before" {
+		t.Fatalf("Url() = %v, %v, want %v", got, err, "http://before")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "url"), []byte("http://after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Url("url"); err != nil || got.String() != "http://before" {
+		t.Errorf("Url() before Reload() = %v, %v, want %v", got, err, "http://before")
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, err := c.Url("url"); err != nil || got.String() != "http://after" {
+		t.Errorf("Url() after Reload() = %v, %v, want %v", got, err, "http://after")
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() error = %v, wantErr %v", err, true)
+	}
+	if got, err := c.Url("url"); err != nil || got.String() != "http://after" {
+		t.Errorf("Url() after failed Reload() = %v, %v, want %v", got, err, "http://after")
+	}
+}
+
+func TestConfig_Generation(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"name": "a",
+	})
+	c := New(WithPath(dir))
+	if got := c.Generation(); got != 0 || !c.LoadedAt().IsZero() {
+		t.Errorf("Generation() = %d, LoadedAt() = %v before Load(), want 0 and the zero time", got, c.LoadedAt())
+	}
+
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := c.Generation(); got != 1 {
+		t.Errorf("Generation() = %d, want %d", got, 1)
+	}
+	loaded := c.LoadedAt()
+	if loaded.IsZero() {
+		t.Errorf("LoadedAt() = %v, want a load time", loaded)
+	}
+
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := c.Scoped("x.").Generation(); got != 2 {
+		t.Errorf("Generation() = %d, want %d", got, 2)
+	}
+	if c.LoadedAt().Before(loaded) {
+		t.Errorf("LoadedAt() = %v, want at least %v", c.LoadedAt(), loaded)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err == nil {
+		t.Fatalf("Reload() error = %v, wantErr %v", err, true)
+	}
+	if got := c.Generation(); got != 2 {
+		t.Errorf("Generation() after failed Reload() = %d, want %d", got, 2)
+	}
+}
+
+func Test_loadPath_order(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/c": "c",
+		"a/a": "a",
+		"a/b": "b",
+	})
+	src := staticSource{"z": "z", "y": "y", "x": "x", "w": "w"}
+
+	for i := 0; i < 10; i++ {
+		_, report, err := loadPath(context.Background(), dir+string(os.PathListSeparator)+filepath.Join(dir, "a"), &options{sources: []weightedSource{{Source: src}}}, nil)
+		if err != nil {
+			t.Fatalf("loadPath() error = %v", err)
+		}
+
+		got := report.loaded()
+		want := []string{
+			filepath.Join(dir, "a", "a"), filepath.Join(dir, "a", "b"), filepath.Join(dir, "a", "c"),
+			"static:w", "static:x", "static:y", "static:z",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("loadPath() files = %v, want %v", got, want)
+		}
+	}
+}
+
+func Test_loadPath_duplicateInSource(t *testing.T) {
+	src := duplicateSource{
+		{Name: "name", Path: "remote:2"},
+		{Name: "name", Path: "remote:1"},
+	}
+
+	_, _, err := loadPath(context.Background(), "", &options{sources: []weightedSource{{Source: src}}}, nil)
+	var de *DuplicateError
+	if !errors.As(err, &de) {
+		t.Fatalf("loadPath() error = %v, want %T", err, de)
+	}
+	if want := [2]string{"remote:1", "remote:2"}; de.Paths != want {
+		t.Errorf("loadPath() error paths = %q, want %q", de.Paths, want)
+	}
+}
+
+// duplicateSource is a Source that provides a fixed list of files.
+type duplicateSource []File
+
+func (s duplicateSource) Files(context.Context) ([]File, error) {
+	return append([]File(nil), s...), nil
+}
+
+func Test_loadPath_file(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/name":          "a",
+		"secrets/db.yaml": "user: admin",
+	})
+	p := strings.Join([]string{
+		filepath.Join(dir, "a"),
+		filepath.Join(dir, "secrets", "db.yaml"),
+	}, string(os.PathListSeparator))
+
+	got, _, err := loadPath(context.Background(), p, &options{}, nil)
+	if err != nil {
+		t.Fatalf("loadPath() error = %v", err)
+	}
+	e, ok := got["db.yaml"]
+	if !ok || string(e.data) != "user: admin" || e.path != filepath.Join(dir, "secrets", "db.yaml") || e.index != 1 {
+		t.Errorf("loadPath() db.yaml = %q from %q (path entry %d), want %q", e.data, e.path, e.index, "user: admin")
+	}
+	if _, ok := got["name"]; !ok {
+		t.Errorf("loadPath() = %v, want name", got)
+	}
+}
+
+func Test_loadPath_optional(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base/name": "production",
+	})
+	missing := filepath.Join(dir, "local")
+
+	tests := []struct {
+		name    string
+		p       string
+		wantErr bool
+	}{
+		{name: "optional", p: missing + "?" + string(os.PathListSeparator) + filepath.Join(dir, "base")},
+		{name: "required", p: missing + string(os.PathListSeparator) + filepath.Join(dir, "base"), wantErr: true},
+		{name: "optional exists", p: filepath.Join(dir, "base") + "?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report, err := loadPath(context.Background(), tt.p, &options{}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(got["name"].data) != "production" {
+				t.Errorf("loadPath() name = %q, want %q", got["name"].data, "production")
+			}
+			wantMissing := strings.HasPrefix(tt.p, missing)
+			if report.Sources[0].Missing != wantMissing || report.Sources[0].Source == tt.p {
+				t.Errorf("loadPath() report = %+v, want Missing %v", report.Sources[0], wantMissing)
+			}
+		})
+	}
+}
+
+func TestFirstOf(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db.json":       `{"host": "db"}`,
+		"db.local.json": `{"host": "localhost"}`,
+		"cache.json":    `{"size": 1}`,
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		names   []string
+		want    string
+		wantErr bool
+	}{
+		{names: []string{"db.local.json", "db.json"}, want: "db.local.json"},
+		{names: []string{"cache.local.json", "cache.json"}, want: "cache.json"},
+		{names: []string{"queue.local.json", "queue.json"}, wantErr: true},
+		{names: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.names, ","), func(t *testing.T) {
+			got, err := c.FirstOf(tt.names...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FirstOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("FirstOf() error = %v, want os.ErrNotExist", err)
+			}
+			if got != tt.want {
+				t.Errorf("FirstOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := c.Scoped("db.").FirstOf("local.json", "json"); err != nil || got != "local.json" {
+		t.Errorf("Scoped().FirstOf() = %q, %v, want %q", got, err, "local.json")
+	}
+}
+
+func TestConfig_readAllocs(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": " value\n", "billing/url": "https://billing"})
+	c := New(WithPath(dir), WithMaxDepth(1))
+	scoped := c.Scoped("billing/")
+	if _, err := scoped.String("url"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		read func()
+	}{
+		{name: "Bytes", read: func() { _, _ = c.Bytes("name") }},
+		{name: "String", read: func() { _, _ = c.String("name") }},
+		{name: "Scoped", read: func() { _, _ = scoped.String("url") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(100, tt.read); got != 0 {
+				t.Errorf("%s allocates %v times per read, want 0", tt.name, got)
+			}
+		})
+	}
+}
+
+func BenchmarkConfig_Bytes(b *testing.B) {
+	c := benchmarkConfig(b)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = c.Bytes("name")
+		}
+	})
+}
+
+func BenchmarkConfig_String(b *testing.B) {
+	c := benchmarkConfig(b)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = c.String("name")
+		}
+	})
+}
+
+func BenchmarkConfig_Scoped(b *testing.B) {
+	c := benchmarkConfig(b).Scoped("billing/")
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = c.String("url")
+		}
+	})
+}
+
+// benchmarkConfig returns a loaded Config with the values read by the benchmarks.
+func benchmarkConfig(b *testing.B) *Config {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = os.RemoveAll(dir) })
+	for n, d := range map[string]string{"name": "value", "billing/url": "https://billing"} {
+		f := filepath.Join(dir, filepath.FromSlash(n))
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := ioutil.WriteFile(f, []byte(d), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	c := New(WithPath(dir), WithMaxDepth(1))
+	if err := c.Load(); err != nil {
+		b.Fatal(err)
+	}
+	return c
+}
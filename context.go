@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"time"
+)
+
+// contextKey is the key of the *Config stored in a context by NewContext.
+type contextKey struct{}
+
+// contextValuesKey is the key of the overrides stored in a context by WithValues.
+type contextValuesKey struct{}
+
+// contextValues maps the full names of the values overridden with WithValues to them.
+type contextValues map[string]contextValue
+
+// contextValue is a value added with WithValues, or the error encoding it.
+type contextValue struct {
+	e   entry
+	err error
+}
+
+// NewContext returns a copy of ctx that carries c, so request-scoped code and libraries
+// can read the Config that applies to the request, such as the view returned by ForTenant
+// for the request's tenant, with FromContext.
+func NewContext(ctx context.Context, c *Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the Config carried by ctx, if any, with the values added to ctx with
+// WithValues layered over it. See NewContext.
+func FromContext(ctx context.Context) (*Config, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Config)
+	if !ok || c == nil {
+		return nil, false
+	}
+	return c.WithContext(ctx), true
+}
+
+// WithValues returns a copy of ctx that carries vals as overrides of the configuration
+// values with the same names. They are only visible through views returned by WithContext
+// or FromContext for the returned context or its children, so experiment parameters and
+// per-request debug toggles can be set without changing what other requests see:
+//
+//		ctx = config.WithValues(ctx, map[string]interface{}{"checkout/variant": "b"})
+//		variant, err := c.WithContext(ctx).String("checkout/variant")
+//
+// Names are not prefixed by Scoped views. Values of type []byte and string are used as
+// is, time.Duration and encoding.TextMarshaler values are converted to text, and any
+// other value is encoded as JSON. Calling WithValues on a context that already carries
+// overrides layers vals over them. Overrides are not validated and are not seen by
+// OnChange subscribers, Dump or Export.
+func WithValues(ctx context.Context, vals map[string]interface{}) context.Context {
+	parent, _ := ctx.Value(contextValuesKey{}).(contextValues)
+	layered := make(contextValues, len(parent)+len(vals))
+	for n, v := range parent {
+		layered[n] = v
+	}
+	for n, v := range vals {
+		layered[n] = newContextValue(v)
+	}
+	return context.WithValue(ctx, contextValuesKey{}, layered)
+}
+
+// newContextValue converts v, a value passed to WithValues, to a contextValue.
+func newContextValue(v interface{}) contextValue {
+	var data []byte
+	var err error
+	switch v := v.(type) {
+	case []byte:
+		data = append([]byte(nil), v...)
+	case string:
+		data = []byte(v)
+	case time.Duration:
+		data = []byte(v.String())
+	case encoding.TextMarshaler:
+		data, err = v.MarshalText()
+	default:
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return contextValue{err: err}
+	}
+	return contextValue{e: entry{data: data, str: &lazyString{}, source: "context"}}
+}
+
+// WithContext returns a view of c in which the values added to ctx with WithValues
+// replace those with the same names. The overrides of ctx replace any that c was
+// returned with. Views share loaded values and caches with c; if ctx carries no overrides
+// and c has none, c is returned.
+func (c *Config) WithContext(ctx context.Context) *Config {
+	vals, _ := ctx.Value(contextValuesKey{}).(contextValues)
+	if len(vals) == 0 && c.values == nil {
+		return c
+	}
+	if c.s.normalize != nil {
+		normalized := make(contextValues, len(vals))
+		for n, v := range vals {
+			normalized[c.s.normalizeName(n)] = v
+		}
+		vals = normalized
+	}
+	return &Config{s: c.s, prefix: c.prefix, names: c.names, values: vals}
+}
+
+// cachedDecode decodes value n of c into v with decode, through the decode cache of the
+// store unless n is overridden by the context of c, since the cache is shared by every
+// view of the store.
+func (c *Config) cachedDecode(format, n string, v interface{}, decode func(v interface{}) error) error {
+	full := c.fullName(n)
+	if _, ok := c.values[full]; ok {
+		return decode(v)
+	}
+	return c.s.cachedDecode(format, full, v, decode)
+}
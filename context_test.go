@@ -0,0 +1,122 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestFromContext(t *testing.T) {
+	if c, ok := FromContext(context.Background()); ok || c != nil {
+		t.Errorf("FromContext() = %v, %v, want nil, false", c, ok)
+	}
+
+	dir := writeFiles(t, map[string]string{"name": "shared", "tenants/acme/name": "acme"})
+	c := New(WithPath(dir))
+	ctx := NewContext(context.Background(), c.ForTenant("acme"))
+
+	got, ok := FromContext(ctx)
+	if !ok {
+		t.Fatal("FromContext() ok = false, want true")
+	}
+	if s, err := got.String("name"); err != nil || s != "acme" {
+		t.Errorf("String() = %q, %v, want %q", s, err, "acme")
+	}
+}
+
+func TestWithValues(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"checkout.variant": "a", "checkout.timeout": "5s", "debug": "false"})
+	c := New(WithPath(dir))
+
+	ctx := WithValues(context.Background(), map[string]interface{}{
+		"checkout.variant": "b",
+		"checkout.timeout": 2 * time.Second,
+		"limits.json":      map[string]int{"rps": 10},
+		"bad.json":         make(chan int),
+	})
+	ctx = WithValues(ctx, map[string]interface{}{"debug": true})
+
+	tests := []struct {
+		name    string
+		c       *Config
+		n       string
+		want    string
+		wantErr bool
+	}{
+		{name: "shared", c: c, n: "checkout.variant", want: "a"},
+		{name: "string", c: c.WithContext(ctx), n: "checkout.variant", want: "b"},
+		{name: "duration", c: c.WithContext(ctx), n: "checkout.timeout", want: "2s"},
+		{name: "layered", c: c.WithContext(ctx), n: "debug", want: "true"},
+		{name: "json", c: c.WithContext(ctx), n: "limits.json", want: `{"rps":10}`},
+		{name: "scoped", c: c.WithContext(ctx).Scoped("checkout."), n: "variant", want: "b"},
+		{name: "tenant", c: c.WithContext(ctx).ForTenant("acme"), n: "checkout.variant", want: "b"},
+		{name: "unencodable", c: c.WithContext(ctx), n: "bad.json", wantErr: true},
+		{name: "other context", c: c.WithContext(context.Background()), n: "debug", want: "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.c.String(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("String() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("String() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if d, err := c.WithContext(ctx).Duration("checkout.timeout"); err != nil || d != 2*time.Second {
+		t.Errorf("Duration() = %v, %v, want 2s", d, err)
+	}
+	if o, err := c.WithContext(ctx).Origin("debug"); err != nil || o.Source != "context" {
+		t.Errorf("Origin() = %+v, %v, want source context", o, err)
+	}
+	got, ok := FromContext(NewContext(ctx, c))
+	if !ok {
+		t.Fatal("FromContext() ok = false, want true")
+	}
+	if n, err := got.FirstOf("missing", "limits.json"); err != nil || n != "limits.json" {
+		t.Errorf("FirstOf() = %q, %v, want limits.json", n, err)
+	}
+}
+
+func TestWithValues_decodeCache(t *testing.T) {
+	for _, baseFirst := range []bool{true, false} {
+		t.Run(fmt.Sprintf("base first %v", baseFirst), func(t *testing.T) {
+			c := New(WithPath(writeFiles(t, map[string]string{
+				"user.json": `{"username": "user"}`,
+				"user.yaml": "username: user\n",
+			})))
+			ctx := WithValues(context.Background(), map[string]interface{}{
+				"user.json": map[string]string{"username": "override"},
+				"user.yaml": "username: override\n",
+			})
+			views := []struct {
+				c    *Config
+				want string
+			}{
+				{c: c, want: "user"},
+				{c: c.WithContext(ctx), want: "override"},
+			}
+			if !baseFirst {
+				views[0], views[1] = views[1], views[0]
+			}
+
+			for _, n := range []string{"user.json", "user.yaml"} {
+				for _, v := range views {
+					var got map[string]string
+					var err error
+					if n == "user.json" {
+						err = v.c.InterfaceJson(n, &got)
+					} else {
+						err = v.c.InterfaceYaml(n, &got)
+					}
+					if err != nil || got["username"] != v.want {
+						t.Errorf("decode(%q) = %v, %v, want username %q", n, got, err, v.want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCORSMethods are the methods allowed by a CORSPolicy that does not list any.
+var DefaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// CORSPolicy is a cross-origin resource sharing policy, parsed by Config.CORS.
+type CORSPolicy struct {
+	AllowedOrigins   []string      // AllowedOrigins are the allowed origins, or "*" for any.
+	AllowedMethods   []string      // AllowedMethods are the allowed methods, in upper case.
+	AllowedHeaders   []string      // AllowedHeaders are the allowed request headers, in canonical form.
+	ExposedHeaders   []string      // ExposedHeaders are the response headers exposed to scripts.
+	AllowCredentials bool          // AllowCredentials allows requests with credentials.
+	MaxAge           time.Duration // MaxAge is how long preflight results may be cached.
+}
+
+// AllowsOrigin reports whether p allows requests from origin.
+func (p CORSPolicy) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, o := range p.AllowedOrigins {
+		switch {
+		case o == "*" || o == origin:
+			return true
+		case strings.Contains(o, "://*."):
+			i := strings.Index(o, "*")
+			if strings.HasPrefix(origin, o[:i]) && strings.HasSuffix(origin, o[i+1:]) && len(origin) > len(o)-1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type corsPolicy struct {
+	AllowedOrigins   []string `yaml:"allowedOrigins"`
+	AllowedMethods   []string `yaml:"allowedMethods"`
+	AllowedHeaders   []string `yaml:"allowedHeaders"`
+	ExposedHeaders   []string `yaml:"exposedHeaders"`
+	AllowCredentials bool     `yaml:"allowCredentials"`
+	MaxAge           string   `yaml:"maxAge"`
+}
+
+// CORS parses configuration value n into a CORSPolicy. It expects a yaml or json object of
+// the form
+//		allowedOrigins: [https://example.com, "https://*.example.com"]
+//		allowedMethods: [GET, POST, DELETE]
+//		allowedHeaders: [Content-Type, Authorization]
+//		exposedHeaders: [X-Request-Id]
+//		allowCredentials: true
+//		maxAge: 10m
+//
+// Origins are "*", or a scheme and host with an optional port and no path; the host may
+// start with "*." to allow any subdomain. Methods and headers must be valid HTTP tokens;
+// methods default to DefaultCORSMethods. maxAge is a duration or a number of seconds.
+// Allowing any origin together with credentials is an error, since browsers reject it.
+//
+// To reject invalid policies when they are loaded, rather than when they are first used,
+// register ValidCORS with WithValidator.
+func (c *Config) CORS(n string) (CORSPolicy, error) {
+	var raw corsPolicy
+	err := c.InterfaceYaml(n, &raw)
+	if err != nil {
+		return CORSPolicy{}, err
+	}
+
+	result, err := parseCORS(raw)
+	if err != nil {
+		return CORSPolicy{}, errorf(ErrValidation, "config: invalid CORS policy in %s: %w", n, err)
+	}
+	return result, nil
+}
+
+// ValidCORS is a Validator that checks that a configuration value is a CORS policy, as
+// expected by CORS.
+func ValidCORS(_ string, b []byte) error {
+	var raw corsPolicy
+	err := yaml.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	_, err = parseCORS(raw)
+	return err
+}
+
+func parseCORS(raw corsPolicy) (CORSPolicy, error) {
+	result := CORSPolicy{AllowCredentials: raw.AllowCredentials}
+
+	for _, o := range raw.AllowedOrigins {
+		o, err := parseOrigin(o)
+		if err != nil {
+			return CORSPolicy{}, err
+		}
+		if o == "*" && raw.AllowCredentials {
+			return CORSPolicy{}, fmt.Errorf(`origin "*" cannot be combined with allowCredentials`)
+		}
+		result.AllowedOrigins = append(result.AllowedOrigins, o)
+	}
+
+	methods := raw.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+	for _, m := range methods {
+		if !isToken(m) {
+			return CORSPolicy{}, fmt.Errorf("invalid method %q", m)
+		}
+		result.AllowedMethods = append(result.AllowedMethods, strings.ToUpper(m))
+	}
+
+	for _, h := range raw.AllowedHeaders {
+		if !isToken(h) {
+			return CORSPolicy{}, fmt.Errorf("invalid allowed header %q", h)
+		}
+		result.AllowedHeaders = append(result.AllowedHeaders, http.CanonicalHeaderKey(h))
+	}
+	for _, h := range raw.ExposedHeaders {
+		if !isToken(h) {
+			return CORSPolicy{}, fmt.Errorf("invalid exposed header %q", h)
+		}
+		result.ExposedHeaders = append(result.ExposedHeaders, http.CanonicalHeaderKey(h))
+	}
+
+	if raw.MaxAge != "" {
+		secs, err := strconv.Atoi(raw.MaxAge)
+		if err == nil {
+			result.MaxAge = time.Duration(secs) * time.Second
+		} else if result.MaxAge, err = time.ParseDuration(raw.MaxAge); err != nil {
+			return CORSPolicy{}, fmt.Errorf("invalid maxAge %q", raw.MaxAge)
+		}
+		if result.MaxAge < 0 {
+			return CORSPolicy{}, fmt.Errorf("maxAge is negative")
+		}
+	}
+	return result, nil
+}
+
+// parseOrigin checks that o is "*" or a scheme and host with an optional port, and
+// returns it in lower case.
+func parseOrigin(o string) (string, error) {
+	if o == "*" {
+		return o, nil
+	}
+
+	u, err := url.Parse(strings.Replace(strings.ToLower(o), "://*.", "://wildcard.", 1))
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil || u.RawQuery != "" || u.Fragment != "" || (u.Path != "" && u.Path != "/") {
+		return "", fmt.Errorf("invalid origin %q: expected scheme://host[:port]", o)
+	}
+	return strings.TrimSuffix(strings.ToLower(o), "/"), nil
+}
+
+// isToken reports whether s is an HTTP token, as defined by RFC 7230.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+	return true
+}
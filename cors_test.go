@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfig_CORS(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"api":         "allowedOrigins: [https://Example.com/, \"https://*.example.com\"]\nallowedMethods: [get, delete]\nallowedHeaders: [content-type]\nexposedHeaders: [x-request-id]\nallowCredentials: true\nmaxAge: 10m\n",
+		"public":      `{"allowedOrigins": ["*"], "maxAge": 600}`,
+		"credentials": "allowedOrigins: ['*']\nallowCredentials: true\n",
+		"origin":      "allowedOrigins: [example.com]\n",
+		"path":        "allowedOrigins: [https://example.com/app]\n",
+		"method":      "allowedMethods: [\"GET POST\"]\n",
+		"maxage":      "maxAge: -5\n",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    CORSPolicy
+		wantErr bool
+	}{
+		{name: "api", want: CORSPolicy{
+			AllowedOrigins:   []string{"https://example.com", "https://*.example.com"},
+			AllowedMethods:   []string{"GET", "DELETE"},
+			AllowedHeaders:   []string{"Content-Type"},
+			ExposedHeaders:   []string{"X-Request-Id"},
+			AllowCredentials: true,
+			MaxAge:           10 * time.Minute,
+		}},
+		{name: "public", want: CORSPolicy{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET", "HEAD", "POST"},
+			MaxAge:         10 * time.Minute,
+		}},
+		{name: "credentials", wantErr: true},
+		{name: "origin", wantErr: true},
+		{name: "path", wantErr: true},
+		{name: "method", wantErr: true},
+		{name: "maxage", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CORS(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CORS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CORS() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var ve *ValidationError
+	if err := New(WithPath(dir), WithValidator("credentials", ValidCORS)).Load(); !errors.As(err, &ve) {
+		t.Errorf("Load() error = %v, want %T", err, ve)
+	}
+}
+
+func TestCORSPolicy_AllowsOrigin(t *testing.T) {
+	p := CORSPolicy{AllowedOrigins: []string{"https://example.com", "https://*.example.com"}}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://example.com", true},
+		{"https://EXAMPLE.com", true},
+		{"https://api.example.com", true},
+		{"https://api.eu.example.com", true},
+		{"http://example.com", false},
+		{"https://evilexample.com", false},
+		{"https://example.com.evil.org", false},
+	}
+	for _, tt := range tests {
+		if got := p.AllowsOrigin(tt.origin); got != tt.want {
+			t.Errorf("AllowsOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSV parses configuration value n as comma-separated values and returns its records.
+// Records may have different numbers of fields. Leading and trailing blank lines are
+// ignored.
+func (c *Config) CSV(n string) ([][]string, error) {
+	e, err := c.lookup(n)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := readCsv(e.data)
+	if err != nil {
+		return nil, newCsvError(n, e, result, err)
+	}
+	return result, nil
+}
+
+// InterfaceCsv parses configuration value n as comma-separated values with a header
+// record, and decodes the remaining records into v, which must be a pointer to a slice of
+// structs or of pointers to structs. Columns are matched to fields by the name in the
+// field's `csv` tag, or else case-insensitively by the field's name. A tag of "-" skips
+// the field, and columns without a matching field are ignored. Fields may be strings,
+// booleans, numbers, time.Durations or implement encoding.TextUnmarshaler, flag.Value or
+// encoding.BinaryUnmarshaler; empty values leave fields at their zero value.
+//
+// For example, a rates.csv of:
+//		currency,rate
+//		EUR,0.92
+// decodes into []struct{ Currency string; Rate float64 `csv:"rate"` }.
+func (c *Config) InterfaceCsv(n string, v interface{}) error {
+	e, err := c.lookup(n)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errorf(ErrDecode, "config: failed to unmarshal %s into %T: target must be a pointer to a slice", n, v)
+	}
+	slice := rv.Elem()
+	elem := slice.Type().Elem()
+	ptr := elem.Kind() == reflect.Ptr
+	if ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return errorf(ErrDecode, "config: failed to unmarshal %s into %T: slice elements must be structs", n, v)
+	}
+
+	records, err := readCsv(e.data)
+	if err != nil {
+		return newCsvError(n, e, v, err)
+	}
+	if len(records) == 0 {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+		return nil
+	}
+
+	fields := csvFields(elem, records[0])
+	result := reflect.MakeSlice(slice.Type(), 0, len(records)-1)
+	for i, r := range records[1:] {
+		item := reflect.New(elem).Elem()
+		for j, s := range r {
+			if j >= len(fields) || fields[j] == nil || s == "" {
+				continue
+			}
+			err := setCsvField(item.FieldByIndex(fields[j]), s)
+			if err != nil {
+				return newDecodeError(n, e, v, i+2, 0, fmt.Errorf("column %q: %w", records[0][j], err))
+			}
+		}
+		if ptr {
+			item = item.Addr()
+		}
+		result = reflect.Append(result, item)
+	}
+	slice.Set(result)
+	return nil
+}
+
+func readCsv(b []byte) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimSpace(b)))
+	r.FieldsPerRecord = -1
+	return r.ReadAll()
+}
+
+// newCsvError wraps an error returned by csv.Reader, using the line it reports (if any)
+// to locate the failure.
+func newCsvError(n string, e entry, v interface{}, err error) error {
+	line, col := 0, 0
+	var pe *csv.ParseError
+	if errors.As(err, &pe) {
+		line, col = pe.Line, pe.Column
+		// The leading blank lines trimmed by readCsv are not counted.
+		line += bytes.Count(e.data[:len(e.data)-len(bytes.TrimLeft(e.data, " \t\r\n"))], []byte("\n"))
+	}
+	return newDecodeError(n, e, v, line, col, err)
+}
+
+// csvFields returns the index of the field of struct type t that each column of header
+// maps to, or nil for columns without one.
+func csvFields(t reflect.Type, header []string) [][]int {
+	result := make([][]int, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		for j := 0; j < t.NumField(); j++ {
+			f := t.Field(j)
+			if f.PkgPath != "" {
+				continue
+			}
+			tag := f.Tag.Get("csv")
+			if tag == "-" {
+				continue
+			}
+			if tag == h || tag == "" && strings.EqualFold(f.Name, h) {
+				result[i] = f.Index
+				break
+			}
+		}
+	}
+	return result
+}
+
+func setCsvField(f reflect.Value, s string) error {
+	if f.CanAddr() {
+		if ok, err := unmarshalText(f, s); ok {
+			return err
+		}
+	}
+
+	if f.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(x)
+	case reflect.Ptr:
+		p := reflect.New(f.Type().Elem())
+		err := setCsvField(p.Elem(), s)
+		if err != nil {
+			return err
+		}
+		f.Set(p)
+	default:
+		return fmt.Errorf("unsupported field type %v", f.Type())
+	}
+	return nil
+}
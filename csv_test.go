@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfig_CSV(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"countries.csv": "\ncode,name\nDE,Germany\n\"US\",\"United States, The\"\n",
+		"ragged.csv":    "a,b\nc\n",
+		"bad.csv":       "a,b\n\"c,d\n",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name     string
+		want     [][]string
+		wantLine int
+	}{
+		{name: "countries.csv", want: [][]string{{"code", "name"}, {"DE", "Germany"}, {"US", "United States, The"}}},
+		{name: "ragged.csv", want: [][]string{{"a", "b"}, {"c"}}},
+		{name: "bad.csv", wantLine: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CSV(tt.name)
+			if tt.wantLine > 0 {
+				var de *DecodeError
+				if !errors.As(err, &de) || de.Line < tt.wantLine {
+					t.Errorf("CSV() error = %v, want *DecodeError at line %d", err, tt.wantLine)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CSV() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+type csvRate struct {
+	Currency string
+	Rate     float64       `csv:"rate"`
+	Active   bool          `csv:"active"`
+	Settle   time.Duration `csv:"settle"`
+	Limit    *int          `csv:"limit"`
+	Gateway  net.IP        `csv:"gateway"`
+	Internal string        `csv:"-"`
+}
+
+func TestConfig_InterfaceCsv(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"rates.csv": "currency,rate,active,settle,limit,gateway,internal,extra\n" +
+			"EUR,0.92,true,48h,100,10.0.0.1,x,y\n" +
+			"JPY,151.5,false,,,,,\n",
+		"bad.csv": "currency,rate\nEUR,high\n",
+	})
+	c := New(WithPath(dir))
+
+	limit := 100
+	want := []csvRate{
+		{Currency: "EUR", Rate: 0.92, Active: true, Settle: 48 * time.Hour, Limit: &limit, Gateway: net.ParseIP("10.0.0.1")},
+		{Currency: "JPY", Rate: 151.5},
+	}
+
+	var got []csvRate
+	if err := c.InterfaceCsv("rates.csv", &got); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("InterfaceCsv() = %+v, %v, want %+v", got, err, want)
+	}
+
+	var ptrs []*csvRate
+	if err := c.InterfaceCsv("rates.csv", &ptrs); err != nil || len(ptrs) != 2 || !reflect.DeepEqual(*ptrs[1], want[1]) {
+		t.Errorf("InterfaceCsv() = %+v, %v, want pointers to %+v", ptrs, err, want)
+	}
+
+	var de *DecodeError
+	if err := c.InterfaceCsv("bad.csv", &got); !errors.As(err, &de) || de.Line != 2 {
+		t.Errorf("InterfaceCsv() error = %v, want *DecodeError at line 2", err)
+	}
+
+	var notSlice csvRate
+	if err := c.InterfaceCsv("rates.csv", &notSlice); err == nil {
+		t.Errorf("InterfaceCsv() error = %v, wantErr %v", err, true)
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DecodeError is returned when a configuration value cannot be decoded as JSON or YAML.
+// It records where in the source file decoding failed, so the offending line can be found
+// without searching through the whole file.
+type DecodeError struct {
+	Name    string // Name is the name of the configuration value.
+	Path    string // Path is the file the configuration value was read from.
+	Target  string // Target is the type that was being decoded into.
+	Line    int    // Line is the 1-based line of the failure, or 0 if it is unknown.
+	Column  int    // Column is the 1-based column of the failure, or 0 if it is unknown.
+	Context string // Context is an excerpt of the source surrounding Line.
+	Err     error  // Err is the error returned by the decoder.
+}
+
+func (e *DecodeError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "config: failed to unmarshal %s into %s", e.Name, e.Target)
+	if e.Path != "" {
+		fmt.Fprintf(&sb, " (%s", e.Path)
+		if e.Line > 0 {
+			fmt.Fprintf(&sb, ":%d", e.Line)
+			if e.Column > 0 {
+				fmt.Fprintf(&sb, ":%d", e.Column)
+			}
+		}
+		sb.WriteString(")")
+	}
+	fmt.Fprintf(&sb, ": %v", e.Err)
+	if e.Context != "" {
+		sb.WriteString("\n")
+		sb.WriteString(e.Context)
+	}
+	return sb.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+// decodeContextLines is the number of lines shown on either side of a decode failure.
+const decodeContextLines = 2
+
+func newDecodeError(n string, e entry, v interface{}, line, col int, err error) *DecodeError {
+	return &DecodeError{
+		Name:    n,
+		Path:    e.path,
+		Target:  fmt.Sprintf("%T", v),
+		Line:    line,
+		Column:  col,
+		Context: excerpt(e.data, line),
+		Err:     err,
+	}
+}
+
+// newJsonError wraps an error returned by json.Unmarshal, using the byte offset it
+// reports (if any) to locate the failure.
+func newJsonError(n string, e entry, v interface{}, err error) error {
+	var offset int64 = -1
+
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		offset = se.Offset
+	case errors.As(err, &te):
+		offset = te.Offset
+	}
+
+	line, col := 0, 0
+	if offset >= 0 {
+		line, col = position(e.data, offset)
+	}
+
+	return newDecodeError(n, e, v, line, col, err)
+}
+
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)(?:: column (\d+))?`)
+
+// newYamlError wraps an error returned by yaml.Unmarshal, using the first line (and
+// column) reported in its message (if any) to locate the failure.
+func newYamlError(n string, e entry, v interface{}, err error) error {
+	line, col := 0, 0
+	if m := yamlLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		col, _ = strconv.Atoi(m[2])
+	}
+
+	return newDecodeError(n, e, v, line, col, err)
+}
+
+// position converts a byte offset into b to a 1-based line and column.
+func position(b []byte, offset int64) (line, col int) {
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	before := b[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n') - 1
+	if col < 1 {
+		col = 1
+	}
+	return line, col
+}
+
+// excerpt returns the lines of b surrounding line, with line itself marked.
+func excerpt(b []byte, line int) string {
+	if line <= 0 {
+		return ""
+	}
+
+	lines := strings.Split(string(b), "\n")
+	if line > len(lines) {
+		return ""
+	}
+
+	first, last := line-decodeContextLines, line+decodeContextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+
+	width := len(strconv.Itoa(last))
+	var sb strings.Builder
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "%s %*d | %s\n", marker, width, i, strings.TrimRight(lines[i-1], "\r"))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeError(t *testing.T) {
+	type server struct {
+		Port int `json:"port" yaml:"port"`
+	}
+	type app struct {
+		Port   int    `json:"port"`
+		Server server `yaml:"server"`
+	}
+
+	tests := []struct {
+		name       string
+		decode     func(n string, v interface{}) error
+		n          string
+		wantPath   string
+		wantLine   int
+		wantColumn int
+		wantMarked string
+	}{
+		{
+			"1/typed.json",
+			InterfaceJson,
+			"typed.json",
+			filepath.Join("testdata", "1", "typed.json"),
+			3,
+			16,
+			`> 3 |   "port": "http",`,
+		},
+		{
+			"1/bad.json",
+			InterfaceJson,
+			"bad.json",
+			filepath.Join("testdata", "1", "bad.json"),
+			3,
+			16,
+			`> 3 |   "port": 8080,,`,
+		},
+		{
+			"2/typed.yaml",
+			InterfaceYaml,
+			"typed.yaml",
+			filepath.Join("testdata", "2", "typed.yaml"),
+			3,
+			0,
+			`> 3 |   port: http`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decode(tt.n, new(app))
+			var de *DecodeError
+			if !errors.As(err, &de) {
+				t.Fatalf("error = %v, want %T", err, de)
+			}
+			if de.Path != tt.wantPath || de.Line != tt.wantLine || de.Column != tt.wantColumn {
+				t.Errorf("location = %s:%d:%d, want %s:%d:%d", de.Path, de.Line, de.Column, tt.wantPath, tt.wantLine, tt.wantColumn)
+			}
+			if !strings.Contains(de.Context, tt.wantMarked) {
+				t.Errorf("context = %q, want it to contain %q", de.Context, tt.wantMarked)
+			}
+			if !strings.Contains(err.Error(), tt.wantPath) {
+				t.Errorf("Error() = %q, want it to contain %q", err.Error(), tt.wantPath)
+			}
+		})
+	}
+}
+
+func Test_position(t *testing.T) {
+	b := []byte("ab\ncd\nef")
+	tests := []struct {
+		offset   int64
+		wantLine int
+		wantCol  int
+	}{
+		{0, 1, 1},
+		{2, 1, 2},
+		{3, 2, 1},
+		{5, 2, 2},
+		{7, 3, 1},
+		{100, 3, 2},
+	}
+	for _, tt := range tests {
+		line, col := position(b, tt.offset)
+		if line != tt.wantLine || col != tt.wantCol {
+			t.Errorf("position(%d) = %d:%d, want %d:%d", tt.offset, line, col, tt.wantLine, tt.wantCol)
+		}
+	}
+}
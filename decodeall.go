@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package config
+
+// DecodeAll calls DecodeGroup[T](Group(pattern)), decoding the values of the default
+// Config whose names match pattern.
+func DecodeAll[T any](pattern string) (map[string]T, error) {
+	return DecodeGroup[T](Group(pattern))
+}
+
+// DecodeGroup decodes each configuration value in g into a T, keyed by its name, as
+// DecodeEach does. This suits trees with one value per object, such as a YAML file per
+// customer or queue:
+//
+//		queues, err := config.DecodeGroup[Queue](c.Group("queues/*.yaml"))
+//
+// If any values fail to decode, the others are still returned, along with a *GroupError
+// naming each failure. An empty group returns an empty map.
+func DecodeGroup[T any](g *ValueGroup) (map[string]T, error) {
+	result := map[string]T{}
+	err := g.DecodeEach(func(name string, dec Decoder) error {
+		var v T
+		err := dec.Decode(&v)
+		if err != nil {
+			return err
+		}
+		result[name] = v
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*GroupError); !ok {
+			return nil, err
+		}
+	}
+	return result, err
+}
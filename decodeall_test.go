@@ -0,0 +1,71 @@
+//go:build go1.18
+// +build go1.18
+
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeGroup(t *testing.T) {
+	type queue struct {
+		Workers int    `json:"workers" yaml:"workers"`
+		DLQ     string `json:"dlq" yaml:"dlq"`
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"queues/orders.yaml":  "workers: 4\ndlq: orders-dead",
+		"queues/emails.json":  `{"workers": 2}`,
+		"queues/broken.yaml":  "workers: [1",
+		"queues/typo.yaml":    "workers: many",
+		"customers/acme.yaml": "workers: 1",
+	})
+	c := New(WithPath(dir), WithMaxDepth(2))
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    map[string]queue
+		wantErr []string
+	}{
+		{
+			name:    "all",
+			pattern: "queues/*",
+			want: map[string]queue{
+				"queues/orders.yaml": {Workers: 4, DLQ: "orders-dead"},
+				"queues/emails.json": {Workers: 2},
+			},
+			wantErr: []string{"queues/broken.yaml", "queues/typo.yaml"},
+		},
+		{
+			name:    "clean",
+			pattern: "queues/[eo]*",
+			want: map[string]queue{
+				"queues/orders.yaml": {Workers: 4, DLQ: "orders-dead"},
+				"queues/emails.json": {Workers: 2},
+			},
+		},
+		{name: "empty", pattern: "jobs/*.yaml", want: map[string]queue{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeGroup[queue](c.Group(tt.pattern))
+			var ge *GroupError
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("DecodeGroup() error = %v", err)
+			}
+			if tt.wantErr != nil && (!errors.As(err, &ge) || !reflect.DeepEqual(ge.Names, tt.wantErr)) {
+				t.Fatalf("DecodeGroup() error = %v, want failures for %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeGroup() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := DecodeGroup[queue](c.Group("[")); err == nil {
+		t.Errorf("DecodeGroup() with an invalid pattern error = nil")
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+const (
+	// DefaultsPriority is the priority of the values registered with SetDefault. It is
+	// lower than that of any other source, so defaults are only used for names that
+	// nothing else provides.
+	DefaultsPriority = math.MinInt32
+	// OverridesPriority is the priority of the values added with WithOverrides. It is
+	// higher than that of any other source, so overrides replace values from anywhere else.
+	OverridesPriority = math.MaxInt32
+)
+
+// SetDefault registers data as the default for configuration value n. Defaults are loaded
+// with DefaultsPriority, so a file or source providing n overrides them. This allows
+// libraries to ship defaults in code. Defaults take effect when c is loaded, so they
+// should be set before c is first used, or c must be reloaded.
+func (c *Config) SetDefault(n string, data []byte) {
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.defaults == nil {
+		s.defaults = map[string][]byte{}
+	}
+	s.defaults[c.prefix+n] = append([]byte(nil), data...)
+}
+
+// SetDefaultString registers v as the default for configuration value n. See SetDefault.
+func (c *Config) SetDefaultString(n string, v string) {
+	c.SetDefault(n, []byte(v))
+}
+
+// SetDefaultJson registers the JSON encoding of v as the default for configuration value
+// n. See SetDefault.
+func (c *Config) SetDefaultJson(n string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("config: failed to marshal default for %s: %w", n, err)
+	}
+	c.SetDefault(n, b)
+	return nil
+}
+
+// WithOverrides adds vals as the source of the Config with OverridesPriority, so they
+// replace the values with the same names from every other source. This allows values
+// parsed from command line flags to take precedence over configuration files. vals is
+// copied.
+func WithOverrides(vals map[string][]byte) Option {
+	return WithSourcePriority(newMapSource("override", vals), OverridesPriority)
+}
+
+// withDefaults returns the options of s with the defaults registered with SetDefault
+// added as the lowest-priority source.
+func (s *store) withDefaults() *options {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o := s.options
+	if len(s.defaults) == 0 {
+		return &o
+	}
+
+	d := newMapSource("default", s.defaults)
+	o.sources = append(append([]weightedSource(nil), o.sources...), weightedSource{Source: d, priority: DefaultsPriority})
+	return &o
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestConfig_SetDefault(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"port": "9090",
+	})
+	c := New(WithPath(dir))
+	c.SetDefaultString("port", "8080")
+	c.SetDefaultString("host", "localhost")
+	c.Scoped("db.").SetDefault("url", []byte("postgres://localhost"))
+	if err := c.SetDefaultJson("limits.json", map[string]int{"rps": 10}); err != nil {
+		t.Fatalf("SetDefaultJson() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		want         string
+		wantPriority int
+	}{
+		{name: "port", want: "9090", wantPriority: 0},
+		{name: "host", want: "localhost", wantPriority: DefaultsPriority},
+		{name: "db.url", want: "postgres://localhost", wantPriority: DefaultsPriority},
+		{name: "limits.json", want: `{"rps":10}`, wantPriority: DefaultsPriority},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.String(tt.name)
+			if err != nil || got != tt.want {
+				t.Errorf("String() = %q, %v, want %q", got, err, tt.want)
+			}
+			if o, err := c.Origin(tt.name); err != nil || o.Priority != tt.wantPriority {
+				t.Errorf("Origin() = %+v, %v, want priority %d", o, err, tt.wantPriority)
+			}
+		})
+	}
+
+	c.SetDefaultString("timeout", "5s")
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, err := c.Duration("timeout"); err != nil || got.String() != "5s" {
+		t.Errorf("Duration() = %v, %v, want %v", got, err, "5s")
+	}
+
+	if err := c.SetDefaultJson("invalid", func() {}); err == nil {
+		t.Errorf("SetDefaultJson() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"port": "9090",
+		"host": "example.com",
+	})
+	vals := map[string][]byte{"port": []byte("7070"), "debug": []byte("true")}
+	c := New(
+		WithPath(dir),
+		WithOverrides(vals),
+		WithSourcePriority(staticSource{"port": "6060"}, 100),
+	)
+	vals["host"] = []byte("ignored")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "port", want: "7070"},
+		{name: "debug", want: "true"},
+		{name: "host", want: "example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := c.String(tt.name); err != nil || got != tt.want {
+				t.Errorf("String() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	if o, err := c.Origin("port"); err != nil || o.Priority != OverridesPriority || o.Source != "override" {
+		t.Errorf("Origin() = %+v, %v, want priority %d from %q", o, err, OverridesPriority, "override")
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DownwardAPI returns a Source that provides the pod metadata in a Kubernetes Downward API
+// volume mounted at dir. Every file in the volume (e.g. "namespace", "cpu_limit") is
+// provided under its own name. In addition, the keys of the "labels" and "annotations"
+// files are provided individually as "labels.<key>" and "annotations.<key>".
+//
+// Resource limits are written by Kubernetes as integers in units of the configured divisor,
+// and can be read with Int. Label and annotation files can be read with Labels.
+func DownwardAPI(dir string) Source {
+	return downwardSource(dir)
+}
+
+// downwardMapFiles are the names of Downward API files that are expanded into one
+// configuration value per key.
+var downwardMapFiles = []string{"labels", "annotations"}
+
+type downwardSource string
+
+func (d downwardSource) Files(ctx context.Context) ([]File, error) {
+	fs, err := dirSource(d).Files(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []File
+	for _, f := range fs {
+		// Kubernetes updates volumes atomically through "..data" symlinks and
+		// timestamped "..<time>" directories; neither is metadata.
+		if strings.HasPrefix(f.Name, "..") {
+			continue
+		}
+		result = append(result, f)
+
+		if !isDownwardMapFile(f.Name) {
+			continue
+		}
+
+		m, err := parseLabels(f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to parse downward API file %q: %w", f.Path, err)
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			result = append(result, File{
+				Name: f.Name + "." + k,
+				Path: f.Path + "#" + k,
+				Data: []byte(m[k]),
+			})
+		}
+	}
+	return result, nil
+}
+
+func isDownwardMapFile(n string) bool {
+	for _, m := range downwardMapFiles {
+		if n == m {
+			return true
+		}
+	}
+	return false
+}
+
+// Labels parses configuration value n as a Kubernetes Downward API labels or annotations
+// file, which has one key="value" pair per line with Go-quoted values.
+//		app="web"
+//		tier="frontend"
+func (c *Config) Labels(n string) (map[string]string, error) {
+	b, err := c.Bytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseLabels(b)
+	if err != nil {
+		return nil, errorf(ErrDecode, "config: failed to unmarshal %s into %T: %w", n, result, err)
+	}
+
+	return result, nil
+}
+
+func parseLabels(b []byte) (map[string]string, error) {
+	result := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(nil, len(b)+1)
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" {
+			continue
+		}
+
+		i := strings.IndexByte(l, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected key=\"value\"", line)
+		}
+
+		v, err := strconv.Unquote(l[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted value: %w", line, err)
+		}
+		result[l[:i]] = v
+	}
+	return result, sc.Err()
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDownwardAPI(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"podinfo/namespace":      "billing",
+		"podinfo/cpu_limit":      "2\n",
+		"podinfo/labels":         "app=\"web\"\ntier=\"front\\\"end\"\n",
+		"podinfo/annotations":    "build=\"1234\"\n",
+		"podinfo/..data/ignored": "x",
+		"config/url":             "http://billing",
+	})
+
+	c := New(WithPath(filepath.Join(dir, "config")), WithSource(DownwardAPI(filepath.Join(dir, "podinfo"))))
+
+	for n, want := range map[string]string{
+		"namespace":         "billing",
+		"labels.app":        "web",
+		"labels.tier":       `front"end`,
+		"annotations.build": "1234",
+		"url":               "http://billing",
+	} {
+		if got, err := c.String(n); err != nil || got != want {
+			t.Errorf("String(%q) = %q, %v, want %q", n, got, err, want)
+		}
+	}
+
+	if got, err := c.Int("cpu_limit"); err != nil || got != 2 {
+		t.Errorf("Int() = %v, %v, want %v", got, err, 2)
+	}
+
+	got, err := c.Labels("labels")
+	if want := map[string]string{"app": "web", "tier": `front"end`}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %v, %v, want %v", got, err, want)
+	}
+
+	if _, err := c.Labels("url"); err == nil {
+		t.Errorf("Labels() error = %v, wantErr %v", err, true)
+	}
+	if _, err := c.Bytes("ignored"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Bytes() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
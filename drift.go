@@ -0,0 +1,70 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Drift describes the differences between the loaded configuration values and their
+// sources.
+type Drift struct {
+	Entries []DriftEntry // Entries are the values that differ, sorted by name.
+}
+
+// DriftEntry describes a configuration value that differs from its source.
+type DriftEntry struct {
+	Name    string // Name is the name of the configuration value.
+	Loaded  string // Loaded is the hex SHA-256 hash of the loaded value, or "" if it was added since.
+	Current string // Current is the hex SHA-256 hash of the value in its source, or "" if it was removed since.
+}
+
+// Stale reports whether any configuration value differs from its source.
+func (d Drift) Stale() bool {
+	return len(d.Entries) > 0
+}
+
+// Verify calls c.Load() then reads the sources of c again and reports which values have
+// changed since they were loaded, without replacing them. Values are read exactly as
+// Reload would read them, so decryption and template rendering are applied, but they are
+// not validated. Use it to find out whether a process is running with stale
+// configuration.
+func (c *Config) Verify() (Drift, error) {
+	err := c.Load()
+	if err != nil {
+		return Drift{}, err
+	}
+
+	s := c.s
+	_, current, _, err := s.read(context.Background())
+	if err != nil {
+		return Drift{}, fmt.Errorf("config: encountered while verifying config: %w", err)
+	}
+	splitSchedule(nil, current, timeNow())
+
+	s.mu.RLock()
+	loaded := s.val
+	s.mu.RUnlock()
+
+	var result Drift
+	for _, n := range changedNames(loaded, current) {
+		result.Entries = append(result.Entries, DriftEntry{
+			Name:    n,
+			Loaded:  hashEntry(loaded, n),
+			Current: hashEntry(current, n),
+		})
+	}
+	return result, nil
+}
+
+// hashEntry returns the hex SHA-256 hash of the data of entry n of m, or "" if there is
+// none.
+func hashEntry(m map[string]entry, n string) string {
+	e, ok := m[n]
+	if !ok {
+		return ""
+	}
+	sum := sha256.Sum256(e.unpacked().data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Verify(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"same":    "same",
+		"changed": "old",
+		"removed": "x",
+	})
+	c := New(WithPath(dir))
+
+	d, err := c.Verify()
+	if err != nil || d.Stale() {
+		t.Fatalf("Verify() = %+v, %v, want no drift", d, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "changed"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "added"), []byte("y"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "removed")); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err = c.Verify()
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	tests := []struct {
+		name        string
+		wantLoaded  bool
+		wantCurrent bool
+	}{
+		{name: "added", wantLoaded: false, wantCurrent: true},
+		{name: "changed", wantLoaded: true, wantCurrent: true},
+		{name: "removed", wantLoaded: true, wantCurrent: false},
+	}
+	if len(d.Entries) != len(tests) {
+		t.Fatalf("Verify() = %+v, want %d entries", d, len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := d.Entries[i]
+			if e.Name != tt.name || (e.Loaded != "") != tt.wantLoaded || (e.Current != "") != tt.wantCurrent || e.Loaded == e.Current {
+				t.Errorf("Verify() entry = %+v, want %+v", e, tt)
+			}
+		})
+	}
+
+	if got, _ := c.String("changed"); got != "old" {
+		t.Errorf("String() = %q, want %q; Verify() must not replace values", got, "old")
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the values of secrets written by Dump.
+const Redacted = "REDACTED"
+
+// DumpOption configures Dump.
+type DumpOption func(*dumpOptions)
+
+type dumpOptions struct {
+	redact     []string
+	omitValues bool
+	hashKey    []byte
+}
+
+// RedactNames redacts the values whose names match any of patterns, as accepted by
+// path.Match, in addition to the secrets of the Config. Names are matched
+// case-insensitively.
+func RedactNames(patterns ...string) DumpOption {
+	return func(o *dumpOptions) {
+		o.redact = append(o.redact, patterns...)
+	}
+}
+
+// OmitValues leaves out every value, so that the dump only describes where values were
+// read from.
+func OmitValues() DumpOption {
+	return func(o *dumpOptions) {
+		o.omitValues = true
+	}
+}
+
+// HashSecrets records an HMAC-SHA256 of each redacted value keyed with key, so that dumps
+// written with the same key show whether secrets differ without revealing them. Without
+// it, no hash of redacted values is written, since a plain hash of a short secret can be
+// reversed by guessing.
+func HashSecrets(key []byte) DumpOption {
+	return func(o *dumpOptions) {
+		o.hashKey = append([]byte(nil), key...)
+	}
+}
+
+// DumpEntry describes a configuration value in the output of Dump.
+type DumpEntry struct {
+	Name     string     `json:"name"`               // Name is the name of the value.
+	Path     string     `json:"path"`               // Path is the file the value was read from.
+	Source   string     `json:"source"`             // Source is the search path entry, or a description of the Source, containing Path.
+	Priority int        `json:"priority"`           // Priority is the priority of Source; see WithSourcePriority.
+	Size     int        `json:"size"`               // Size is the length of the value in bytes.
+	SHA256   string     `json:"sha256,omitempty"`   // SHA256 is the hash of the value, unless it is redacted.
+	HMAC     string     `json:"hmac,omitempty"`     // HMAC is the keyed hash of a redacted value; see HashSecrets.
+	Expires  *time.Time `json:"expires,omitempty"`  // Expires is when the value expires, if known.
+	Redacted bool       `json:"redacted,omitempty"` // Redacted is true if the value is a secret or matches RedactNames.
+	Value    *string    `json:"value,omitempty"`    // Value is the value, or Redacted. It is nil with OmitValues.
+}
+
+// DumpFile is the document written by Dump.
+type DumpFile struct {
+	Path       string      `json:"path"`       // Path is the search path the values were loaded from.
+	Generation uint64      `json:"generation"` // Generation is the Generation of the values.
+	LoadedAt   time.Time   `json:"loadedAt"`   // LoadedAt is when the values were loaded.
+	Entries    []DumpEntry `json:"entries"`    // Entries are the values, sorted by name.
+}
+
+// Dump calls c.Load() then writes every loaded value to w as an indented JSON DumpFile,
+// along with where it was read from and its hash, for attaching to support tickets. The
+// values of secrets (see WithSecrets) and of names matched by RedactNames are replaced with
+// Redacted and their hashes are left out. Values are written as they were loaded, after
+// decryption and template rendering, so only the redaction stands between them and the
+// reader: use RedactNames or OmitValues for sensitive values that are not named like
+// secrets.
+func (c *Config) Dump(w io.Writer, opts ...DumpOption) error {
+	var o dumpOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := c.Load()
+	if err != nil {
+		return err
+	}
+
+	c.s.mu.RLock()
+	val := c.s.val
+	result := DumpFile{Path: c.s.resolved, Generation: c.s.generation, LoadedAt: c.s.loadedAt}
+	c.s.mu.RUnlock()
+
+	names := make([]string, 0, len(val))
+	for n := range val {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	all := &Config{s: c.s}
+	result.Entries = make([]DumpEntry, 0, len(names))
+	for _, n := range names {
+		e := val[n].unpacked()
+		de := DumpEntry{
+			Name:     n,
+			Path:     e.path,
+			Source:   e.source,
+			Priority: e.priority,
+			Size:     len(e.data),
+			Redacted: all.IsSecret(n) || o.redacts(n),
+		}
+
+		if !e.expires.IsZero() {
+			expires := e.expires
+			de.Expires = &expires
+		}
+
+		v := string(e.data)
+		if de.Redacted {
+			v = Redacted
+			if o.hashKey != nil {
+				mac := hmac.New(sha256.New, o.hashKey)
+				_, _ = mac.Write(e.data)
+				de.HMAC = hex.EncodeToString(mac.Sum(nil))
+			}
+		} else {
+			de.SHA256 = hashEntry(val, n)
+		}
+		if !o.omitValues {
+			de.Value = &v
+		}
+		result.Entries = append(result.Entries, de)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(result)
+	if err != nil {
+		return fmt.Errorf("config: failed to dump config: %w", err)
+	}
+	return nil
+}
+
+// redacts reports whether configuration value n matches the patterns of RedactNames.
+func (o *dumpOptions) redacts(n string) bool {
+	n = strings.ToLower(n)
+	for _, p := range o.redact {
+		if ok, _ := path.Match(strings.ToLower(p), n); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Dump(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db_password":  "hunter2",
+		"api_url":      "https://api",
+		"internal_ids": "1,2,3",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name      string
+		opts      []DumpOption
+		wantValue map[string]string // wantValue maps names to values, or "" if omitted.
+		wantHMAC  bool
+	}{
+		{
+			name:      "default",
+			wantValue: map[string]string{"api_url": "https://api", "db_password": Redacted, "internal_ids": "1,2,3"},
+		},
+		{
+			name:      "redact names",
+			opts:      []DumpOption{RedactNames("INTERNAL_*")},
+			wantValue: map[string]string{"api_url": "https://api", "db_password": Redacted, "internal_ids": Redacted},
+		},
+		{
+			name:      "omit values",
+			opts:      []DumpOption{OmitValues()},
+			wantValue: map[string]string{"api_url": "", "db_password": "", "internal_ids": ""},
+		},
+		{
+			name:      "hash secrets",
+			opts:      []DumpOption{HashSecrets([]byte("key"))},
+			wantValue: map[string]string{"api_url": "https://api", "db_password": Redacted, "internal_ids": "1,2,3"},
+			wantHMAC:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := c.Dump(&buf, tt.opts...); err != nil {
+				t.Fatalf("Dump() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "hunter2") {
+				t.Fatalf("Dump() wrote a secret:\n%s", buf.String())
+			}
+
+			var got DumpFile
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Dump() wrote invalid JSON: %v", err)
+			}
+			if got.Path != dir || got.Generation != 1 || len(got.Entries) != len(tt.wantValue) {
+				t.Fatalf("Dump() got = %+v", got)
+			}
+			for _, e := range got.Entries {
+				var v string
+				if e.Value != nil {
+					v = *e.Value
+				}
+				if want := tt.wantValue[e.Name]; v != want {
+					t.Errorf("Dump() %s value = %q, want %q", e.Name, v, want)
+				}
+				if e.Redacted != (e.SHA256 == "") || e.Redacted && (e.HMAC != "") != tt.wantHMAC {
+					t.Errorf("Dump() %s hashes = %q, %q, redacted %v", e.Name, e.SHA256, e.HMAC, e.Redacted)
+				}
+			}
+		})
+	}
+}
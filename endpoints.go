@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SRVScheme is the prefix of Endpoints entries that are resolved with a DNS SRV lookup.
+const SRVScheme = "dns+srv://"
+
+// lookupSRV resolves SRV records. It is a variable so tests can replace it.
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// endpointSeed orders endpoints differently in each process, so clients of the same
+// service spread their load.
+var endpointSeed = rand.New(rand.NewSource(time.Now().UnixNano())).Uint64()
+
+// Endpoints calls c.String(n) and parses the result as a list of "host:port" endpoints
+// separated by commas or whitespace, for configuring client-side load balancing. Entries
+// of the form "dns+srv://_service._proto.name" are resolved with a DNS SRV lookup each
+// time Endpoints is called, and replaced by the targets found.
+//
+// The result is shuffled, but stable: within a process, an endpoint is always returned in
+// the same position relative to the others, so repeated calls agree and adding or removing
+// one endpoint does not reorder the rest. Different processes use different orders.
+// Duplicate endpoints are removed.
+func (c *Config) Endpoints(n string) ([]string, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	seen := map[string]bool{}
+	add := func(e string) {
+		if !seen[e] {
+			seen[e] = true
+			result = append(result, e)
+		}
+	}
+
+	for _, e := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r' }) {
+		if strings.HasPrefix(e, SRVScheme) {
+			_, srvs, err := lookupSRV(context.Background(), "", "", strings.TrimPrefix(e, SRVScheme))
+			if err != nil {
+				return nil, errorf(ErrSource, "config: failed to resolve %s in %s: %w", e, n, err)
+			}
+			for _, srv := range srvs {
+				add(net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))))
+			}
+			continue
+		}
+
+		host, port, err := net.SplitHostPort(e)
+		if err != nil {
+			return nil, errorf(ErrDecode, "config: invalid endpoint %q in %s: %w", e, n, err)
+		}
+		if p, err := strconv.Atoi(port); err != nil || host == "" || p < 1 || p > 65535 {
+			return nil, errorf(ErrDecode, "config: invalid endpoint %q in %s: expected host:port", e, n)
+		}
+		add(e)
+	}
+
+	if len(result) == 0 {
+		return nil, errorf(ErrValidation, "config: %s has no endpoints", n)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return endpointRank(result[i]) < endpointRank(result[j])
+	})
+	return result, nil
+}
+
+// endpointRank returns the position of endpoint e in the order of this process.
+func endpointRank(e string) uint64 {
+	h := fnv.New64a()
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], endpointSeed)
+	_, _ = h.Write(seed[:])
+	_, _ = h.Write([]byte(e))
+	return h.Sum64()
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestConfig_Endpoints(t *testing.T) {
+	defer func(f func(context.Context, string, string, string) (string, []*net.SRV, error)) { lookupSRV = f }(lookupSRV)
+	lookupSRV = func(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+		if name != "_grpc._tcp.example.com" {
+			return "", nil, errors.New("no such host")
+		}
+		return name, []*net.SRV{{Target: "c.example.com.", Port: 9000}, {Target: "d.example.com.", Port: 9000}}, nil
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"hosts":    "a:80, b:80\n[::1]:443,a:80",
+		"srv":      "dns+srv://_grpc._tcp.example.com,a:80",
+		"badsrv":   "dns+srv://_grpc._tcp.missing.com",
+		"noport":   "a",
+		"badport":  "a:99999",
+		"nohost":   ":80",
+		"empty":    " , ",
+		"reversed": "[::1]:443 b:80 a:80",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "hosts", want: []string{"[::1]:443", "a:80", "b:80"}},
+		{name: "srv", want: []string{"a:80", "c.example.com:9000", "d.example.com:9000"}},
+		{name: "badsrv", wantErr: true},
+		{name: "noport", wantErr: true},
+		{name: "badport", wantErr: true},
+		{name: "nohost", wantErr: true},
+		{name: "empty", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Endpoints(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Endpoints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			sorted := append([]string(nil), got...)
+			sort.Strings(sorted)
+			if !tt.wantErr && !reflect.DeepEqual(sorted, tt.want) {
+				t.Errorf("Endpoints() = %q, want %q in any order", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.Endpoints("badsrv"); !errors.Is(err, ErrSource) {
+		t.Errorf("Endpoints() error = %v, want %v for a failed lookup", err, ErrSource)
+	}
+
+	a, _ := c.Endpoints("hosts")
+	b, _ := c.Endpoints("reversed")
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Endpoints() = %q and %q, want the same order", a, b)
+	}
+}
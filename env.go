@@ -0,0 +1,360 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportEnv sets an environment variable for each of the named configuration values, so
+// child processes that are only configured through the environment see the same values.
+// Variables are named by prefix and the value name, converted to upper case with
+// characters other than letters and digits replaced by underscores; for example, with
+// prefix "APP", the value "db-url" is exported as APP_DB_URL.
+//
+// Values whose names end in .json, .yaml or .yml are decoded and flattened instead, with
+// one variable for each scalar they contain, named by the keys and array indices leading to
+// it. For example, with prefix "APP", an app.yaml of:
+//		server:
+//		  hosts: [a, b]
+// is exported as APP_SERVER_HOSTS_0=a and APP_SERVER_HOSTS_1=b. An empty prefix adds no
+// leading underscore. ExportEnv fails without setting anything if two values map to the
+// same variable.
+func (c *Config) ExportEnv(prefix string, names ...string) error {
+	vars, err := c.envVars(prefix, names)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		err := os.Setenv(k, vars[k])
+		if err != nil {
+			return fmt.Errorf("config: failed to set environment variable %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// Environ returns the environment variables that ExportEnv would set for names, in the
+// "key=value" form used by os.Environ and exec.Cmd, sorted by key.
+func (c *Config) Environ(prefix string, names ...string) ([]string, error) {
+	vars, err := c.envVars(prefix, names)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(vars))
+	for k, v := range vars {
+		result = append(result, k+"="+v)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// envVars returns the environment variables that ExportEnv sets for names.
+func (c *Config) envVars(prefix string, names []string) (map[string]string, error) {
+	result := map[string]string{}
+	origin := map[string]string{}
+	add := func(n, k, v string) error {
+		if prev, ok := origin[k]; ok {
+			return fmt.Errorf("config: %s and %s both map to environment variable %s", prev, n, k)
+		}
+		result[k], origin[k] = v, n
+		return nil
+	}
+
+	for _, n := range names {
+		switch strings.ToLower(path.Ext(n)) {
+		case ".json", ".yaml", ".yml":
+			var v interface{}
+			var err error
+			if strings.ToLower(path.Ext(n)) == ".json" {
+				err = c.InterfaceJson(n, &v)
+			} else {
+				err = c.InterfaceYaml(n, &v)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			err = flattenEnv(prefix, v, func(k, s string) error { return add(n, k, s) })
+			if err != nil {
+				return nil, err
+			}
+		default:
+			s, err := c.String(n)
+			if err != nil {
+				return nil, err
+			}
+			err = add(n, envName(prefix, n), s)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// flattenEnv calls fn with the variable name and value of each scalar in v, a decoded JSON
+// or YAML value, naming them by key starting with prefix.
+func flattenEnv(prefix string, v interface{}, fn func(k, s string) error) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			err := flattenEnv(envName(prefix, k), e, fn)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for i, e := range v {
+			err := flattenEnv(envName(prefix, strconv.Itoa(i)), e, fn)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		return fn(prefix, "")
+	case string:
+		return fn(prefix, v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return fn(prefix, string(b))
+}
+
+// envName appends the upper-cased name n to prefix, separated by an underscore.
+func envName(prefix, n string) string {
+	n = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, n)
+	if prefix == "" {
+		return n
+	}
+	return prefix + "_" + n
+}
+
+// WithEnvOverrides adds the Transformer returned by EnvOverrides(n, prefix, delim) to the
+// Config. See WithTransformer.
+func WithEnvOverrides(n, prefix, delim string) Option {
+	return WithTransformer(EnvOverrides(n, prefix, delim))
+}
+
+// EnvOverrides returns a Transformer that overrides keys of the YAML (or JSON)
+// configuration value named n with environment variables, the reverse of ExportEnv.
+// Variables named by prefix and delim followed by a list of keys separated by delim set
+// the nested key they name, converted to lower case, so values are layered over the
+// loaded file with the highest priority. For example, with prefix "APP" and delim "_",
+// APP_SERVER_HTTP_PORT=8080 sets:
+//		server:
+//		  http:
+//		    port: 8080
+// Keys are matched case-insensitively, and missing mappings are created. Values are
+// interpreted like plain YAML scalars, so 8080 is a number and true is a boolean. delim
+// defaults to "_". The environment is read each time the value is loaded, so Reload picks
+// up changes to it. Values whose names end in .json remain JSON. Variables naming an empty
+// key, such as APP__PORT with delim "_", are ignored.
+func EnvOverrides(n, prefix, delim string) Transformer {
+	if delim == "" {
+		delim = "_"
+	}
+	return func(name string, b []byte) ([]byte, error) {
+		if name != n {
+			return b, nil
+		}
+
+		var vars []string
+		for _, kv := range os.Environ() {
+			if strings.HasPrefix(kv, prefix+delim) {
+				vars = append(vars, kv)
+			}
+		}
+		if len(vars) == 0 {
+			return b, nil
+		}
+		sort.Strings(vars)
+
+		var patches []yamlPatch
+		for _, kv := range vars {
+			i := strings.Index(kv, "=")
+			keys := strings.Split(strings.ToLower(kv[len(prefix+delim):i]), strings.ToLower(delim))
+			if hasEmptyKey(keys) {
+				continue
+			}
+			patches = append(patches, yamlPatch{env: kv[:i], keys: keys, value: kv[i+1:]})
+		}
+		return patchYaml(n, b, patches)
+	}
+}
+
+// OverridePrefix is the default for WithOverridePrefix.
+var OverridePrefix = "CONFIG_OVERRIDE"
+
+// WithOverridePrefix sets the prefix of the environment variables that override individual
+// keys of structured configuration values after they are loaded, so operators can hotfix
+// a single setting without editing or remounting files. Variables are named by the prefix,
+// the value name and the keys, separated by double underscores. The value name is
+// converted like ExportEnv does, and both it and the keys are matched case-insensitively.
+// For example,
+//		CONFIG_OVERRIDE__app_yaml__server__port=9090
+// sets server.port of app.yaml to the number 9090. Values are interpreted like plain YAML
+// scalars, and values whose names end in .json remain JSON; see EnvOverrides. Variables
+// that match no value are logged and ignored, and Load fails if a variable matches more
+// than one value, such as app.yaml and app-yaml. It defaults to OverridePrefix; an empty
+// prefix disables overrides.
+func WithOverridePrefix(prefix string) Option {
+	return func(o *options) {
+		o.overridePrefix = prefix
+	}
+}
+
+// overrideKeys applies the key overrides from the environment to the values of result.
+// Values that cannot be patched are handled according to the error policy of s.
+func (s *store) overrideKeys(report *LoadReport, result map[string]entry) error {
+	if s.overridePrefix == "" {
+		return nil
+	}
+
+	patches := map[string][]yamlPatch{}
+	vars := os.Environ()
+	sort.Strings(vars)
+	for _, kv := range vars {
+		if !strings.HasPrefix(kv, s.overridePrefix+"__") {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		parts := strings.Split(strings.ToLower(kv[len(s.overridePrefix+"__"):i]), "__")
+
+		var matches []string
+		for n := range result {
+			if strings.EqualFold(envName("", n), parts[0]) {
+				matches = append(matches, n)
+			}
+		}
+		sort.Strings(matches)
+		if len(matches) > 1 {
+			return errorf(ErrValidation, "config: %s matches both %s and %s", kv[:i], matches[0], matches[1])
+		}
+		if len(matches) == 0 || len(parts) < 2 || hasEmptyKey(parts[1:]) {
+			s.warn(Warning{Kind: WarnOverride, Message: fmt.Sprintf("%s does not match a key of any value", kv[:i])}, true)
+			continue
+		}
+		patches[matches[0]] = append(patches[matches[0]], yamlPatch{env: kv[:i], keys: parts[1:], value: kv[i+1:]})
+	}
+
+	names := make([]string, 0, len(patches))
+	for n := range patches {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		e := result[n]
+		d, err := patchYaml(n, e.data, patches[n])
+		if err != nil {
+			err = s.fileFailed(report, result, n, e, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		e.data = d
+		result[n] = e
+	}
+	return nil
+}
+
+// yamlPatch sets the nested key of a YAML value named by keys to value. env is the
+// environment variable it came from.
+type yamlPatch struct {
+	env   string
+	keys  []string
+	value string
+}
+
+// patchYaml applies patches to b, the data of YAML (or JSON) configuration value n.
+func patchYaml(n string, b []byte, patches []yamlPatch) ([]byte, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	for _, p := range patches {
+		err := setYamlKey(doc.Content[0], p.keys, p.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s: %w", p.env, err)
+		}
+	}
+
+	if strings.ToLower(path.Ext(n)) == ".json" {
+		var v interface{}
+		err := doc.Decode(&v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	}
+	return yaml.Marshal(&doc)
+}
+
+// hasEmptyKey reports whether any of keys is empty.
+func hasEmptyKey(keys []string) bool {
+	for _, k := range keys {
+		if k == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// setYamlKey sets the nested key of mapping node m named by keys to the plain scalar v,
+// creating mappings as needed.
+func setYamlKey(m *yaml.Node, keys []string, v string) error {
+	m = resolveAlias(m)
+	if m.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: %q is not a mapping", m.Line, m.Value)
+	}
+
+	var val *yaml.Node
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if strings.EqualFold(m.Content[i].Value, keys[0]) {
+			val = m.Content[i+1]
+			break
+		}
+	}
+	if val == nil {
+		val = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: keys[0]}, val)
+	}
+
+	if len(keys) > 1 {
+		return setYamlKey(val, keys[1:], v)
+	}
+	*val = yaml.Node{Kind: yaml.ScalarNode, Value: v}
+	return nil
+}
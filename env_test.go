@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_ExportEnv(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db-url":     "postgres://db\n",
+		"app.yaml":   "server:\n  hosts: [a, b]\n  port: 8080\n  tls: true\n  proxy: null\n",
+		"flags.json": `{"beta.search": false}`,
+		"port":       "9090",
+	})
+	c := New(WithPath(dir))
+
+	if err := c.ExportEnv("CFGTEST", "db-url", "app.yaml", "flags.json"); err != nil {
+		t.Fatalf("ExportEnv() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"CFGTEST_DB_URL", "postgres://db"},
+		{"CFGTEST_SERVER_HOSTS_0", "a"},
+		{"CFGTEST_SERVER_HOSTS_1", "b"},
+		{"CFGTEST_SERVER_PORT", "8080"},
+		{"CFGTEST_SERVER_TLS", "true"},
+		{"CFGTEST_SERVER_PROXY", ""},
+		{"CFGTEST_BETA_SEARCH", "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Unsetenv(tt.name)
+			got, ok := os.LookupEnv(tt.name)
+			if !ok || got != tt.want {
+				t.Errorf("Getenv() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+
+	if err := c.ExportEnv("CFGTEST", "missing"); err == nil {
+		t.Errorf("ExportEnv() error = %v, wantErr %v", err, true)
+	}
+
+	dup := writeFiles(t, map[string]string{"server.json": `{"port": 1, "host": "h"}`, "port": "2"})
+	err := New(WithPath(dup)).ExportEnv("CFGTEST_DUP", "server.json", "port")
+	if err == nil {
+		t.Errorf("ExportEnv() with colliding names error = %v, wantErr %v", err, true)
+	}
+	if _, ok := os.LookupEnv("CFGTEST_DUP_HOST"); ok {
+		t.Errorf("ExportEnv() set variables despite failing")
+	}
+}
+
+func TestWithEnvOverrides(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml":   "server:\n  Host: localhost\n  http:\n    port: 80\n",
+		"app.json":   `{"server": {"host": "localhost"}}`,
+		"other.yaml": "server:\n  host: other\n",
+		"upper.JSON": `{"a": 1}`,
+	})
+	for k, v := range map[string]string{
+		"CFGOVR_SERVER_HTTP_PORT": "8080",
+		"CFGOVR_SERVER_HOST":      "example.com",
+		"CFGOVR_DEBUG":            "true",
+		"CFGJSON__LEVEL":          "warn",
+		"CFGEMPTY__LEVEL":         "warn",
+		"CFGEMPTY_DEBUG":          "true",
+		"CFGUPPER_B":              "2",
+	} {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Unsetenv(k)
+	}
+
+	tests := []struct {
+		name   string
+		n      string
+		prefix string
+		delim  string
+		decode func(c *Config, v interface{}) error
+		want   map[string]interface{}
+	}{
+		{
+			name:   "yaml",
+			n:      "app.yaml",
+			prefix: "CFGOVR",
+			decode: func(c *Config, v interface{}) error { return c.InterfaceYaml("app.yaml", v) },
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"Host": "example.com", "http": map[string]interface{}{"port": 8080}},
+				"debug":  true,
+			},
+		},
+		{
+			name:   "json",
+			n:      "app.json",
+			prefix: "CFGJSON",
+			delim:  "__",
+			decode: func(c *Config, v interface{}) error { return c.InterfaceJson("app.json", v) },
+			want:   map[string]interface{}{"server": map[string]interface{}{"host": "localhost"}, "level": "warn"},
+		},
+		{
+			name:   "json extension case",
+			n:      "upper.JSON",
+			prefix: "CFGUPPER",
+			decode: func(c *Config, v interface{}) error { return c.InterfaceJson("upper.JSON", v) },
+			want:   map[string]interface{}{"a": 1.0, "b": 2.0},
+		},
+		{
+			name:   "other values",
+			n:      "app.yaml",
+			prefix: "CFGOVR",
+			decode: func(c *Config, v interface{}) error { return c.InterfaceYaml("other.yaml", v) },
+			want:   map[string]interface{}{"server": map[string]interface{}{"host": "other"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithPath(dir), WithEnvOverrides(tt.n, tt.prefix, tt.delim))
+			var got map[string]interface{}
+			if err := tt.decode(c, &got); err != nil {
+				t.Fatalf("decode error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := EnvOverrides("app.yaml", "CFGEMPTY", "_")("app.yaml", nil); err != nil || string(got) != "debug: true\n" {
+		t.Errorf("EnvOverrides() = %q, %v, want %q with the empty key skipped", got, err, "debug: true\n")
+	}
+}
+
+func TestWithOverridePrefix(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml":  "server:\n  port: 8080\n  host: localhost\n",
+		"db.json":   `{"pool": {"size": 5}}`,
+		"plain.txt": "text",
+	})
+	for k, v := range map[string]string{
+		"CFGKEY__APP_YAML__SERVER__PORT": "9090",
+		"CFGKEY__db_json__pool__size":    "10",
+		"CFGKEY__missing__key":           "x",
+	} {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Unsetenv(k)
+	}
+
+	c := New(WithPath(dir), WithOverridePrefix("CFGKEY"))
+	var app map[string]map[string]interface{}
+	if err := c.InterfaceYaml("app.yaml", &app); err != nil {
+		t.Fatalf("InterfaceYaml() error = %v", err)
+	}
+	if want := map[string]interface{}{"port": 9090, "host": "localhost"}; !reflect.DeepEqual(app["server"], want) {
+		t.Errorf("InterfaceYaml() server = %v, want %v", app["server"], want)
+	}
+	if got, err := c.RawJson("db.json", "pool.size"); err != nil || string(got) != "10" {
+		t.Errorf("RawJson() = %s, %v, want %s", got, err, "10")
+	}
+
+	if got, err := New(WithPath(dir), WithOverridePrefix("")).RawJson("db.json", "pool.size"); err != nil || string(got) != "5" {
+		t.Errorf("RawJson() without overrides = %s, %v, want %s", got, err, "5")
+	}
+
+	if err := os.Setenv("CFGKEY__plain_txt__key", "x"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("CFGKEY__plain_txt__key")
+	var fe *FileError
+	if err := New(WithPath(dir), WithOverridePrefix("CFGKEY")).Load(); !errors.As(err, &fe) || fe.Name != "plain.txt" {
+		t.Errorf("Load() error = %v, want %T for plain.txt", err, fe)
+	}
+
+	dir = writeFiles(t, map[string]string{"app.yaml": "port: 1\n", "app-yaml": "port: 2\n"})
+	if err := New(WithPath(dir), WithOverridePrefix("CFGKEY")).Load(); !errors.Is(err, ErrValidation) {
+		t.Errorf("Load() error = %v, want %v for an ambiguous override", err, ErrValidation)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The classes of errors returned by this package. Every error returned by a Config or a
+// Source matches at most one of them with errors.Is, and the typed errors it may wrap
+// (such as *NotFoundError or *DecodeError) can be retrieved with errors.As, so callers can
+// branch on the kind of failure without matching messages:
+//
+//		port, err := c.Port("port")
+//		switch {
+//		case errors.Is(err, config.ErrNotFound):
+//			port = 8080
+//		case err != nil:
+//			return err
+//		}
+//
+// Errors that wrap a load error, such as an accessor called on a Config that failed to
+// load, match the class of the load error. Messages are not part of the API and may change.
+var (
+	// ErrNotFound is matched by errors for configuration values, or parts of them, that do
+	// not exist, such as *NotFoundError. These errors also match os.ErrNotExist.
+	ErrNotFound = errors.New("config: not found")
+
+	// ErrDecode is matched by errors for values that cannot be converted to the requested
+	// type, such as *DecodeError, a malformed number or an unparsable template.
+	ErrDecode = errors.New("config: cannot decode value")
+
+	// ErrDuplicate is matched by *DuplicateError.
+	ErrDuplicate = errors.New("config: duplicate value")
+
+	// ErrSource is matched by errors reading the search path or a Source, such as
+	// *SourceError, *FileError, *ManifestError and *RemovedError.
+	ErrSource = errors.New("config: cannot read source")
+
+	// ErrValidation is matched by errors for values that decode but are not allowed, such
+	// as *ValidationError, *ExpiryError, values outside the allowed range, and settings
+	// rejected by the structured accessors.
+	ErrValidation = errors.New("config: invalid value")
+)
+
+// SourceError is returned by Load when a search path entry or Source cannot be read.
+type SourceError struct {
+	Source string // Source is the search path entry, or a description of the Source.
+	Err    error  // Err is the error returned by the Source.
+}
+
+func (e *SourceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SourceError) Is(target error) bool {
+	return target == ErrSource
+}
+
+// classError is an error that also matches class. See errorf.
+type classError struct {
+	class error
+	err   error
+}
+
+// errorf is like fmt.Errorf, but the result also matches class with errors.Is.
+func errorf(class error, format string, a ...interface{}) error {
+	return &classError{class: class, err: fmt.Errorf(format, a...)}
+}
+
+func (e *classError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classError) Unwrap() error {
+	return e.err
+}
+
+func (e *classError) Is(target error) bool {
+	return target == e.class
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorClasses(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"port":        "http",
+		"big_port":    "70000",
+		"app.yaml":    "a: [1\n",
+		"limits.json": `{"a": 1}`,
+		"pool.yaml":   "maxOpen: 1\nmaxIdle: 2\n",
+	})
+	dup := writeFiles(t, map[string]string{"a/name": "1", "b/name": "2"})
+	c := New(WithPath(dir))
+
+	classes := []error{ErrNotFound, ErrDecode, ErrDuplicate, ErrSource, ErrValidation}
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{name: "missing value", err: func() error { _, err := c.String("missing"); return err }, want: ErrNotFound},
+		{name: "missing path", err: func() error { _, err := c.RawJson("limits.json", "b"); return err }, want: ErrNotFound},
+		{name: "first of", err: func() error { _, err := c.FirstOf("x", "y"); return err }, want: ErrNotFound},
+		{name: "malformed int", err: func() error { _, err := c.Int("port"); return err }, want: ErrDecode},
+		{name: "malformed yaml", err: func() error { var v interface{}; return c.InterfaceYaml("app.yaml", &v) }, want: ErrDecode},
+		{name: "out of range", err: func() error { _, err := c.Port("big_port"); return err }, want: ErrValidation},
+		{name: "not allowed", err: func() error { _, err := c.Enum("port", "https"); return err }, want: ErrValidation},
+		{name: "settings", err: func() error { _, err := c.PoolSettings("pool.yaml"); return err }, want: ErrValidation},
+		{name: "required", err: func() error { return New(WithPath(dir), WithRequired("missing")).Load() }, want: ErrValidation},
+		{name: "duplicate", err: func() error {
+			return New(WithPath(filepath.Join(dup, "a") + string(filepath.ListSeparator) + filepath.Join(dup, "b"))).Load()
+		}, want: ErrDuplicate},
+		{name: "unreadable source", err: func() error { return New(WithPath(filepath.Join(dir, "missing"))).Load() }, want: ErrSource},
+		{name: "load error", err: func() error { _, err := New(WithPath(filepath.Join(dir, "missing"))).String("port"); return err }, want: ErrSource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			if err == nil {
+				t.Fatal("error = nil")
+			}
+			for _, class := range classes {
+				if got := errors.Is(err, class); got != (class == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, class, got)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorClasses_preserved(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"port": "http"})
+	c := New(WithPath(dir))
+
+	_, err := c.String("missing")
+	var nf *NotFoundError
+	if !errors.As(err, &nf) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("String() error = %v, want a *NotFoundError wrapping os.ErrNotExist", err)
+	}
+
+	_, err = c.Int("port")
+	if got, want := err.Error(), `config: failed to unmarshal port into int: strconv.Atoi: parsing "http": invalid syntax`; got != want {
+		t.Errorf("Int() error = %q, want %q", got, want)
+	}
+
+	err = New(WithPath(filepath.Join(dir, "missing"))).Load()
+	var se *SourceError
+	if !errors.As(err, &se) || se.Source != filepath.Join(dir, "missing") || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %v, want a *SourceError wrapping os.ErrNotExist", err)
+	}
+}
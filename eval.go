@@ -0,0 +1,585 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EvalPrefix starts string values that Eval treats as expressions rather than strings.
+const EvalPrefix = "="
+
+// Eval evaluates expression expr over the YAML (or JSON) configuration value n. Identifiers
+// in expr refer to the keys of the mapping found at path in n, which is a dot-separated
+// list of keys and array indices as in RawJson; an empty path is the whole document.
+// Identifiers may themselves be dotted paths into nested values (e.g. "pool.size").
+// The identifier cpus refers to the number of CPUs unless the mapping defines it.
+//
+// Expressions support numbers, quoted strings, true and false, parentheses, arithmetic
+// (+ - * / %), string concatenation with +, comparisons (== != < <= > >=), logical
+// operators (&& || !) and the ternary operator (cond ? a : b). Numbers are float64. Only
+// the branch of a ternary that is taken is evaluated, and the right operands of && and ||
+// only if the left ones do not decide the result.
+//
+// String values starting with EvalPrefix are evaluated as expressions when referenced, so
+// derived settings can be declared in configuration rather than code:
+//		cpus: 4
+//		maxConns: "= cpus * 4"
+//		mode: "= maxConns > 8 ? 'pooled' : 'single'"
+// Here Eval("pool.yaml", "", "maxConns") returns 16.0.
+func (c *Config) Eval(n, path, expr string) (interface{}, error) {
+	var doc interface{}
+	err := c.InterfaceYaml(n, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := evalScope(doc, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errorf(ErrNotFound, "config: %s has no value at %q: %w", n, path, err)
+	}
+	if err != nil {
+		return nil, errorf(ErrDecode, "config: %s has no mapping at %q: %w", n, path, err)
+	}
+
+	ev := &evaluator{scope: scope, visiting: map[string]bool{}}
+	v, err := ev.eval(expr)
+	if err != nil {
+		return nil, errorf(ErrDecode, "config: failed to evaluate %q in %s: %w", expr, n, err)
+	}
+	return v, nil
+}
+
+// EvalInt calls c.Eval() and returns the result as an int. It fails if the result is not
+// a whole number that fits in an int.
+func (c *Config) EvalInt(n, path, expr string) (int, error) {
+	v, err := c.Eval(n, path, expr)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) || float64(int(f)) != f {
+		return 0, errorf(ErrDecode, "config: %q in %s is %v, not an integer", expr, n, v)
+	}
+	return int(f), nil
+}
+
+// evalScope returns the mapping found at path in doc.
+func evalScope(doc interface{}, path string) (map[string]interface{}, error) {
+	if path != "" {
+		var ok bool
+		doc, ok = evalLookup(doc, path)
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+	}
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value is a %T", doc)
+	}
+	return m, nil
+}
+
+// evalLookup returns the value found at dotted path in v.
+func evalLookup(v interface{}, path string) (interface{}, bool) {
+	for _, k := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			v, ok = t[k]
+			if !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// evaluator evaluates expressions over a scope. visiting holds the identifiers whose
+// expressions are being evaluated, to detect cycles.
+type evaluator struct {
+	scope    map[string]interface{}
+	visiting map[string]bool
+}
+
+// eval parses and evaluates expr.
+func (ev *evaluator) eval(expr string) (interface{}, error) {
+	x, err := parseExpr(expr, "_.")
+	if err != nil {
+		return nil, err
+	}
+	return ev.evalNode(x)
+}
+
+// resolve returns the value of identifier id, evaluating it if it is an expression.
+func (ev *evaluator) resolve(id string) (interface{}, error) {
+	v, ok := evalLookup(ev.scope, id)
+	if !ok {
+		if id == "cpus" {
+			return float64(runtime.NumCPU()), nil
+		}
+		return nil, fmt.Errorf("undefined identifier %q", id)
+	}
+
+	switch t := v.(type) {
+	case string:
+		if !strings.HasPrefix(t, EvalPrefix) {
+			return t, nil
+		}
+		if ev.visiting[id] {
+			return nil, fmt.Errorf("expression cycle through %q", id)
+		}
+		ev.visiting[id] = true
+		defer delete(ev.visiting, id)
+		v, err := ev.eval(strings.TrimPrefix(t, EvalPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		return v, nil
+	case int:
+		return float64(t), nil
+	case int64:
+		return float64(t), nil
+	case uint64:
+		return float64(t), nil
+	case float64, bool:
+		return t, nil
+	}
+	return nil, fmt.Errorf("%q is a %T, not a number, string or bool", id, v)
+}
+
+// evalNode evaluates x. Only the branch of a ternary that is taken is evaluated.
+func (ev *evaluator) evalNode(x exprNode) (interface{}, error) {
+	switch x := x.(type) {
+	case exprLiteral:
+		switch x.kind {
+		case tokNumber:
+			f, err := strconv.ParseFloat(x.text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", x.text)
+			}
+			return f, nil
+		case tokString:
+			return x.text, nil
+		}
+		return x.text == "true", nil
+	case exprIdent:
+		return ev.resolve(x.name)
+	case exprUnary:
+		v, err := ev.evalNode(x.x)
+		if err != nil {
+			return nil, err
+		}
+		if x.op == "-" {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, fmt.Errorf("-%v: operand must be a number", v)
+			}
+			return -f, nil
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("!%v: operand must be a bool", v)
+		}
+		return !b, nil
+	case exprTernary:
+		cond, err := ev.evalNode(x.cond)
+		if err != nil {
+			return nil, err
+		}
+		t, ok := cond.(bool)
+		if !ok {
+			return nil, fmt.Errorf("ternary condition is %v, not a bool", cond)
+		}
+		if t {
+			return ev.evalNode(x.a)
+		}
+		return ev.evalNode(x.b)
+	case exprBinary:
+		if x.op == "&&" || x.op == "||" {
+			return ev.logical(x)
+		}
+		v, err := ev.evalNode(x.x)
+		if err != nil {
+			return nil, err
+		}
+		w, err := ev.evalNode(x.y)
+		if err != nil {
+			return nil, err
+		}
+		return applyOp(v, x.op, w)
+	}
+	return nil, fmt.Errorf("unexpected expression %T", x)
+}
+
+// logical evaluates x, an && or || operation. Its right operand is evaluated only if the
+// left one does not decide the result.
+func (ev *evaluator) logical(x exprBinary) (interface{}, error) {
+	v, err := ev.evalNode(x.x)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%v %s: operands must be bools", v, x.op)
+	}
+	if a == (x.op == "||") {
+		return a, nil
+	}
+	w, err := ev.evalNode(x.y)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := w.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%v %s %v: operands must be bools", v, x.op, w)
+	}
+	return b, nil
+}
+
+// applyOp applies binary operator op, other than && and ||, to v and w.
+func applyOp(v interface{}, op string, w interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return v == w, nil
+	case "!=":
+		return v != w, nil
+	case "<", "<=", ">", ">=":
+		return compare(v, op, w)
+	case "+":
+		_, s1 := v.(string)
+		_, s2 := w.(string)
+		if s1 || s2 {
+			return evalString(v) + evalString(w), nil
+		}
+		return arithmetic(v, op, w)
+	case "-", "*", "/", "%":
+		return arithmetic(v, op, w)
+	}
+	return nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+// compare applies ordering operator op to v and w.
+func compare(v interface{}, op string, w interface{}) (interface{}, error) {
+	var cmp int
+	switch a := v.(type) {
+	case float64:
+		b, ok := w.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%v %s %v: mismatched types", v, op, w)
+		}
+		cmp = compareFloats(a, b)
+	case string:
+		b, ok := w.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v %s %v: mismatched types", v, op, w)
+		}
+		cmp = strings.Compare(a, b)
+	default:
+		return nil, fmt.Errorf("%v %s %v: operands must be numbers or strings", v, op, w)
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	}
+	return cmp >= 0, nil
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// arithmetic applies numeric operator op to v and w.
+func arithmetic(v interface{}, op string, w interface{}) (interface{}, error) {
+	a, ok1 := v.(float64)
+	b, ok2 := w.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%v %s %v: operands must be numbers", v, op, w)
+	}
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	}
+	if b == 0 {
+		return nil, fmt.Errorf("%v %s %v: division by zero", v, op, w)
+	}
+	if op == "/" {
+		return a / b, nil
+	}
+	return math.Mod(a, b), nil
+}
+
+// evalString formats v for string concatenation.
+func evalString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// token kinds of expressions.
+const (
+	tokNumber = iota
+	tokString
+	tokIdent
+	tokOp
+)
+
+type evalToken struct {
+	kind int
+	text string
+}
+
+// evalOps are the operators of expressions, longest first.
+var evalOps = []string{"==", "!=", "=~", "<=", ">=", "&&", "||", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", "(", ")"}
+
+// tokenize splits expr into tokens. Identifiers may contain letters, digits and the
+// characters in identPunct.
+func tokenize(expr, identPunct string) ([]evalToken, error) {
+	var result []evalToken
+	for i := 0; i < len(expr); {
+		r := rune(expr[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r >= '0' && r <= '9' || r == '.':
+			j := i
+			for j < len(expr) && (expr[j] >= '0' && expr[j] <= '9' || expr[j] == '.' || expr[j] == 'e' || expr[j] == 'E' ||
+				(expr[j] == '+' || expr[j] == '-') && (expr[j-1] == 'e' || expr[j-1] == 'E')) {
+				j++
+			}
+			result = append(result, evalToken{kind: tokNumber, text: expr[i:j]})
+			i = j
+		case r == '"' || r == '\'':
+			j := strings.IndexByte(expr[i+1:], expr[i])
+			if j < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			result = append(result, evalToken{kind: tokString, text: expr[i+1 : i+1+j]})
+			i += j + 2
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(expr) && (strings.IndexByte(identPunct, expr[j]) >= 0 || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			result = append(result, evalToken{kind: tokIdent, text: expr[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range evalOps {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q", r)
+			}
+			result = append(result, evalToken{kind: tokOp, text: op})
+			i += len(op)
+		}
+	}
+	return result, nil
+}
+
+// exprNode is a parsed expression: an exprLiteral, exprIdent, exprUnary, exprBinary or
+// exprTernary. Eval expressions and WithConditions conditions share this syntax, and
+// differ in how they are evaluated.
+type exprNode interface{}
+
+// exprLiteral is a number, a string, or true or false, whose kind is tokIdent.
+type exprLiteral evalToken
+
+type exprIdent struct {
+	name string
+}
+
+type exprUnary struct {
+	op string
+	x  exprNode
+}
+
+type exprBinary struct {
+	op   string
+	x, y exprNode
+}
+
+type exprTernary struct {
+	cond, a, b exprNode
+}
+
+// parseExpr parses expr. Identifiers may contain letters, digits and the characters in
+// identPunct.
+func parseExpr(expr, identPunct string) (exprNode, error) {
+	toks, err := tokenize(expr, identPunct)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	x, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	return x, nil
+}
+
+// exprParser is a recursive descent parser of expressions.
+type exprParser struct {
+	toks []evalToken
+	pos  int
+}
+
+// accept consumes the next token if it is one of ops, returning it.
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.toks[p.pos].text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) ternary() (exprNode, error) {
+	cond, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	a, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept(":"); !ok {
+		return nil, errors.New(`missing ":" in ternary`)
+	}
+	b, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return exprTernary{cond: cond, a: a, b: b}, nil
+}
+
+func (p *exprParser) or() (exprNode, error) {
+	return p.binary(p.and, "||")
+}
+
+func (p *exprParser) and() (exprNode, error) {
+	return p.binary(p.comparison, "&&")
+}
+
+func (p *exprParser) comparison() (exprNode, error) {
+	x, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "=~", "<=", ">=", "<", ">")
+	if !ok {
+		return x, nil
+	}
+	y, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	return exprBinary{op: op, x: x, y: y}, nil
+}
+
+func (p *exprParser) additive() (exprNode, error) {
+	return p.binary(p.multiplicative, "+", "-")
+}
+
+func (p *exprParser) multiplicative() (exprNode, error) {
+	return p.binary(p.unary, "*", "/", "%")
+}
+
+// binary parses operands joined by any of ops with next, associating to the left.
+func (p *exprParser) binary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	x, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return x, nil
+		}
+		y, err := next()
+		if err != nil {
+			return nil, err
+		}
+		x = exprBinary{op: op, x: x, y: y}
+	}
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	op, ok := p.accept("-", "!")
+	if !ok {
+		return p.primary()
+	}
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return exprUnary{op: op, x: x}, nil
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	if p.pos >= len(p.toks) {
+		return nil, errors.New("unexpected end of expression")
+	}
+	if _, ok := p.accept("("); ok {
+		x, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, errors.New(`missing ")"`)
+		}
+		return x, nil
+	}
+
+	t := p.toks[p.pos]
+	p.pos++
+	switch {
+	case t.kind == tokNumber, t.kind == tokString, t.text == "true", t.text == "false":
+		return exprLiteral(t), nil
+	case t.kind == tokIdent:
+		return exprIdent{name: t.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestConfig_Eval(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"pool.yaml": `
+cpus: 4
+idle: 0
+maxConns: "= cpus * 4"
+mode: "= maxConns > 8 ? 'pooled' : 'single'"
+name: db
+ratio: 0.5
+enabled: true
+loop: "= loop + 1"
+broken: "= 1 +"
+servers:
+  - host: a
+    port: 8080
+    tls: "= port == 443"
+`,
+		"limits.json": `{"base": 10, "nested": {"factor": 3}}`,
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		n       string
+		path    string
+		expr    string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "identifier", n: "pool.yaml", expr: "cpus", want: 4.0},
+		{name: "derived", n: "pool.yaml", expr: "maxConns", want: 16.0},
+		{name: "ternary", n: "pool.yaml", expr: "mode", want: "pooled"},
+		{name: "precedence", n: "pool.yaml", expr: "1 + 2 * 3 - (4 - 2) / 2", want: 6.0},
+		{name: "modulo", n: "pool.yaml", expr: "-7 % 3", want: -1.0},
+		{name: "concat", n: "pool.yaml", expr: "name + '-' + cpus", want: "db-4"},
+		{name: "logic", n: "pool.yaml", expr: "enabled && !(ratio > 1) || false", want: true},
+		{name: "path", n: "pool.yaml", path: "servers.0", expr: "host + ':' + port", want: "a:8080"},
+		{name: "nested expression", n: "pool.yaml", path: "servers.0", expr: "tls ? 'https' : 'http'", want: "http"},
+		{name: "guarded division", n: "pool.yaml", expr: "idle > 0 ? 10 / idle : 0", want: 0.0},
+		{name: "untaken branch", n: "pool.yaml", expr: "cpus > 8 ? missing : cpus", want: 4.0},
+		{name: "short-circuit and", n: "pool.yaml", expr: "false && missing", want: false},
+		{name: "short-circuit or", n: "pool.yaml", expr: "enabled || missing", want: true},
+		{name: "json", n: "limits.json", expr: "base * nested.factor", want: 30.0},
+		{name: "builtin cpus", n: "limits.json", expr: "cpus", want: float64(runtime.NumCPU())},
+		{name: "undefined", n: "pool.yaml", expr: "missing * 2", wantErr: true},
+		{name: "cycle", n: "pool.yaml", expr: "loop", wantErr: true},
+		{name: "syntax", n: "pool.yaml", expr: "broken", wantErr: true},
+		{name: "division by zero", n: "pool.yaml", expr: "cpus / 0", wantErr: true},
+		{name: "logical type", n: "pool.yaml", expr: "cpus && true", wantErr: true},
+		{name: "type mismatch", n: "pool.yaml", expr: "name * 2", wantErr: true},
+		{name: "trailing", n: "pool.yaml", expr: "1 2", wantErr: true},
+		{name: "not a mapping", n: "pool.yaml", path: "servers", expr: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Eval(tt.n, tt.path, tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Eval() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := c.Eval("pool.yaml", "servers.1", "1"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Eval() missing path error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestConfig_EvalInt(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"pool.yaml": "cpus: 4\nmaxConns: \"= cpus * 4\"\n"})
+	c := New(WithPath(dir))
+
+	if got, err := c.EvalInt("pool.yaml", "", "maxConns"); err != nil || got != 16 {
+		t.Errorf("EvalInt() = %v, %v, want %v", got, err, 16)
+	}
+	if got, err := c.EvalInt("pool.yaml", "", "maxConns * 1000000000"); err != nil || got != 16000000000 {
+		t.Errorf("EvalInt() = %v, %v, want %v", got, err, 16000000000)
+	}
+	for _, expr := range []string{"cpus / 3", "'x'"} {
+		if _, err := c.EvalInt("pool.yaml", "", expr); err == nil {
+			t.Errorf("EvalInt(%q) error = nil", expr)
+		}
+	}
+}
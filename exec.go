@@ -0,0 +1,173 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+func init() {
+	pathSources["exec"] = newExecSource
+	opaquePathSources["exec"] = true
+}
+
+// DefaultExecTimeout is how long an ExecSource command may run if its Timeout is not set.
+const DefaultExecTimeout = 30 * time.Second
+
+// ExecSource is a Source that runs a command and provides the configuration values it
+// writes to standard output. The output is either a JSON object, whose members are the
+// values (strings are used as-is, anything else as JSON), or a tar archive, whose regular
+// files are the values named by their path in the archive. Anything written to standard
+// error is included in the error if the command fails.
+//
+// Search path entries of the form "exec:./fetch-config.sh" are read with an ExecSource.
+// The command is split into arguments on spaces, which may be URL-encoded. The "timeout"
+// query parameter sets Timeout, and the "env" query parameter is a comma separated list
+// of the environment variables to pass to the command. For example:
+//
+//		exec:./fetch-config.sh%20--env%20prod?timeout=10s&env=HOME,VAULT_TOKEN
+type ExecSource struct {
+	Command string
+	Args    []string
+
+	// Dir is the working directory of the command. It defaults to the current directory.
+	Dir string
+	// Env is the environment of the command. If it is nil, the command inherits the
+	// environment of the process.
+	Env []string
+	// Timeout is how long the command may run before it is killed. It defaults to
+	// DefaultExecTimeout.
+	Timeout time.Duration
+}
+
+// Exec returns an ExecSource that runs command with args.
+func Exec(command string, args ...string) *ExecSource {
+	return &ExecSource{Command: command, Args: args}
+}
+
+func newExecSource(u *url.URL) (Source, error) {
+	// Absolute commands (e.g. "exec:/usr/bin/fetch") are parsed as paths rather than
+	// opaque data.
+	var err error
+	cmd := u.Path
+	if u.Opaque != "" {
+		cmd, err = url.PathUnescape(u.Opaque)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid exec search path entry %q: %w", u.String(), err)
+		}
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("config: exec search path entry %q has no command", u.String())
+	}
+	s := Exec(fields[0], fields[1:]...)
+
+	q := u.Query()
+	if t := q.Get("timeout"); t != "" {
+		s.Timeout, err = time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid timeout in exec search path entry %q: %w", u.String(), err)
+		}
+	}
+	if _, ok := q["env"]; ok {
+		s.Env = []string{}
+		for _, n := range strings.Split(q.Get("env"), ",") {
+			if v, ok := os.LookupEnv(n); ok && n != "" {
+				s.Env = append(s.Env, n+"="+v)
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *ExecSource) String() string {
+	return "exec:" + strings.Join(append([]string{s.Command}, s.Args...), " ")
+}
+
+func (s *ExecSource) Files(ctx context.Context) ([]File, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.Command, s.Args...)
+	cmd.Dir = s.Dir
+	cmd.Env = s.Env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("config: %s timed out after %v", s, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: %s failed: %w: %s", s, err, strings.TrimSpace(stderr.String()))
+	}
+
+	fs, err := parseExecOutput(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to parse output of %s: %w", s, err)
+	}
+	for i := range fs {
+		fs[i].Path = s.String() + "#" + fs[i].Name
+	}
+	return fs, nil
+}
+
+// parseExecOutput parses b, the output of an ExecSource command, as a JSON object or a
+// tar archive.
+func parseExecOutput(b []byte) ([]File, error) {
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '{' {
+		var m map[string]json.RawMessage
+		err := json.Unmarshal(t, &m)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]File, 0, len(m))
+		for n, v := range m {
+			d := []byte(v)
+			var s string
+			if json.Unmarshal(v, &s) == nil {
+				d = []byte(s)
+			}
+			result = append(result, File{Name: n, Data: d})
+		}
+		return result, nil
+	}
+
+	var result []File
+	r := tar.NewReader(bytes.NewReader(b))
+	for {
+		h, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		d, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, File{Name: strings.TrimPrefix(path.Clean(h.Name), "/"), Data: d})
+	}
+}
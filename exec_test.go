@@ -0,0 +1,95 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeScript(t *testing.T, script string) string {
+	dir := writeFiles(t, map[string]string{"script.sh": "#!/bin/sh\n" + script})
+	p := filepath.Join(dir, "script.sh")
+	if err := os.Chmod(p, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestExecSource_pathEntry(t *testing.T) {
+	script := writeScript(t, `printf '{"name": "%s-%s", "port": 8080, "secret": "%s"}' "$1" "$CONFIG_TEST_EXEC" "$CONFIG_TEST_EXEC_HIDDEN"`)
+	os.Setenv("CONFIG_TEST_EXEC", "visible")
+	os.Setenv("CONFIG_TEST_EXEC_HIDDEN", "hidden")
+	defer os.Unsetenv("CONFIG_TEST_EXEC")
+	defer os.Unsetenv("CONFIG_TEST_EXEC_HIDDEN")
+
+	dir := writeFiles(t, map[string]string{"other": "other"})
+	p := strings.Join([]string{dir, "exec:" + script + "%20prod?timeout=5s&env=CONFIG_TEST_EXEC"}, string(os.PathListSeparator))
+	c := New(WithPath(p))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "name", want: "prod-visible"},
+		{name: "port", want: "8080"},
+		{name: "secret", want: ""},
+		{name: "other", want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.String(tt.name)
+			if err != nil || got != tt.want {
+				t.Errorf("String() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecSource_tar(t *testing.T) {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for n, d := range map[string]string{"./name": "tar", "sub/value": "nested"} {
+		if err := w.WriteHeader(&tar.Header{Name: n, Mode: 0644, Size: int64(len(d)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(d)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := writeFiles(t, map[string]string{"config.tar": buf.String()})
+
+	c := New(WithPath(""), WithSource(Exec("cat", filepath.Join(archive, "config.tar"))))
+	if got, err := c.String("name"); err != nil || got != "tar" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "tar")
+	}
+	if got, err := c.String("sub/value"); err != nil || got != "nested" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "nested")
+	}
+}
+
+func TestExecSource_error(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     *ExecSource
+		wantErr string
+	}{
+		{name: "failure", src: Exec(writeScript(t, "echo broken >&2; exit 3")), wantErr: "broken"},
+		{name: "timeout", src: &ExecSource{Command: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond}, wantErr: "timed out"},
+		{name: "output", src: Exec("echo", "{not json"), wantErr: "failed to parse output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(WithPath(""), WithSource(tt.src)).Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MetaSuffix is the suffix of sidecar files holding metadata about the configuration value
+// named by the rest of their name, such as "tls.crt.meta" for "tls.crt".
+const MetaSuffix = ".meta"
+
+// DefaultExpiryWarning is how long before a configuration value expires that a warning is
+// logged. See WithExpiryWarning.
+const DefaultExpiryWarning = 30 * 24 * time.Hour
+
+// timeNow returns the current time. It is a variable so tests can replace it.
+var timeNow = time.Now
+
+// WithExpiryWarning sets how long before a configuration value expires that a warning is
+// logged when it is loaded. A negative d disables the warnings. See Expiries.
+func WithExpiryWarning(d time.Duration) Option {
+	return func(o *options) {
+		o.expiryWarning = d
+	}
+}
+
+// Expiry is the expiry date of a configuration value.
+type Expiry struct {
+	Name    string    // Name is the name of the configuration value.
+	Path    string    // Path is the file the configuration value was read from.
+	Expires time.Time // Expires is when the configuration value expires.
+}
+
+// Expired reports whether e has expired at t.
+func (e Expiry) Expired(t time.Time) bool {
+	return !t.Before(e.Expires)
+}
+
+// ExpiryError is returned by Health when configuration values have expired.
+type ExpiryError struct {
+	Expired []Expiry // Expired are the expired values, soonest first.
+}
+
+func (e *ExpiryError) Error() string {
+	names := make([]string, len(e.Expired))
+	for i, x := range e.Expired {
+		names[i] = fmt.Sprintf("%s (%s)", x.Name, x.Expires.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("config: expired config entries: %s", strings.Join(names, ", "))
+}
+
+func (e *ExpiryError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Expiries calls c.Load() then returns the expiry dates of the configuration values that
+// declare one, soonest first. A value declares an expiry date, for example because it is a
+// certificate or a token that must be rotated, either in a sidecar file named after it
+// with MetaSuffix appended, holding a yaml object such as
+//		expires: 2026-12-01
+//
+// or in a comment at the top of the value itself:
+//		# expires: 2026-12-01T12:00:00Z
+//		-----BEGIN CERTIFICATE-----
+//
+// Dates are in RFC 3339 format, or dates alone, which are taken as midnight UTC. Sidecar
+// files are not loaded as values of their own. Values that expire within the warning
+// period set by WithExpiryWarning are logged each time they are loaded.
+func (c *Config) Expiries() ([]Expiry, error) {
+	err := c.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	var result []Expiry
+	for _, x := range expiries(c.s.val) {
+		if strings.HasPrefix(x.Name, c.prefix) {
+			x.Name = strings.TrimPrefix(x.Name, c.prefix)
+			result = append(result, x)
+		}
+	}
+	return result, nil
+}
+
+// Health calls c.Load() and returns its error, if any. Otherwise, it returns an
+// *ExpiryError if any configuration values have expired. See Expiries.
+func (c *Config) Health() error {
+	xs, err := c.Expiries()
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	var expired []Expiry
+	for _, x := range xs {
+		if x.Expired(now) {
+			expired = append(expired, x)
+		}
+	}
+	if len(expired) > 0 {
+		return &ExpiryError{Expired: expired}
+	}
+	return nil
+}
+
+// annotateExpiry sets the expiry dates of the values in result from their sidecar files
+// and header comments, and removes the sidecar files.
+func (s *store) annotateExpiry(report *LoadReport, result map[string]entry) error {
+	for n, meta := range result {
+		if !strings.HasSuffix(n, MetaSuffix) {
+			continue
+		}
+		target := strings.TrimSuffix(n, MetaSuffix)
+		e, ok := result[target]
+		if !ok {
+			continue
+		}
+
+		var raw struct {
+			Expires string `yaml:"expires"`
+		}
+		err := yaml.Unmarshal(meta.data, &raw)
+		if err != nil {
+			return fmt.Errorf("config: failed to parse %s: %w", meta.path, err)
+		}
+		if raw.Expires != "" {
+			e.expires, err = parseExpiry(raw.Expires)
+			if err != nil {
+				return fmt.Errorf("config: failed to parse %s: %w", meta.path, err)
+			}
+			result[target] = e
+		}
+		delete(result, n)
+		report.skip(meta, "metadata for "+target)
+	}
+
+	for n, e := range result {
+		if !e.expires.IsZero() {
+			continue
+		}
+		v, ok := headerExpiry(e.data)
+		if !ok {
+			continue
+		}
+		t, err := parseExpiry(v)
+		if err != nil {
+			return fmt.Errorf("config: failed to parse the expiry date of %s: %w", e.path, err)
+		}
+		e.expires = t
+		result[n] = e
+	}
+	return nil
+}
+
+// headerExpiry returns the value of an "expires:" comment among the comment lines at the
+// top of data.
+func headerExpiry(data []byte) (string, bool) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, "#") {
+			return "", false
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if strings.HasPrefix(line, "expires:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "expires:")), true
+		}
+	}
+	return "", false
+}
+
+// parseExpiry parses an RFC 3339 time or a date.
+func parseExpiry(v string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, v)
+	if err == nil {
+		return t, nil
+	}
+	t, err = time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry date %q: expected RFC 3339 or YYYY-MM-DD", v)
+	}
+	return t, nil
+}
+
+// expiries returns the expiry dates declared by the values of m, soonest first.
+func expiries(m map[string]entry) []Expiry {
+	var result []Expiry
+	for n, e := range m {
+		if !e.expires.IsZero() {
+			result = append(result, Expiry{Name: n, Path: e.path, Expires: e.expires})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Expires.Equal(result[j].Expires) {
+			return result[i].Expires.Before(result[j].Expires)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// warnExpiry logs the values of m that have expired or expire within the warning period of
+// s.
+func (s *store) warnExpiry(m map[string]entry) {
+	if s.expiryWarning < 0 {
+		return
+	}
+	now := timeNow()
+	for _, x := range expiries(m) {
+		switch {
+		case x.Expired(now):
+			s.warn(Warning{Kind: WarnExpiry, Name: x.Name, Path: x.Path, Message: fmt.Sprintf("%s expired on %s", x.Name, x.Expires.Format(time.RFC3339))}, true)
+		case x.Expires.Sub(now) <= s.expiryWarning:
+			s.warn(Warning{Kind: WarnExpiry, Name: x.Name, Path: x.Path, Message: fmt.Sprintf("%s expires on %s, in %s", x.Name, x.Expires.Format(time.RFC3339), x.Expires.Sub(now).Round(time.Hour))}, true)
+		}
+	}
+}
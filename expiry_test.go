@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Expiries(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC) }
+
+	dir := writeFiles(t, map[string]string{
+		"tls.crt":      "-----BEGIN CERTIFICATE-----\n",
+		"tls.crt.meta": "expires: 2026-12-01\n",
+		"token":        "# rotated by hand\n# expires: 2026-11-01T12:00:00Z\nsecret\n",
+		"url":          "https://example.com",
+		"orphan.meta":  "expires: 2020-01-01\n",
+	})
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	c := New(WithPath(dir))
+	got, err := c.Expiries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Expiry{
+		{Name: "token", Path: dir + "/token", Expires: time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)},
+		{Name: "tls.crt", Path: dir + "/tls.crt", Expires: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expiries() = %+v, want %+v", got, want)
+	}
+
+	if _, err := c.Bytes("tls.crt.meta"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Bytes() error = %v, want sidecar files not to be loaded", err)
+	}
+	if _, err := c.Bytes("orphan.meta"); err != nil {
+		t.Errorf("Bytes() error = %v, want sidecar files without a value to be loaded", err)
+	}
+
+	var ee *ExpiryError
+	if err := c.Health(); !errors.As(err, &ee) || len(ee.Expired) != 1 || ee.Expired[0].Name != "token" {
+		t.Errorf("Health() error = %v, want token to have expired", err)
+	}
+	if !strings.Contains(buf.String(), "token expired") || !strings.Contains(buf.String(), "tls.crt expires") {
+		t.Errorf("log = %q, want warnings for token and tls.crt", buf.String())
+	}
+
+	buf.Reset()
+	if err := New(WithPath(dir), WithExpiryWarning(-1)).Load(); err != nil || strings.Contains(buf.String(), "expire") {
+		t.Errorf("Load() = %v, log = %q, want no expiry warnings", err, buf.String())
+	}
+
+	bad := writeFiles(t, map[string]string{"key": "k", "key.meta": "expires: soon\n"})
+	if err := New(WithPath(bad)).Load(); err == nil {
+		t.Errorf("Load() error = nil, want an invalid expiry date error")
+	}
+}
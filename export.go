@@ -0,0 +1,167 @@
+package config
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+)
+
+// PAX records holding the provenance and hash of each value in an archive written by
+// Export.
+const (
+	exportPathKey    = "CONFIG.path"
+	exportSourceKey  = "CONFIG.source"
+	exportSHA256Key  = "CONFIG.sha256"
+	exportExpiresKey = "CONFIG.expires"
+)
+
+// importPath is the search path reported for values read by Import.
+const importPath = "import"
+
+// Export calls c.Load() then writes every loaded value to w as a tar archive, in which each
+// file is named after a value. The file each value was read from, the source that provided
+// it, its SHA-256 hash and its expiry date, if any, are recorded as PAX records. The
+// archive can be read back with Import, or by a subprocess through a StdinSource, to use
+// exactly the same configuration. Values are written as they were loaded, after decryption
+// and template rendering, so the archive must be protected like the secrets it contains.
+func (c *Config) Export(w io.Writer) error {
+	err := c.Load()
+	if err != nil {
+		return err
+	}
+
+	c.s.mu.RLock()
+	val := c.s.val
+	c.s.mu.RUnlock()
+	return writeArchive(w, val)
+}
+
+// writeArchive writes val to w as an archive read by Import.
+func writeArchive(w io.Writer, val map[string]entry) error {
+	names := make([]string, 0, len(val))
+	for n := range val {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	tw := tar.NewWriter(w)
+	for _, n := range names {
+		e := val[n].unpacked()
+		h := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     n,
+			Mode:     0600,
+			Size:     int64(len(e.data)),
+			Format:   tar.FormatPAX,
+			PAXRecords: map[string]string{
+				exportPathKey:   e.path,
+				exportSourceKey: e.source,
+				exportSHA256Key: hashEntry(val, n),
+			},
+		}
+		if !e.expires.IsZero() {
+			h.PAXRecords[exportExpiresKey] = e.expires.Format(time.RFC3339)
+		}
+		err := tw.WriteHeader(h)
+		if err == nil {
+			_, err = tw.Write(e.data)
+		}
+		if err != nil {
+			return fmt.Errorf("config: failed to export %s: %w", n, err)
+		}
+	}
+	err := tw.Close()
+	if err != nil {
+		return fmt.Errorf("config: failed to export config: %w", err)
+	}
+	return nil
+}
+
+// Import reads an archive written by Export from r and replaces the loaded values of c
+// with the values in it, as Commit does, keeping the provenance recorded in the archive.
+// Each value is checked against its recorded hash, and the values are validated; if either
+// fails, the current values are kept. Import counts as the initial load if c has not been
+// loaded yet. A later Reload reads the search path again.
+func (c *Config) Import(r io.Reader) error {
+	s := c.s
+	st, err := s.readArchive(r)
+	if err != nil {
+		return err
+	}
+
+	// Importing counts as the initial load, if it has not happened yet, unless the
+	// import fails.
+	loaded := s.once.setLoaded(true)
+	err = st.commit("Import")
+	if err != nil {
+		s.once.setLoaded(loaded)
+		return fmt.Errorf("config: encountered while importing config: %w", err)
+	}
+	return nil
+}
+
+// readArchive reads an archive written by Export from r, checking and validating its values
+// for s, and returns them staged for commit.
+func (s *store) readArchive(r io.Reader) (*Staged, error) {
+	report := &LoadReport{Path: importPath, Start: time.Now(), Sources: []SourceReport{{Source: importPath}}}
+	result := map[string]entry{}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to import config: %w", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to import %s: %w", h.Name, err)
+		}
+		if want, ok := h.PAXRecords[exportSHA256Key]; ok {
+			sum := sha256.Sum256(data)
+			if got := hex.EncodeToString(sum[:]); got != want {
+				return nil, fmt.Errorf("config: failed to import %s: hash %s does not match the recorded hash %s", h.Name, got, want)
+			}
+		}
+
+		e := entry{data: data, path: h.PAXRecords[exportPathKey], source: h.PAXRecords[exportSourceKey]}
+		if e.path == "" {
+			e.path = importPath + "#" + h.Name
+		}
+		if e.source == "" {
+			e.source = importPath
+		}
+		if v, ok := h.PAXRecords[exportExpiresKey]; ok {
+			e.expires, err = parseExpiry(v)
+			if err != nil {
+				return nil, fmt.Errorf("config: failed to import %s: %w", h.Name, err)
+			}
+		}
+		result[h.Name] = e
+		report.Sources[0].Files = append(report.Sources[0].Files, FileReport{Name: h.Name, Path: e.path, Size: len(data)})
+	}
+	report.Duration = time.Since(report.Start)
+
+	err := s.validate(result)
+	report.Err = err
+	s.mu.Lock()
+	s.report = report
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("config: encountered while importing config: %w", err)
+	}
+	s.seal(result)
+	return &Staged{s: s, path: importPath, val: result, report: report, committed: true}, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Export(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"url":          "https://example.com",
+		"tls.crt":      "cert",
+		"tls.crt.meta": "expires: 2030-01-01\n",
+	})
+	c := New(WithPath(dir), WithOverrides(map[string][]byte{"level": []byte("debug")}))
+
+	var buf bytes.Buffer
+	if err := c.Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := parseExecOutput(buf.Bytes())
+	if err != nil || len(files) != 3 {
+		t.Fatalf("parseExecOutput() = %v, %v, want the archive to be readable as a StdinSource", files, err)
+	}
+
+	imported := New(WithPath(t.Name()))
+	if err := imported.Import(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := imported.String("url"); err != nil || got != "https://example.com" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "https://example.com")
+	}
+	if o, err := imported.Origin("url"); err != nil || o.Path != filepath.Join(dir, "url") {
+		t.Errorf("Origin() = %+v, %v, want the exported path", o, err)
+	}
+	if xs, err := imported.Expiries(); err != nil || len(xs) != 1 || xs[0].Name != "tls.crt" {
+		t.Errorf("Expiries() = %+v, %v, want the exported expiry of tls.crt", xs, err)
+	}
+	if got, _ := imported.String("level"); got != "debug" {
+		t.Errorf("String() = %q, want %q", got, "debug")
+	}
+}
+
+func TestConfig_Import(t *testing.T) {
+	archive := func(name, data, sum string) io.Reader {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		h := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600, Size: int64(len(data)), Format: tar.FormatPAX}
+		if sum != "" {
+			h.PAXRecords = map[string]string{exportSHA256Key: sum}
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	c := New(WithPath(t.Name()), WithRequired("name"))
+	if err := c.Import(archive("name", "value", "0000")); err == nil {
+		t.Errorf("Import() error = nil, want a hash mismatch")
+	}
+	var ve *ValidationError
+	if err := c.Import(archive("other", "value", "")); !errors.As(err, &ve) {
+		t.Errorf("Import() error = %v, want %T", err, ve)
+	}
+	if err := c.Import(archive("name", "value", "")); err != nil {
+		t.Fatal(err)
+	}
+	if o, err := c.Origin("name"); err != nil || o.Path != "import#name" {
+		t.Errorf("Origin() = %+v, %v, want %q", o, err, "import#name")
+	}
+}
+
+func TestConfig_Import_initialRejected(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New(WithPath(writeFiles(t, map[string]string{"name": "imported"}))).Export(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(WithPath(writeFiles(t, map[string]string{"name": "loaded"})))
+	reject := true
+	c.OnLoad(func(*Config) error {
+		if reject {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	var hookErr *HookError
+	if err := c.Import(&buf); !errors.As(err, &hookErr) {
+		t.Fatalf("Import() error = %v, want a *HookError", err)
+	}
+
+	reject = false
+	if got, err := c.String("name"); err != nil || got != "loaded" {
+		t.Errorf("String() = %q, %v, want %q; a rejected Import() must not count as the initial load", got, err, "loaded")
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultExtendsKey is the conventional key that names the base of a structured value. See
+// WithExtends.
+const DefaultExtendsKey = "extends"
+
+// WithExtends enables inheritance between YAML and JSON values (those whose names end in
+// .yaml, .yml or .json). A value whose top-level mapping has the given key is merged over
+// the value it names when it is loaded, and the key is removed. For example, with
+// WithExtends(DefaultExtendsKey):
+//
+//		# base.yaml
+//		server:
+//		  port: 8080
+//		  timeout: 5s
+//
+//		# prod.yaml
+//		extends: base.yaml
+//		server:
+//		  timeout: 30s
+//
+// loads prod.yaml with a port of 8080 and a timeout of 30s. The key may also hold a list of
+// names, which are merged in order before the value itself. Bases may extend other values
+// in turn; a cycle is an error. Mappings are merged key by key and lists as set by
+// WithListMerge for the extending value; anything else replaces the base. Merged JSON
+// values are re-encoded as JSON. An empty key disables inheritance, which is the default.
+func WithExtends(key string) Option {
+	return func(o *options) {
+		o.extends = key
+	}
+}
+
+// resolveExtends merges each value in result that extends others over its bases.
+func (s *store) resolveExtends(report *LoadReport, result map[string]entry) error {
+	names := make([]string, 0, len(result))
+	for n := range result {
+		if isStructured(n) {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	orig := make(map[string]entry, len(result))
+	for n, e := range result {
+		orig[n] = e
+	}
+	resolved := map[string]*yaml.Node{}
+
+	var resolve func(n string, stack []string) (*yaml.Node, bool, error)
+	resolve = func(n string, stack []string) (*yaml.Node, bool, error) {
+		for i, prev := range stack {
+			if prev == n {
+				return nil, false, fmt.Errorf("%s cycle: %s", s.extends, strings.Join(append(stack[i:], n), " -> "))
+			}
+		}
+		if node, ok := resolved[n]; ok {
+			return node, true, nil
+		}
+
+		e, ok := orig[n]
+		if !ok {
+			e, ok = s.parent.get(n)
+		}
+		if !ok {
+			return nil, false, fmt.Errorf("%s references missing value %q: %w", s.extends, n, os.ErrNotExist)
+		}
+
+		var doc yaml.Node
+		err := yaml.Unmarshal(e.data, &doc)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse %s: %w", n, err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			resolved[n] = &doc
+			return &doc, false, nil
+		}
+
+		root := doc.Content[0]
+		var bases []string
+		rest := *root
+		rest.Content = nil
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			k, v := root.Content[i], root.Content[i+1]
+			if k.Value != s.extends {
+				rest.Content = append(rest.Content, k, v)
+				continue
+			}
+			switch v.Kind {
+			case yaml.ScalarNode:
+				bases = append(bases, v.Value)
+			case yaml.SequenceNode:
+				for _, b := range v.Content {
+					bases = append(bases, b.Value)
+				}
+			default:
+				return nil, false, fmt.Errorf("%s of %s must be a name or a list of names", s.extends, n)
+			}
+		}
+		if bases == nil {
+			resolved[n] = &doc
+			return &doc, false, nil
+		}
+
+		var merged *yaml.Node
+		for _, b := range bases {
+			base, _, err := resolve(b, append(stack, n))
+			if err != nil {
+				return nil, false, err
+			}
+			if len(base.Content) > 0 {
+				merged = mergeYaml(merged, base.Content[0], "", s.listMerges[n])
+			}
+		}
+		merged = mergeYaml(merged, &rest, "", s.listMerges[n])
+
+		out := doc
+		out.Content = []*yaml.Node{merged}
+		resolved[n] = &out
+		return &out, true, nil
+	}
+
+	for _, n := range names {
+		e := orig[n]
+		node, extended, err := resolve(n, nil)
+		if err == nil && extended {
+			e.data, err = encodeStructured(n, node)
+		}
+		if err != nil {
+			err = s.fileFailed(report, result, n, e, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if extended {
+			result[n] = e
+		}
+	}
+	return nil
+}
+
+// isStructured reports whether configuration value n is YAML or JSON, judging by its name.
+func isStructured(n string) bool {
+	switch path.Ext(n) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// encodeStructured encodes doc as JSON if configuration value n is JSON, and as YAML
+// otherwise.
+func encodeStructured(n string, doc *yaml.Node) ([]byte, error) {
+	if path.Ext(n) != ".json" {
+		return yaml.Marshal(doc)
+	}
+
+	var v interface{}
+	err := doc.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
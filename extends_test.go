@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestWithExtends(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml":    "server:\n  port: 8080\n  timeout: 5s\nfeatures: [a]\n",
+		"staging.yaml": "extends: base.yaml\nserver:\n  timeout: 10s\n",
+		"prod.yaml":    "extends: staging.yaml\nserver:\n  timeout: 30s\nfeatures: [b]\n",
+		"multi.yaml":   "extends: [base.yaml, logging.yaml]\nserver:\n  port: 9090\n",
+		"logging.yaml": "level: info\n",
+		"api.json":     `{"extends": "base.yaml", "server": {"port": 443}}`,
+	})
+
+	type server struct {
+		Port    int    `yaml:"port" json:"port"`
+		Timeout string `yaml:"timeout" json:"timeout"`
+	}
+	type settings struct {
+		Extends  string   `yaml:"extends"`
+		Server   server   `yaml:"server" json:"server"`
+		Features []string `yaml:"features" json:"features"`
+		Level    string   `yaml:"level"`
+	}
+
+	c := New(WithPath(dir), WithExtends(DefaultExtendsKey), WithListMerge("prod.yaml", "features", ListAppend))
+	tests := []struct {
+		name string
+		want settings
+	}{
+		{name: "staging.yaml", want: settings{Server: server{Port: 8080, Timeout: "10s"}, Features: []string{"a"}}},
+		{name: "prod.yaml", want: settings{Server: server{Port: 8080, Timeout: "30s"}, Features: []string{"a", "b"}}},
+		{name: "multi.yaml", want: settings{Server: server{Port: 9090, Timeout: "5s"}, Features: []string{"a"}, Level: "info"}},
+		{name: "base.yaml", want: settings{Server: server{Port: 8080, Timeout: "5s"}, Features: []string{"a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got settings
+			if err := c.InterfaceYaml(tt.name, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InterfaceYaml() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var api settings
+	if err := c.InterfaceJson("api.json", &api); err != nil || api.Server != (server{Port: 443, Timeout: "5s"}) {
+		t.Errorf("InterfaceJson() = %+v, %v, want the merged JSON", api, err)
+	}
+
+	missing := writeFiles(t, map[string]string{"plain.yaml": "extends: nothing special\n"})
+	if err := New(WithPath(missing), WithExtends(DefaultExtendsKey)).Load(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() error = %v, want a missing base for plain.yaml", err)
+	}
+	if err := New(WithPath(missing)).Load(); err != nil {
+		t.Errorf("Load() error = %v, want inheritance to be disabled by default", err)
+	}
+}
+
+func TestWithExtends_cycle(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "extends: b.yaml\n",
+		"b.yaml": "extends: c.yaml\n",
+		"c.yaml": "extends: a.yaml\n",
+	})
+	var fe *FileError
+	err := New(WithPath(dir), WithExtends(DefaultExtendsKey)).Load()
+	if !errors.As(err, &fe) || fe.Err.Error() != "extends cycle: a.yaml -> b.yaml -> c.yaml -> a.yaml" {
+		t.Errorf("Load() error = %v, want an extends cycle", err)
+	}
+}
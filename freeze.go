@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"log"
+)
+
+// ErrFrozen is returned when reloading or committing a Config that has been frozen.
+var ErrFrozen = errors.New("config: config is frozen")
+
+// Freeze calls c.Load() then marks c immutable: from then on, reloading it or committing
+// a staged configuration fails with ErrFrozen, and Watch returns ErrFrozen.
+//
+// If trim is true, Freeze also discards the values that have not been accessed, are not
+// required by WithRequired and have no Validator, releasing their memory. Services that
+// load large shared configuration directories can use it once they are initialized to
+// avoid keeping values they never read for their whole lifetime. Values discarded by
+// trimming are treated as missing afterwards. Freeze does not affect the values of tenant
+// views of c.
+func (c *Config) Freeze(trim bool) error {
+	err := c.Load()
+	if err != nil {
+		return err
+	}
+
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.frozen = true
+	if !trim {
+		return nil
+	}
+
+	keep := map[string]bool{}
+	for _, n := range s.required {
+		keep[n] = true
+	}
+	for n := range s.validators {
+		keep[n] = true
+	}
+
+	val := make(map[string]entry, len(s.val))
+	released, size := 0, 0
+	for n, e := range s.val {
+		if _, ok := s.accessed.Load(n); ok || keep[n] {
+			val[n] = e
+			continue
+		}
+		released++
+		size += e.length()
+	}
+	s.val = val
+	if released > 0 {
+		log.Printf("config: frozen, released %d unused values (%d bytes)", released, size)
+	}
+	return nil
+}
+
+// markAccessed records that configuration value n of s has been accessed, so Freeze keeps
+// it.
+func (s *store) markAccessed(n string) {
+	if _, ok := s.accessed.Load(n); !ok {
+		s.accessed.Store(n, true)
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfig_Freeze(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"used":      "used",
+		"required":  "required",
+		"validated": "validated",
+		"unused":    "unused",
+	})
+
+	tests := []struct {
+		name string
+		trim bool
+		want map[string]bool
+	}{
+		{name: "trim", trim: true, want: map[string]bool{"used": true, "required": true, "validated": true, "unused": false}},
+		{name: "no trim", trim: false, want: map[string]bool{"used": true, "required": true, "validated": true, "unused": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(
+				WithPath(dir),
+				WithRequired("required"),
+				WithValidator("validated", func(string, []byte) error { return nil }),
+			)
+			if _, err := c.String("used"); err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+
+			if err := c.Freeze(tt.trim); err != nil {
+				t.Fatalf("Freeze() error = %v", err)
+			}
+			for n, want := range tt.want {
+				if _, err := c.Bytes(n); (err == nil) != want {
+					t.Errorf("Bytes(%q) error = %v, want present %v", n, err, want)
+				}
+			}
+
+			if err := c.Reload(); !errors.Is(err, ErrFrozen) {
+				t.Errorf("Reload() error = %v, want %v", err, ErrFrozen)
+			}
+			st, err := c.Stage()
+			if err != nil {
+				t.Fatalf("Stage() error = %v", err)
+			}
+			if err := st.Commit(); !errors.Is(err, ErrFrozen) {
+				t.Errorf("Commit() error = %v, want %v", err, ErrFrozen)
+			}
+		})
+	}
+}
+
+func TestConfig_Watch_frozen(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "a"})
+	c := New(WithPath(dir), WithPollInterval(10*time.Millisecond))
+	if err := c.Freeze(false); err != nil {
+		t.Fatalf("Freeze() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = ioutil.WriteFile(filepath.Join(dir, "name"), []byte("bb"), 0644)
+	}()
+	if err := c.Watch(ctx); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Watch() error = %v, want %v", err, ErrFrozen)
+	}
+	if got, _ := c.String("name"); got != "a" {
+		t.Errorf("String() = %q, want %q", got, "a")
+	}
+}
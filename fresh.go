@@ -0,0 +1,152 @@
+package config
+
+import (
+	"context"
+	"time"
+)
+
+// Freshness describes how current a value returned by BytesFresh or StringFresh is.
+type Freshness struct {
+	LoadedAt time.Time // LoadedAt is when the values it was read from were loaded.
+
+	// Stale is true if the value was loaded longer than the requested maximum ago, because
+	// refreshing failed or did not finish before the context was done.
+	Stale bool
+
+	// Err is why the value could not be refreshed, if it is stale, such as the error of
+	// the reload or that of the context.
+	Err error
+}
+
+// BytesFresh is like Bytes, but first reloads c if its values were loaded more than
+// maxStale ago, within the deadline of ctx. If the reload fails or ctx is done first, the
+// values that are already loaded are used, and the returned Freshness says they are
+// stale, so callers choose between freshness and latency on each read:
+//
+//		b, f, err := c.BytesFresh(ctx, "rates.json", time.Minute)
+//		if err != nil {
+//			return err
+//		}
+//		if f.Stale {
+//			log.Printf("using rates from %v: %v", f.LoadedAt, f.Err)
+//		}
+//
+// Concurrent calls share a single reload, which is canceled once every call waiting for it
+// has given up, so sources that honor their context, such as HTTPSource, stop early. A
+// later call waits for a canceled reload to finish before starting another, so reloads
+// never overlap.
+//
+// TTLSource caches older than maxStale are discarded so that they are fetched again.
+// Subscribers are told about the change with the trigger "Refresh". An error is only
+// returned if there is no value to fall back to, such as when c has never loaded or n
+// does not exist.
+func (c *Config) BytesFresh(ctx context.Context, n string, maxStale time.Duration) ([]byte, Freshness, error) {
+	f := c.refresh(ctx, maxStale)
+	b, err := c.Bytes(n)
+	if err != nil {
+		return nil, Freshness{}, err
+	}
+	return b, f, nil
+}
+
+// StringFresh is like String, but refreshes c as BytesFresh does.
+func (c *Config) StringFresh(ctx context.Context, n string, maxStale time.Duration) (string, Freshness, error) {
+	f := c.refresh(ctx, maxStale)
+	v, err := c.String(n)
+	if err != nil {
+		return "", Freshness{}, err
+	}
+	return v, f, nil
+}
+
+// refresh loads c, then reloads it if its values were loaded more than maxStale ago,
+// waiting for the reload until ctx is done.
+func (c *Config) refresh(ctx context.Context, maxStale time.Duration) Freshness {
+	s := c.s
+	err := c.Load()
+	if err != nil {
+		// The accessor reports the error.
+		return Freshness{}
+	}
+
+	loadedAt := c.LoadedAt()
+	if time.Since(loadedAt) <= maxStale {
+		return Freshness{LoadedAt: loadedAt}
+	}
+
+	call := s.joinRefresh(maxStale)
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		s.leaveRefresh(call)
+		return Freshness{LoadedAt: loadedAt, Stale: true, Err: ctx.Err()}
+	}
+
+	loadedAt = c.LoadedAt()
+	if call.err != nil {
+		return Freshness{LoadedAt: loadedAt, Stale: true, Err: call.err}
+	}
+	return Freshness{LoadedAt: loadedAt}
+}
+
+// refreshCall is a reload started by BytesFresh, shared by the calls waiting for it.
+type refreshCall struct {
+	done    chan struct{} // done is closed once the reload finishes.
+	err     error         // err is the error of the reload, once done is closed.
+	cancel  context.CancelFunc
+	waiters int // waiters counts the calls waiting for the reload; it is guarded by the mutex of the store.
+}
+
+// joinRefresh returns the reload of s in progress, starting one if there is none. If the
+// reload in progress was canceled, the new one starts once it finishes.
+func (s *store) joinRefresh(maxStale time.Duration) *refreshCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := s.refresh
+	if prev != nil && prev.waiters > 0 {
+		prev.waiters++
+		return prev
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	call := &refreshCall{done: make(chan struct{}), cancel: cancel, waiters: 1}
+	s.refresh = call
+	go func() {
+		defer cancel()
+		if prev != nil {
+			<-prev.done
+		}
+		err := ctx.Err()
+		if err == nil {
+			for _, src := range s.sources {
+				if t, ok := src.Source.(*TTLSource); ok {
+					t.expireOlderThan(maxStale)
+				}
+			}
+
+			s.reloading()
+			err = s.reload(ctx, "Refresh")
+			s.reloaded(err)
+		}
+
+		s.mu.Lock()
+		if s.refresh == call {
+			s.refresh = nil
+		}
+		s.mu.Unlock()
+		call.err = err
+		close(call.done)
+	}()
+	return call
+}
+
+// leaveRefresh records that a call stopped waiting for call. Once none are waiting, call is
+// canceled, and later calls start a new reload once it finishes.
+func (s *store) leaveRefresh(call *refreshCall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	call.waiters--
+	if call.waiters == 0 {
+		call.cancel()
+	}
+}
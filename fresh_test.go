@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// versionSource is a Source whose single value counts its successful reads. While block is
+// set, reads wait for their context to be done.
+type versionSource struct {
+	mu      sync.Mutex
+	version int
+	block   bool
+	err     error
+}
+
+func (s *versionSource) Files(ctx context.Context) ([]File, error) {
+	s.mu.Lock()
+	block, err := s.block, s.err
+	s.mu.Unlock()
+	if block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version++
+	return []File{{Name: "rates", Path: "version:rates", Data: []byte(strconv.Itoa(s.version))}}, nil
+}
+
+func (s *versionSource) set(block bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.block, s.err = block, err
+}
+
+func TestConfig_BytesFresh(t *testing.T) {
+	src := &versionSource{}
+	c := New(WithPath(""), WithSource(src), WithLoadRetry(0))
+	var triggers []string
+	c.OnChange(func(e ChangeEvent) { triggers = append(triggers, e.Trigger) })
+	errDown := errors.New("down")
+
+	steps := []struct {
+		name      string
+		maxStale  time.Duration
+		block     bool
+		err       error
+		want      string
+		wantStale bool
+	}{
+		{name: "initial", maxStale: time.Hour, want: "1"},
+		{name: "fresh enough", maxStale: time.Hour, want: "1"},
+		{name: "refreshed", maxStale: 0, want: "2"},
+		{name: "refresh fails", maxStale: 0, err: errDown, want: "2", wantStale: true},
+		{name: "deadline", maxStale: 0, block: true, want: "2", wantStale: true},
+		{name: "recovered", maxStale: 0, want: "3"},
+	}
+	for _, st := range steps {
+		t.Run(st.name, func(t *testing.T) {
+			src.set(st.block, st.err)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			got, f, err := c.StringFresh(ctx, "rates", st.maxStale)
+			if err != nil {
+				t.Fatalf("StringFresh() error = %v", err)
+			}
+			if got != st.want || f.Stale != st.wantStale || (f.Err != nil) != st.wantStale {
+				t.Errorf("StringFresh() = %q, %+v, want %q, stale %v", got, f, st.want, st.wantStale)
+			}
+			if st.err != nil && !errors.Is(f.Err, errDown) {
+				t.Errorf("StringFresh() Freshness.Err = %v, want %v", f.Err, errDown)
+			}
+			if st.block && !errors.Is(f.Err, context.DeadlineExceeded) {
+				t.Errorf("StringFresh() Freshness.Err = %v, want %v", f.Err, context.DeadlineExceeded)
+			}
+			if f.LoadedAt.IsZero() {
+				t.Errorf("StringFresh() LoadedAt is zero")
+			}
+		})
+	}
+
+	if len(triggers) != 2 || triggers[0] != "Refresh" {
+		t.Errorf("triggers = %v, want two Refresh", triggers)
+	}
+	if _, _, err := c.BytesFresh(context.Background(), "missing", time.Hour); !errors.Is(err, ErrNotFound) {
+		t.Errorf("BytesFresh() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestConfig_BytesFresh_ttl(t *testing.T) {
+	src := &versionSource{}
+	c := New(WithPath(""), WithSource(TTL(src, time.Hour)))
+
+	if got, _, err := c.BytesFresh(context.Background(), "rates", time.Hour); err != nil || string(got) != "1" {
+		t.Fatalf("BytesFresh() = %q, %v, want 1", got, err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, _ := c.Bytes("rates"); string(got) != "1" {
+		t.Errorf("Bytes() after Reload = %q, want the cached 1", got)
+	}
+	time.Sleep(time.Millisecond)
+	if got, f, err := c.BytesFresh(context.Background(), "rates", 0); err != nil || string(got) != "2" || f.Stale {
+		t.Errorf("BytesFresh() = %q, %+v, %v, want 2", got, f, err)
+	}
+}
+
+// overlapSource is a Source whose single value counts its reads. While hold is set, reads
+// wait for it to be closed, ignoring their context. It records the most reads that were in
+// progress at once.
+type overlapSource struct {
+	mu      sync.Mutex
+	reads   int
+	running int
+	max     int
+	hold    chan struct{}
+}
+
+func (s *overlapSource) Files(context.Context) ([]File, error) {
+	s.mu.Lock()
+	s.running++
+	if s.running > s.max {
+		s.max = s.running
+	}
+	hold := s.hold
+	s.mu.Unlock()
+	if hold != nil {
+		<-hold
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.reads++
+	return []File{{Name: "rates", Path: "overlap:rates", Data: []byte(strconv.Itoa(s.reads))}}, nil
+}
+
+func TestConfig_BytesFresh_abandoned(t *testing.T) {
+	src := &overlapSource{}
+	c := New(WithPath(""), WithSource(src), WithLoadRetry(0))
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	hold := make(chan struct{})
+	src.mu.Lock()
+	src.hold = hold
+	src.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, f, _ := c.StringFresh(ctx, "rates", 0); !errors.Is(f.Err, context.DeadlineExceeded) {
+		t.Fatalf("StringFresh() Freshness = %+v, want %v", f, context.DeadlineExceeded)
+	}
+
+	// The abandoned reload is still reading, so the next call must wait for it rather than
+	// read alongside it.
+	type result struct {
+		v string
+		f Freshness
+	}
+	next := make(chan result)
+	go func() {
+		v, f, _ := c.StringFresh(context.Background(), "rates", 0)
+		next <- result{v, f}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	src.mu.Lock()
+	src.hold = nil
+	src.mu.Unlock()
+	close(hold)
+
+	got := <-next
+	if got.v != "3" || got.f.Stale {
+		t.Errorf("StringFresh() = %q, %+v, want a fresh 3", got.v, got.f)
+	}
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if src.max != 1 {
+		t.Errorf("reads in progress at once = %d, want 1", src.max)
+	}
+}
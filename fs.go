@@ -0,0 +1,184 @@
+//go:build go1.16
+// +build go1.16
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AsFS returns the configuration values of c as a read-only fs.FS, so they can be used
+// with APIs that accept a file system, such as template.ParseFS or http.FS:
+//
+//		t, err := template.ParseFS(c.Scoped("templates/").AsFS(), "*.tmpl")
+//
+// Each value is a file named after it, and names containing slashes are files in
+// directories. Values are read through c each time they are opened, so the file system
+// reflects reloads and includes values from every Source. Opening a file calls c.Load();
+// if that fails, the error is returned. Values marked as secrets (see IsSecret) are
+// included, so scope c before serving the file system to others.
+func (c *Config) AsFS() fs.FS {
+	return configFS{c: c}
+}
+
+// configFS implements fs.FS for the values of a Config.
+type configFS struct {
+	c *Config
+}
+
+func (f configFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	b, err := f.c.Bytes(name)
+	if err == nil {
+		return &configFile{Reader: bytes.NewReader(b), info: configFileInfo{name: path.Base(name), size: int64(len(b)), modTime: f.c.LoadedAt()}}, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	entries := f.readDir(name)
+	if entries == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &configDir{info: configFileInfo{name: path.Base(name), dir: true, modTime: f.c.LoadedAt()}, entries: entries}, nil
+}
+
+func (f configFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	b, err := f.c.Bytes(name)
+	if errors.Is(err, ErrNotFound) && f.readDir(name) != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return append([]byte(nil), b...), nil
+}
+
+// readDir returns the entries of directory dir, in sorted order, or nil if there is no
+// such directory.
+func (f configFS) readDir(dir string) []fs.DirEntry {
+	prefix := f.c.prefix
+	if dir != "." {
+		prefix += dir + "/"
+	}
+
+	var result []fs.DirEntry
+	seen := map[string]bool{}
+	for _, n := range f.c.s.names() {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		rest := n[len(prefix):]
+		child, isDir := rest, false
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			child, isDir = rest[:i], true
+		}
+		if child == "" || seen[child] {
+			continue
+		}
+		seen[child] = true
+
+		info := configFileInfo{name: child, dir: isDir, modTime: f.c.LoadedAt()}
+		if !isDir {
+			info.size = int64(f.c.s.length(n))
+		}
+		result = append(result, info)
+	}
+	if result == nil && dir == "." {
+		return []fs.DirEntry{}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result
+}
+
+// length returns the length of the data of configuration value n of s or its parents,
+// without marking it as accessed.
+func (s *store) length(n string) int {
+	for ; s != nil; s = s.parent {
+		s.mu.RLock()
+		e, ok := s.val[n]
+		s.mu.RUnlock()
+		if ok {
+			return e.length()
+		}
+	}
+	return 0
+}
+
+// configFileInfo describes a configuration value or directory of a configFS. It is both
+// an fs.FileInfo and an fs.DirEntry.
+type configFileInfo struct {
+	name    string
+	size    int64
+	dir     bool
+	modTime time.Time
+}
+
+func (i configFileInfo) Name() string       { return i.name }
+func (i configFileInfo) Size() int64        { return i.size }
+func (i configFileInfo) ModTime() time.Time { return i.modTime }
+func (i configFileInfo) IsDir() bool        { return i.dir }
+func (i configFileInfo) Sys() interface{}   { return nil }
+
+func (i configFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i configFileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i configFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// configFile is an open configuration value. It implements io.Seeker and io.ReaderAt, so
+// it can be served by http.FileServer.
+type configFile struct {
+	*bytes.Reader
+	info configFileInfo
+}
+
+func (f *configFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *configFile) Close() error               { return nil }
+
+// configDir is an open directory of a configFS.
+type configDir struct {
+	info    configFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *configDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *configDir) Close() error               { return nil }
+
+func (d *configDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *configDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return rest[:n], nil
+}
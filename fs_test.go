@@ -0,0 +1,67 @@
+//go:build go1.16
+// +build go1.16
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"text/template"
+)
+
+func TestConfig_AsFS(t *testing.T) {
+	files := map[string]string{
+		"port":                 "8080",
+		"templates/hello.tmpl": "hello {{.}}",
+		"templates/bye.tmpl":   "bye {{.}}",
+		"policies/a/b.json":    `{"allow": true}`,
+	}
+	c := New(WithPath(writeFiles(t, files)), WithMaxDepth(3), WithTrimSpace(false))
+
+	if err := fstest.TestFS(c.AsFS(), "port", "templates/hello.tmpl", "templates/bye.tmpl", "policies/a/b.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		fsys    fs.FS
+		file    string
+		want    string
+		wantErr error
+	}{
+		{name: "value", fsys: c.AsFS(), file: "port", want: "8080"},
+		{name: "nested", fsys: c.AsFS(), file: "policies/a/b.json", want: `{"allow": true}`},
+		{name: "scoped", fsys: c.Scoped("templates/").AsFS(), file: "bye.tmpl", want: "bye {{.}}"},
+		{name: "missing", fsys: c.AsFS(), file: "nope", wantErr: fs.ErrNotExist},
+		{name: "invalid", fsys: c.AsFS(), file: "../port", wantErr: fs.ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fs.ReadFile(tt.fsys, tt.file)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("ReadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadFile() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	tmpl, err := template.ParseFS(c.Scoped("templates/").AsFS(), "*.tmpl")
+	if err != nil {
+		t.Fatalf("ParseFS() error = %v", err)
+	}
+	if tmpl.Lookup("hello.tmpl") == nil || tmpl.Lookup("bye.tmpl") == nil {
+		t.Errorf("ParseFS() templates = %v", tmpl.DefinedTemplates())
+	}
+
+	rec := httptest.NewRecorder()
+	http.FileServer(http.FS(c.AsFS())).ServeHTTP(rec, httptest.NewRequest("GET", "/policies/a/b.json", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"allow": true}` {
+		t.Errorf("FileServer() got = %d %q", rec.Code, rec.Body)
+	}
+}
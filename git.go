@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GitSource is a Source that provides the files in a directory of a git repository. The
+// repository is cloned on first use and fetched every time the source is read, so the
+// files always reflect Ref as of the most recent load. It requires the git command.
+//
+// If Interval is positive, GitSource implements Notifier by fetching the repository
+// periodically, so Watch reloads the Config whenever Ref moves to a new commit. Polling
+// starts when the source is first read and continues for the lifetime of the process.
+type GitSource struct {
+	// Repo is the repository to clone, in any form accepted by git clone.
+	Repo string
+	// Ref is the branch, tag or commit to load. It defaults to the remote HEAD.
+	Ref string
+	// Dir is the directory within the repository to load. It defaults to the root.
+	Dir string
+	// CacheDir is where the repository is cloned. It defaults to a directory in
+	// os.TempDir() derived from Repo.
+	CacheDir string
+	// Interval is how often the repository is fetched to detect changes.
+	Interval time.Duration
+
+	mu     sync.Mutex
+	commit string
+
+	changeNotifier
+	poll sync.Once
+}
+
+// Git returns a GitSource for directory dir of repository repo at ref.
+func Git(repo, ref, dir string) *GitSource {
+	return &GitSource{Repo: repo, Ref: ref, Dir: dir}
+}
+
+func (s *GitSource) Files(ctx context.Context) ([]File, error) {
+	if s.Interval > 0 {
+		s.poll.Do(func() { go s.watch() })
+	}
+	s.reset()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commit, err := s.update(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.commit = commit
+
+	fs, err := dirSource(filepath.Join(s.cacheDir(), filepath.FromSlash(s.Dir))).Files(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fs {
+		fs[i].Path = fmt.Sprintf("%s@%.12s:%s", s.Repo, commit, path.Join(s.Dir, fs[i].Name))
+	}
+	return fs, nil
+}
+
+// watch fetches the repository every s.Interval and notifies when s.Ref has moved.
+func (s *GitSource) watch() {
+	t := time.NewTicker(s.Interval)
+	defer t.Stop()
+
+	for range t.C {
+		s.mu.Lock()
+		prev := s.commit
+		commit, err := s.update(context.Background())
+		s.mu.Unlock()
+
+		if err != nil {
+			log.Printf("config: %v", err)
+			continue
+		}
+		if commit != prev {
+			s.notify()
+		}
+	}
+}
+
+// update clones or fetches the repository, checks out s.Ref and returns its commit.
+func (s *GitSource) update(ctx context.Context) (string, error) {
+	dir := s.cacheDir()
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		_, err := s.git(ctx, "", "clone", "--quiet", "--no-checkout", s.Repo, dir)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		_, err := s.git(ctx, dir, "fetch", "--quiet", "--force", "--prune", "--tags", "origin",
+			"+refs/heads/*:refs/remotes/origin/*")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	ref := s.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	var commit string
+	var err error
+	for _, r := range []string{"origin/" + ref, ref} {
+		commit, err = s.git(ctx, dir, "rev-parse", "--quiet", "--verify", r+"^{commit}")
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("config: failed to resolve %q in git repository %q: %w", ref, s.Repo, err)
+	}
+
+	_, err = s.git(ctx, dir, "checkout", "--quiet", "--force", "--detach", commit)
+	if err != nil {
+		return "", err
+	}
+
+	return commit, nil
+}
+
+func (s *GitSource) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("config: git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s *GitSource) cacheDir() string {
+	if s.CacheDir != "" {
+		return s.CacheDir
+	}
+	h := sha256.Sum256([]byte(s.Repo))
+	return filepath.Join(os.TempDir(), "config-git-"+hex.EncodeToString(h[:8]))
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test",
+		"-c", "user.email=test@example.com",
+		"-c", "commit.gpgsign=false",
+		"-c", "init.defaultBranch=main",
+	}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := writeFiles(t, map[string]string{
+		"config/db_url":  "postgres://v1",
+		"config/timeout": "1s",
+		"README":         "ignored",
+	})
+	gitCommand(t, repo, "init", "--quiet")
+	gitCommand(t, repo, "add", ".")
+	gitCommand(t, repo, "commit", "--quiet", "-m", "v1")
+	gitCommand(t, repo, "tag", "v1")
+
+	src := &GitSource{
+		Repo:     repo,
+		Dir:      "config",
+		CacheDir: filepath.Join(writeFiles(t, nil), "clone"),
+		Interval: 10 * time.Millisecond,
+	}
+	c := New(WithPath(""), WithSource(src))
+	if got, err := c.String("db_url"); err != nil || got != "postgres://v1" {
+		t.Fatalf("String() = %q, %v, want %q", got, err, "postgres://v1")
+	}
+	if _, err := c.Bytes("README"); err == nil {
+		t.Errorf("Bytes() error = %v, wantErr %v", err, true)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Watch(ctx) }()
+
+	if err := ioutil.WriteFile(filepath.Join(repo, "config", "db_url"), []byte("postgres://v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCommand(t, repo, "commit", "--quiet", "-am", "v2")
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		got, err := c.String("db_url")
+		if err == nil && got == "postgres://v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("String() = %q, %v, want %q after commit", got, err, "postgres://v2")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	pinned := New(WithPath(""), WithSource(&GitSource{
+		Repo:     repo,
+		Ref:      "v1",
+		Dir:      "config",
+		CacheDir: filepath.Join(writeFiles(t, nil), "clone"),
+	}))
+	if got, err := pinned.String("db_url"); err != nil || got != "postgres://v1" {
+		t.Errorf("String() at v1 = %q, %v, want %q", got, err, "postgres://v1")
+	}
+}
@@ -2,4 +2,8 @@ module github.com/ajjensen13/config
 
 go 1.14
 
-require gopkg.in/yaml.v2 v2.3.0
+require (
+	github.com/ProtonMail/go-crypto v1.0.0
+	golang.org/x/crypto v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ValueGroup is a family of configuration values whose names match a pattern, such as a
+// directory of rule or plugin files. It is created with Group.
+type ValueGroup struct {
+	c       *Config
+	pattern string
+}
+
+// Group returns the configuration values of c whose names match pattern, as accepted by
+// path.Match (e.g. "rules/*.yaml"). The pattern is matched each time the group is used,
+// so values added or removed by a reload are picked up.
+func (c *Config) Group(pattern string) *ValueGroup {
+	return &ValueGroup{c: c, pattern: pattern}
+}
+
+// Names calls c.Load() then returns the names of the configuration values in g, in sorted
+// order.
+func (g *ValueGroup) Names() ([]string, error) {
+	_, err := path.Match(g.pattern, "")
+	if err != nil {
+		return nil, errorf(ErrValidation, "config: invalid group pattern %q: %w", g.pattern, err)
+	}
+
+	err = g.c.Load()
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to get group %q because there was a load error: %w", g.pattern, err)
+	}
+
+	var result []string
+	for _, name := range g.c.s.names() {
+		if !strings.HasPrefix(name, g.c.prefix) {
+			continue
+		}
+		if ok, _ := path.Match(g.pattern, name[len(g.c.prefix):]); ok {
+			result = append(result, name[len(g.c.prefix):])
+		}
+	}
+	return result, nil
+}
+
+// Decoder decodes a configuration value.
+type Decoder interface {
+	// Decode decodes the configuration value into v, which must be a non-nil pointer.
+	Decode(v interface{}) error
+}
+
+// valueDecoder is the Decoder of configuration value name of c. Values whose names end in
+// ".json" are decoded with InterfaceJson, and others with InterfaceYaml.
+type valueDecoder struct {
+	c    *Config
+	name string
+}
+
+func (d valueDecoder) Decode(v interface{}) error {
+	if path.Ext(d.name) == ".json" {
+		return d.c.InterfaceJson(d.name, v)
+	}
+	return d.c.InterfaceYaml(d.name, v)
+}
+
+// DecodeEach calls fn with the name and a Decoder of each configuration value in g, in
+// sorted order. Values whose names end in ".json" are decoded as JSON, and others as YAML.
+// Errors returned by fn do not stop the iteration; they are collected and returned as a
+// *GroupError once every value has been visited. An empty group is not an error.
+//
+//		err := c.Group("rules/*.yaml").DecodeEach(func(name string, dec config.Decoder) error {
+//			var r Rule
+//			if err := dec.Decode(&r); err != nil {
+//				return err
+//			}
+//			rules = append(rules, r)
+//			return nil
+//		})
+func (g *ValueGroup) DecodeEach(fn func(name string, dec Decoder) error) error {
+	names, err := g.Names()
+	if err != nil {
+		return err
+	}
+
+	var ge GroupError
+	for _, n := range names {
+		err := fn(n, valueDecoder{c: g.c, name: n})
+		if err != nil {
+			ge.Names = append(ge.Names, n)
+			ge.Errs = append(ge.Errs, err)
+		}
+	}
+	if len(ge.Errs) > 0 {
+		ge.Pattern = g.pattern
+		return &ge
+	}
+	return nil
+}
+
+// GroupError is returned by DecodeEach when decoding one or more values of a group fails.
+type GroupError struct {
+	Pattern string   // Pattern is the pattern of the group.
+	Names   []string // Names are the names of the values that failed, in sorted order.
+	Errs    []error  // Errs are the errors of the values in Names.
+}
+
+func (e *GroupError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = fmt.Sprintf("%s: %v", e.Names[i], err)
+	}
+	return fmt.Sprintf("config: failed to decode %d config entries matching %q: %s", len(e.Errs), e.Pattern, strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the errors of e matches target.
+func (e *GroupError) Is(target error) bool {
+	for _, err := range e.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
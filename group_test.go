@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValueGroup_DecodeEach(t *testing.T) {
+	type rule struct {
+		Action string `json:"action" yaml:"action"`
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"rules/b.yaml":        "action: deny\n",
+		"rules/a.json":        `{"action": "allow"}`,
+		"rules/c.yaml":        "action: [log\n",
+		"rules/nested/d.yaml": "action: skip\n",
+		"other.yaml":          "action: skip\n",
+	})
+	c := New(WithPath(dir), WithMaxDepth(2))
+
+	tests := []struct {
+		name       string
+		pattern    string
+		want       map[string]string
+		wantFailed []string
+	}{
+		{name: "all", pattern: "rules/*", want: map[string]string{"rules/a.json": "allow", "rules/b.yaml": "deny"}, wantFailed: []string{"rules/c.yaml"}},
+		{name: "yaml", pattern: "rules/[ab].yaml", want: map[string]string{"rules/b.yaml": "deny"}},
+		{name: "empty", pattern: "plugins/*.yaml", want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			var order []string
+			err := c.Group(tt.pattern).DecodeEach(func(name string, dec Decoder) error {
+				order = append(order, name)
+				var r rule
+				if err := dec.Decode(&r); err != nil {
+					return err
+				}
+				got[name] = r.Action
+				return nil
+			})
+
+			var ge *GroupError
+			if tt.wantFailed == nil {
+				if err != nil {
+					t.Fatalf("DecodeEach() error = %v", err)
+				}
+			} else if !errors.As(err, &ge) || !reflect.DeepEqual(ge.Names, tt.wantFailed) {
+				t.Fatalf("DecodeEach() error = %v, want GroupError for %v", err, tt.wantFailed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeEach() decoded = %v, want %v", got, tt.want)
+			}
+			for i := 1; i < len(order); i++ {
+				if order[i-1] > order[i] {
+					t.Errorf("DecodeEach() order = %v, want sorted", order)
+				}
+			}
+		})
+	}
+}
+
+func TestValueGroup_Names(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"billing/rules/a.yaml": "", "billing/rules/b.yaml": "", "rules/c.yaml": ""})
+	c := New(WithPath(dir), WithMaxDepth(3))
+
+	got, err := c.Scoped("billing/").Group("rules/*.yaml").Names()
+	if err != nil {
+		t.Fatalf("Names() error = %v", err)
+	}
+	if want := []string{"rules/a.yaml", "rules/b.yaml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() got = %v, want %v", got, want)
+	}
+
+	if _, err := c.Group("rules/[").Names(); !errors.Is(err, ErrValidation) {
+		t.Errorf("Names() error = %v, want %v for an invalid pattern", err, ErrValidation)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// DefaultGRPCClient is the GRPCClientSpec returned by Config.GRPCClient for fields that
+// are not set. Its message sizes match the grpc defaults for received messages.
+var DefaultGRPCClient = GRPCClientSpec{
+	MaxRecvMsgSize: 4 << 20,
+	MaxSendMsgSize: 4 << 20,
+}
+
+// minKeepaliveTime is the shortest keepalive time grpc allows; shorter ones are raised to it.
+const minKeepaliveTime = 10 * time.Second
+
+// GRPCClientSpec is the settings of a gRPC client connection, parsed by Config.GRPCClient.
+// The fields map onto grpc dial options:
+//		opts := []grpc.DialOption{
+//			grpc.WithKeepaliveParams(keepalive.ClientParameters{
+//				Time:                s.KeepaliveTime,
+//				Timeout:             s.KeepaliveTimeout,
+//				PermitWithoutStream: s.PermitWithoutStream,
+//			}),
+//			grpc.WithDefaultCallOptions(
+//				grpc.MaxCallRecvMsgSize(s.MaxRecvMsgSize),
+//				grpc.MaxCallSendMsgSize(s.MaxSendMsgSize),
+//			),
+//		}
+//		if s.TLS != nil {
+//			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(s.TLS)))
+//		} else {
+//			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+//		}
+//		conn, err := grpc.Dial(s.Target, opts...)
+type GRPCClientSpec struct {
+	Target              string        // Target is the name to dial, such as "dns:///orders:443".
+	KeepaliveTime       time.Duration // KeepaliveTime is how long the connection may be idle before it is pinged, or 0 to never ping.
+	KeepaliveTimeout    time.Duration // KeepaliveTimeout is how long to wait for a ping to be acknowledged.
+	PermitWithoutStream bool          // PermitWithoutStream pings even when there are no active streams.
+	MaxRecvMsgSize      int           // MaxRecvMsgSize is the maximum size of a received message.
+	MaxSendMsgSize      int           // MaxSendMsgSize is the maximum size of a sent message.
+	TLS                 *tls.Config   // TLS is the transport security configuration, or nil for plaintext.
+}
+
+type grpcClientSpec struct {
+	Target    string `yaml:"target"`
+	Keepalive struct {
+		Time                string `yaml:"time"`
+		Timeout             string `yaml:"timeout"`
+		PermitWithoutStream bool   `yaml:"permitWithoutStream"`
+	} `yaml:"keepalive"`
+	MaxRecvMsgSize string      `yaml:"maxRecvMsgSize"`
+	MaxSendMsgSize string      `yaml:"maxSendMsgSize"`
+	TLS            *tlsEntries `yaml:"tls"`
+	Insecure       bool        `yaml:"insecure"`
+}
+
+// GRPCClient parses configuration value n into a GRPCClientSpec. It expects a yaml or json
+// object of the form
+//		target: dns:///orders.internal:443
+//		keepalive:
+//			time: 30s
+//			timeout: 10s
+//			permitWithoutStream: true
+//		maxRecvMsgSize: 16MiB
+//		maxSendMsgSize: 16MiB
+//		tls:
+//			ca: orders_ca
+//			cert: client_crt
+//			key: client_key
+//			serverName: orders.internal
+//
+// Only target is required. The message sizes are byte sizes as accepted by CachePolicy, and
+// default to those of DefaultGRPCClient. Keepalive durations are parsed by
+// time.ParseDuration; a keepalive time below 10s is rejected, since grpc would silently
+// raise it. The tls ca, cert and key are the names of the configuration values holding the
+// PEM encoded CA certificates, client certificate chain and private key. Connections use
+// TLS with the system roots unless tls is given or insecure is set to true.
+func (c *Config) GRPCClient(n string) (GRPCClientSpec, error) {
+	var raw grpcClientSpec
+	err := c.InterfaceYaml(n, &raw)
+	if err != nil {
+		return GRPCClientSpec{}, err
+	}
+
+	result := DefaultGRPCClient
+	result.Target = raw.Target
+	result.PermitWithoutStream = raw.Keepalive.PermitWithoutStream
+	if result.Target == "" {
+		return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: missing target", n)
+	}
+
+	durations := []struct {
+		name string
+		raw  string
+		dst  *time.Duration
+	}{
+		{"keepalive.time", raw.Keepalive.Time, &result.KeepaliveTime},
+		{"keepalive.timeout", raw.Keepalive.Timeout, &result.KeepaliveTimeout},
+	}
+	for _, d := range durations {
+		if d.raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.raw)
+		if err != nil {
+			return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: %s: %w", n, d.name, err)
+		}
+		if v < 0 {
+			return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: %s is negative", n, d.name)
+		}
+		*d.dst = v
+	}
+	if result.KeepaliveTime > 0 && result.KeepaliveTime < minKeepaliveTime {
+		return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: keepalive.time (%v) is below the grpc minimum of %v", n, result.KeepaliveTime, minKeepaliveTime)
+	}
+
+	sizes := []struct {
+		name string
+		raw  string
+		dst  *int
+	}{
+		{"maxRecvMsgSize", raw.MaxRecvMsgSize, &result.MaxRecvMsgSize},
+		{"maxSendMsgSize", raw.MaxSendMsgSize, &result.MaxSendMsgSize},
+	}
+	for _, s := range sizes {
+		if s.raw == "" {
+			continue
+		}
+		v, err := parseByteSize(s.raw)
+		if err != nil || v > 1<<31-1 {
+			return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: invalid %s %q", n, s.name, s.raw)
+		}
+		*s.dst = int(v)
+	}
+
+	switch {
+	case raw.Insecure && raw.TLS != nil:
+		return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: tls cannot be combined with insecure", n)
+	case raw.Insecure:
+	case raw.TLS != nil:
+		result.TLS, err = c.tlsConfig(*raw.TLS)
+		if err != nil {
+			return GRPCClientSpec{}, errorf(ErrValidation, "config: invalid gRPC client settings in %s: %w", n, err)
+		}
+	default:
+		result.TLS = &tls.Config{}
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestConfig_GRPCClient(t *testing.T) {
+	cert, key := testKeyPair(t, x509.ExtKeyUsageClientAuth)
+	dir := writeFiles(t, map[string]string{
+		"orders":     "target: dns:///orders.internal:443\nkeepalive:\n  time: 30s\n  timeout: 10s\n  permitWithoutStream: true\nmaxRecvMsgSize: 16MiB\ntls:\n  ca: orders_ca\n  cert: client_crt\n  key: client_key\n  serverName: orders.internal\n",
+		"local":      `{"target": "localhost:50051", "insecure": true}`,
+		"public":     "target: api.example.com:443\n",
+		"orders_ca":  cert,
+		"client_crt": cert,
+		"client_key": key,
+		"target":     "maxRecvMsgSize: 1MiB\n",
+		"keepalive":  "target: x:1\nkeepalive:\n  time: 1s\n",
+		"size":       "target: x:1\nmaxSendMsgSize: 4GiB\n",
+		"both":       "target: x:1\ninsecure: true\ntls:\n  ca: orders_ca\n",
+		"ca":         "target: x:1\ntls:\n  ca: client_key\n",
+		"halftls":    "target: x:1\ntls:\n  cert: client_crt\n",
+	})
+	c := New(WithPath(dir))
+
+	got, err := c.GRPCClient("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TLS == nil || got.TLS.RootCAs == nil || len(got.TLS.Certificates) != 1 || got.TLS.ServerName != "orders.internal" {
+		t.Errorf("GRPCClient().TLS = %+v, want the orders CA, client certificate and server name", got.TLS)
+	}
+	got.TLS = nil
+	want := GRPCClientSpec{Target: "dns:///orders.internal:443", KeepaliveTime: 30 * time.Second, KeepaliveTimeout: 10 * time.Second, PermitWithoutStream: true, MaxRecvMsgSize: 16 << 20, MaxSendMsgSize: 4 << 20}
+	if got != want {
+		t.Errorf("GRPCClient() = %+v, want %+v", got, want)
+	}
+
+	if got, err := c.GRPCClient("local"); err != nil || got.TLS != nil {
+		t.Errorf("GRPCClient() = %+v, %v, want plaintext", got, err)
+	}
+	if got, err := c.GRPCClient("public"); err != nil || got.TLS == nil || got.TLS.RootCAs != nil {
+		t.Errorf("GRPCClient() = %+v, %v, want TLS with the system roots", got, err)
+	}
+
+	for _, n := range []string{"target", "keepalive", "size", "both", "ca", "halftls"} {
+		if _, err := c.GRPCClient(n); err == nil {
+			t.Errorf("GRPCClient(%q) error = nil, want an error", n)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+)
+
+func init() {
+	pathSources["http"] = newHTTPSource
+	pathSources["https"] = newHTTPSource
+}
+
+// HTTPSource is a Source that provides the body of an HTTP GET request as a single
+// configuration value. Responses are cached, and requests are made conditional with
+// If-None-Match and If-Modified-Since whenever the server provided an ETag or
+// Last-Modified header, so unchanged content costs a 304 Not Modified response rather
+// than a full download.
+//
+// Search path entries of the form "https://host/path/name" are read with an HTTPSource.
+//
+// If Interval is positive, HTTPSource implements Notifier by polling the URL, so Watch
+// reloads the Config whenever the content changes. Polling starts when the source is
+// first read and continues for the lifetime of the process.
+type HTTPSource struct {
+	// URL is the URL to fetch.
+	URL string
+	// Name is the name of the configuration value. It defaults to the last element of
+	// the URL path.
+	Name string
+	// Header is added to every request.
+	Header http.Header
+	// Client is used to make requests. It defaults to http.DefaultClient.
+	Client *http.Client
+	// Auth authenticates requests, if it is not nil. See WithAuth.
+	Auth Auth
+	// Interval is how often the URL is polled to detect changes.
+	Interval time.Duration
+
+	cache httpCache
+
+	changeNotifier
+	poll sync.Once
+}
+
+// HTTP returns an HTTPSource for u.
+func HTTP(u string) *HTTPSource {
+	return &HTTPSource{URL: u}
+}
+
+func newHTTPSource(u *url.URL) (Source, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("config: search path entry %q requires a host", u.String())
+	}
+	return HTTP(u.String()), nil
+}
+
+func (s *HTTPSource) Files(ctx context.Context) ([]File, error) {
+	if s.Interval > 0 {
+		s.poll.Do(func() { go s.watch() })
+	}
+	s.reset()
+
+	client, err := authClient(s.Client, s.Auth)
+	if err != nil {
+		return nil, err
+	}
+	d, _, err := s.cache.get(ctx, client, s.URL, s.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	n := s.Name
+	if n == "" {
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid url %q: %w", s.URL, err)
+		}
+		n = path.Base(u.Path)
+	}
+
+	return []File{{Name: n, Path: s.URL, Data: d}}, nil
+}
+
+// watch polls s.URL every s.Interval and notifies when its content changes.
+func (s *HTTPSource) watch() {
+	t := time.NewTicker(s.Interval)
+	defer t.Stop()
+
+	for range t.C {
+		client, err := authClient(s.Client, s.Auth)
+		if err != nil {
+			log.Printf("config: %v", err)
+			continue
+		}
+		_, changed, err := s.cache.get(context.Background(), client, s.URL, s.Header)
+		if err != nil {
+			log.Printf("config: %v", err)
+			continue
+		}
+		if changed {
+			s.notify()
+		}
+	}
+}
+
+func (s *HTTPSource) setAuth(a Auth) {
+	if s.Auth == nil {
+		s.Auth = a
+	}
+}
+
+// httpCache caches the response to a conditional GET request.
+type httpCache struct {
+	mu           sync.Mutex
+	etag         string
+	lastModified string
+	data         []byte
+	valid        bool
+}
+
+// get fetches u, or returns the cached response if the server reports that it has not
+// been modified. It reports whether the response differs from the cached one.
+func (c *httpCache) get(ctx context.Context, client *http.Client, u string, header http.Header) ([]byte, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.valid {
+		if c.etag != "" {
+			req.Header.Set("If-None-Match", c.etag)
+		}
+		if c.lastModified != "" {
+			req.Header.Set("If-Modified-Since", c.lastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("config: request for %q failed: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && c.valid:
+		return c.data, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, fmt.Errorf("config: request for %q failed: %s", u, resp.Status)
+	}
+
+	d, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("config: failed to read response for %q: %w", u, err)
+	}
+
+	changed := !c.valid || !bytes.Equal(d, c.data)
+	c.etag = resp.Header.Get("ETag")
+	c.lastModified = resp.Header.Get("Last-Modified")
+	c.data = d
+	c.valid = true
+	return d, changed, nil
+}
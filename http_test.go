@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPSource(t *testing.T) {
+	var mu sync.Mutex
+	body, etag := "postgres://v1", `"v1"`
+	var full, notModified int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	src := HTTP(srv.URL + "/config/db_url")
+	src.Header = http.Header{"Authorization": {"Bearer token"}}
+	c := New(WithPath(""), WithSource(src))
+
+	if got, err := c.String("db_url"); err != nil || got != "postgres://v1" {
+		t.Fatalf("String() = %q, %v, want %q", got, err, "postgres://v1")
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, err := c.String("db_url"); err != nil || got != "postgres://v1" {
+		t.Errorf("String() after Reload() = %q, %v, want %q", got, err, "postgres://v1")
+	}
+	if f, n := atomic.LoadInt32(&full), atomic.LoadInt32(&notModified); f != 1 || n != 1 {
+		t.Errorf("server saw %d full and %d conditional responses, want 1 and 1", f, n)
+	}
+
+	src.Interval = 10 * time.Millisecond
+	polled := New(WithPath(""), WithSource(src))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = polled.Watch(ctx) }()
+
+	mu.Lock()
+	body, etag = "postgres://v2", `"v2"`
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := polled.String("db_url")
+		if err == nil && got == "postgres://v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("String() = %q, %v, want %q after change", got, err, "postgres://v2")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHTTPSource_pathEntry(t *testing.T) {
+	var full, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "postgres://v1")
+	}))
+	defer srv.Close()
+
+	c := New(WithPath(srv.URL + "/config/db_url"))
+	for i := 0; i < 3; i++ {
+		if err := c.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	}
+	if got, err := c.String("db_url"); err != nil || got != "postgres://v1" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "postgres://v1")
+	}
+	if f, n := atomic.LoadInt32(&full), atomic.LoadInt32(&notModified); f != 1 || n != 2 {
+		t.Errorf("server saw %d full and %d conditional responses, want 1 and 2", f, n)
+	}
+}
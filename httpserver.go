@@ -0,0 +1,133 @@
+package config
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultHTTPServer is the HTTPServerSpec returned by Config.HTTPServer for fields that
+// are not set. Its timeouts keep a slow or idle client from holding a connection forever,
+// which is what a zero timeout in http.Server allows.
+var DefaultHTTPServer = HTTPServerSpec{
+	ReadHeaderTimeout: 10 * time.Second,
+	ReadTimeout:       30 * time.Second,
+	WriteTimeout:      30 * time.Second,
+	IdleTimeout:       2 * time.Minute,
+	MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+}
+
+// HTTPServerSpec is the settings of an HTTP server, parsed by Config.HTTPServer. Zero
+// timeouts mean no timeout.
+type HTTPServerSpec struct {
+	Addr              string        // Addr is the address to listen on.
+	ReadHeaderTimeout time.Duration // ReadHeaderTimeout is the time allowed to read request headers.
+	ReadTimeout       time.Duration // ReadTimeout is the time allowed to read a whole request.
+	WriteTimeout      time.Duration // WriteTimeout is the time allowed to write a response.
+	IdleTimeout       time.Duration // IdleTimeout is how long a keep-alive connection may be idle.
+	MaxHeaderBytes    int           // MaxHeaderBytes is the maximum size of request headers.
+	TLS               *tls.Config   // TLS is the server certificate configuration, or nil to serve plain HTTP.
+}
+
+// Apply sets the settings of s on srv. It does not change srv.Addr if s.Addr is empty.
+func (s HTTPServerSpec) Apply(srv *http.Server) {
+	if s.Addr != "" {
+		srv.Addr = s.Addr
+	}
+	srv.ReadHeaderTimeout = s.ReadHeaderTimeout
+	srv.ReadTimeout = s.ReadTimeout
+	srv.WriteTimeout = s.WriteTimeout
+	srv.IdleTimeout = s.IdleTimeout
+	srv.MaxHeaderBytes = s.MaxHeaderBytes
+	srv.TLSConfig = s.TLS
+}
+
+type httpServerSpec struct {
+	Addr              string      `yaml:"addr"`
+	ReadHeaderTimeout string      `yaml:"readHeaderTimeout"`
+	ReadTimeout       string      `yaml:"readTimeout"`
+	WriteTimeout      string      `yaml:"writeTimeout"`
+	IdleTimeout       string      `yaml:"idleTimeout"`
+	MaxHeaderBytes    string      `yaml:"maxHeaderBytes"`
+	TLS               *tlsEntries `yaml:"tls"`
+}
+
+// HTTPServer parses configuration value n into an HTTPServerSpec. It expects a yaml or json
+// object of the form
+//		addr: :8443
+//		readHeaderTimeout: 5s
+//		readTimeout: 30s
+//		writeTimeout: 1m
+//		idleTimeout: 2m
+//		maxHeaderBytes: 64KiB
+//		tls:
+//			cert: server_crt
+//			key: server_key
+//
+// Fields that are not set are taken from DefaultHTTPServer; a timeout must be set to 0
+// explicitly to disable it. Timeouts are parsed by time.ParseDuration, and maxHeaderBytes
+// is a byte size as accepted by CachePolicy. readHeaderTimeout may not exceed
+// readTimeout. The tls cert and key are the names of the configuration values holding the
+// PEM encoded certificate chain and private key.
+func (c *Config) HTTPServer(n string) (HTTPServerSpec, error) {
+	var raw httpServerSpec
+	err := c.InterfaceYaml(n, &raw)
+	if err != nil {
+		return HTTPServerSpec{}, err
+	}
+
+	result := DefaultHTTPServer
+	result.Addr = raw.Addr
+	if result.Addr != "" {
+		if _, _, err := net.SplitHostPort(result.Addr); err != nil {
+			return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: addr: %w", n, err)
+		}
+	}
+
+	timeouts := []struct {
+		name string
+		raw  string
+		dst  *time.Duration
+	}{
+		{"readHeaderTimeout", raw.ReadHeaderTimeout, &result.ReadHeaderTimeout},
+		{"readTimeout", raw.ReadTimeout, &result.ReadTimeout},
+		{"writeTimeout", raw.WriteTimeout, &result.WriteTimeout},
+		{"idleTimeout", raw.IdleTimeout, &result.IdleTimeout},
+	}
+	for _, t := range timeouts {
+		if t.raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(t.raw)
+		if err != nil {
+			return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: %s: %w", n, t.name, err)
+		}
+		if v < 0 {
+			return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: %s is negative", n, t.name)
+		}
+		*t.dst = v
+	}
+	if result.ReadTimeout > 0 && result.ReadHeaderTimeout > result.ReadTimeout {
+		return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: readHeaderTimeout (%v) exceeds readTimeout (%v)", n, result.ReadHeaderTimeout, result.ReadTimeout)
+	}
+
+	if raw.MaxHeaderBytes != "" {
+		size, err := parseByteSize(raw.MaxHeaderBytes)
+		if err != nil || size > int64(^uint(0)>>1) {
+			return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: invalid maxHeaderBytes %q", n, raw.MaxHeaderBytes)
+		}
+		result.MaxHeaderBytes = int(size)
+	}
+
+	if raw.TLS != nil {
+		if raw.TLS.Cert == "" {
+			return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: tls needs both cert and key", n)
+		}
+		result.TLS, err = c.tlsConfig(tlsEntries{Cert: raw.TLS.Cert, Key: raw.TLS.Key})
+		if err != nil {
+			return HTTPServerSpec{}, errorf(ErrValidation, "config: invalid HTTP server settings in %s: %w", n, err)
+		}
+	}
+	return result, nil
+}
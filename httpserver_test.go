@@ -0,0 +1,58 @@
+package config
+
+import (
+	"crypto/x509"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConfig_HTTPServer(t *testing.T) {
+	cert, key := testKeyPair(t, x509.ExtKeyUsageServerAuth)
+	dir := writeFiles(t, map[string]string{
+		"public":     "addr: :8443\nreadHeaderTimeout: 5s\nwriteTimeout: 1m\nidleTimeout: 0\nmaxHeaderBytes: 64KiB\ntls:\n  cert: server_crt\n  key: server_key\n",
+		"internal":   `{"addr": "127.0.0.1:8080"}`,
+		"server_crt": cert,
+		"server_key": key,
+		"addr":       "addr: 8080\n",
+		"negative":   "writeTimeout: -1s\n",
+		"header":     "readHeaderTimeout: 1m\nreadTimeout: 10s\n",
+		"headers":    "maxHeaderBytes: lots\n",
+		"halftls":    "tls:\n  cert: server_crt\n",
+		"badtls":     "tls:\n  cert: server_crt\n  key: server_crt\n",
+		"missingtls": "tls:\n  cert: server_crt\n  key: nokey\n",
+	})
+	c := New(WithPath(dir))
+
+	got, err := c.HTTPServer("public")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TLS == nil || len(got.TLS.Certificates) != 1 {
+		t.Errorf("HTTPServer().TLS = %v, want one certificate", got.TLS)
+	}
+	got.TLS = nil
+	want := HTTPServerSpec{Addr: ":8443", ReadHeaderTimeout: 5 * time.Second, ReadTimeout: 30 * time.Second, WriteTimeout: time.Minute, MaxHeaderBytes: 64 << 10}
+	if got != want {
+		t.Errorf("HTTPServer() = %+v, want %+v", got, want)
+	}
+
+	got, err = c.HTTPServer("internal")
+	want = DefaultHTTPServer
+	want.Addr = "127.0.0.1:8080"
+	if err != nil || got != want {
+		t.Errorf("HTTPServer() = %+v, %v, want %+v", got, err, want)
+	}
+
+	var srv http.Server
+	got.Apply(&srv)
+	if srv.Addr != "127.0.0.1:8080" || srv.ReadHeaderTimeout != 10*time.Second || srv.MaxHeaderBytes != http.DefaultMaxHeaderBytes {
+		t.Errorf("Apply() did not set the settings of %+v", got)
+	}
+
+	for _, n := range []string{"addr", "negative", "header", "headers", "halftls", "badtls", "missingtls"} {
+		if _, err := c.HTTPServer(n); err == nil {
+			t.Errorf("HTTPServer(%q) error = nil, want an error", n)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesObject is the subset of a Kubernetes ConfigMap or Secret written by
+// WriteKubernetesManifests.
+type kubernetesObject struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   kubernetesMetadata `yaml:"metadata"`
+	Type       string             `yaml:"type,omitempty"`
+	Data       map[string]string  `yaml:"data,omitempty"`
+	BinaryData map[string]string  `yaml:"binaryData,omitempty"`
+}
+
+type kubernetesMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// WriteKubernetesManifests calls c.Load() then writes a Kubernetes ConfigMap and Secret,
+// both called name, that hold the configuration values of c under their own names. Mounted
+// as volumes on the search path, they provide the same values to a pod. Values for which
+// c.IsSecret is true are written to the Secret and all others to the ConfigMap; values that
+// are not valid UTF-8 are written to the ConfigMap's binaryData. Either manifest is omitted
+// if it would be empty. The manifests are written as a multi-document YAML stream.
+func (c *Config) WriteKubernetesManifests(w io.Writer, name, namespace string) error {
+	err := c.Load()
+	if err != nil {
+		return fmt.Errorf("config: failed to write manifests because there was a load error: %w", err)
+	}
+
+	meta := kubernetesMetadata{Name: name, Namespace: namespace}
+	cm := kubernetesObject{APIVersion: "v1", Kind: "ConfigMap", Metadata: meta}
+	secret := kubernetesObject{APIVersion: "v1", Kind: "Secret", Metadata: meta, Type: "Opaque"}
+
+	for _, n := range c.s.names() {
+		if !strings.HasPrefix(n, c.prefix) {
+			continue
+		}
+		n = n[len(c.prefix):]
+		if !validKubernetesKey(n) {
+			return fmt.Errorf("config: %q is not a valid ConfigMap or Secret key", n)
+		}
+
+		b, err := c.Bytes(n)
+		if err != nil {
+			return err
+		}
+		switch {
+		case c.IsSecret(n):
+			setKubernetesData(&secret.Data, n, base64.StdEncoding.EncodeToString(b))
+		case utf8.Valid(b):
+			setKubernetesData(&cm.Data, n, string(b))
+		default:
+			setKubernetesData(&cm.BinaryData, n, base64.StdEncoding.EncodeToString(b))
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, o := range []kubernetesObject{cm, secret} {
+		if o.Data == nil && o.BinaryData == nil {
+			continue
+		}
+		err := enc.Encode(o)
+		if err != nil {
+			return err
+		}
+	}
+	err = enc.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func setKubernetesData(m *map[string]string, k, v string) {
+	if *m == nil {
+		*m = map[string]string{}
+	}
+	(*m)[k] = v
+}
+
+// validKubernetesKey reports whether n can be used as a ConfigMap or Secret key.
+func validKubernetesKey(n string) bool {
+	if n == "" || len(n) > 253 || n == "." || n == ".." {
+		return false
+	}
+	for _, r := range n {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_WriteKubernetesManifests(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db_url":      "postgres://db\n",
+		"db_password": "hunter2",
+		"logo.png":    "\x89PNG\xff",
+	})
+
+	var buf bytes.Buffer
+	if err := New(WithPath(dir)).WriteKubernetesManifests(&buf, "app-config", "billing"); err != nil {
+		t.Fatalf("WriteKubernetesManifests() error = %v", err)
+	}
+
+	var got []kubernetesObject
+	dec := yaml.NewDecoder(&buf)
+	for {
+		var o kubernetesObject
+		err := dec.Decode(&o)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		got = append(got, o)
+	}
+
+	meta := kubernetesMetadata{Name: "app-config", Namespace: "billing"}
+	want := []kubernetesObject{
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata:   meta,
+			Data:       map[string]string{"db_url": "postgres://db\n"},
+			BinaryData: map[string]string{"logo.png": base64.StdEncoding.EncodeToString([]byte("\x89PNG\xff"))},
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   meta,
+			Type:       "Opaque",
+			Data:       map[string]string{"db_password": base64.StdEncoding.EncodeToString([]byte("hunter2"))},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WriteKubernetesManifests() = %+v, want %+v", got, want)
+	}
+
+	buf.Reset()
+	if err := New(WithPath(dir), WithSecrets()).WriteKubernetesManifests(&buf, "app-config", ""); err != nil {
+		t.Fatalf("WriteKubernetesManifests() error = %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("kind: Secret")) || bytes.Contains(buf.Bytes(), []byte("namespace")) {
+		t.Errorf("WriteKubernetesManifests() = %s, want only a ConfigMap without a namespace", buf.String())
+	}
+}
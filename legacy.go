@@ -0,0 +1,241 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	pathSources["legacy"] = newLegacySource
+	opaquePathSources["legacy"] = true
+}
+
+// LegacySource is a Source that exposes a single monolithic configuration file, as used
+// by applications that predate this package, as one configuration value per section, so
+// services can move to reading values by name without first splitting the file:
+//
+//		; app.ini
+//		[database]
+//		host = db1
+//		port = 5432
+//
+//		c := config.New(config.WithLegacyFile("/etc/app.ini"))
+//		var db struct{ Host string; Port int }
+//		err := c.InterfaceYaml("database", &db)
+//
+// In INI files, each section becomes a value holding a YAML mapping of its keys to their
+// values, and each key before the first section becomes a value of its own. Lines
+// starting with ";" or "#" are comments, and keys are separated from values by "=" or
+// ":". Values in sections are typed as YAML plain scalars, so numbers and booleans decode
+// as such, unless they are enclosed in double quotes. In YAML and JSON files, each
+// top-level key becomes a value: scalars are used as written, without quotes, and
+// mappings and sequences are encoded in the format of the file. The file is read again
+// each time the Config is reloaded. Once the file has been split, WithMigration can move
+// the values to their final names.
+//
+// Search path entries of the form "legacy:/etc/app.ini" are read with a LegacySource.
+type LegacySource struct {
+	// Path is the file to read.
+	Path string
+	// Format is "ini", "yaml" or "json". If it is empty, it is chosen by the extension of
+	// Path: ".yaml", ".yml" and ".json" files are YAML or JSON, and others are INI.
+	Format string
+}
+
+// Legacy returns a LegacySource for the file at path.
+func Legacy(path string) *LegacySource {
+	return &LegacySource{Path: path}
+}
+
+// WithLegacyFile adds a LegacySource for the file at path. See WithSource.
+func WithLegacyFile(path string) Option {
+	return WithSource(Legacy(path))
+}
+
+func newLegacySource(u *url.URL) (Source, error) {
+	p := u.Path
+	if u.Opaque != "" {
+		var err error
+		p, err = url.PathUnescape(u.Opaque)
+		if err != nil {
+			return nil, fmt.Errorf("config: invalid legacy search path entry %q: %w", u.String(), err)
+		}
+	}
+	if p == "" {
+		return nil, fmt.Errorf("config: legacy search path entry %q has no file", u.String())
+	}
+	return Legacy(filepath.FromSlash(p)), nil
+}
+
+func (s *LegacySource) Files(context.Context) ([]File, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read %s: %w", s, err)
+	}
+
+	var fs []File
+	switch s.format() {
+	case "ini":
+		fs, err = parseIni(b)
+	case "yaml", "json":
+		fs, err = splitDocument(b, s.format())
+	default:
+		err = fmt.Errorf("unknown format %q", s.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to parse %s: %w", s, err)
+	}
+	for i := range fs {
+		fs[i].Path = s.Path + "#" + fs[i].Name
+	}
+	return fs, nil
+}
+
+// format returns the format of the file of s.
+func (s *LegacySource) format() string {
+	if s.Format != "" {
+		return s.Format
+	}
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	}
+	return "ini"
+}
+
+func (s *LegacySource) String() string {
+	return "legacy:" + s.Path
+}
+
+// parseIni splits the INI file b into a value per section, holding a YAML mapping of the
+// keys of the section, and a value per key that precedes the first section.
+func parseIni(b []byte) ([]File, error) {
+	var names []string
+	globals := map[string]string{}
+	sections := map[string]*yaml.Node{}
+	var section string
+
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || l[0] == ';' || l[0] == '#' {
+			continue
+		}
+
+		if l[0] == '[' {
+			if len(l) < 3 || l[len(l)-1] != ']' {
+				return nil, fmt.Errorf("line %d: invalid section header %q", line, l)
+			}
+			section = strings.TrimSpace(l[1 : len(l)-1])
+			if _, ok := globals[section]; ok {
+				return nil, fmt.Errorf("line %d: section %q has the same name as a key", line, section)
+			}
+			if _, ok := sections[section]; !ok {
+				sections[section] = &yaml.Node{Kind: yaml.MappingNode}
+				names = append(names, section)
+			}
+			continue
+		}
+
+		i := strings.IndexAny(l, "=:")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value, got %q", line, l)
+		}
+		k, v := strings.TrimSpace(l[:i]), strings.TrimSpace(l[i+1:])
+		var tag string
+		if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+			v, tag = v[1:len(v)-1], "!!str"
+		}
+		if section != "" {
+			setIniKey(sections[section], k, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v})
+			continue
+		}
+		if _, ok := globals[k]; !ok {
+			names = append(names, k)
+		}
+		globals[k] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]File, 0, len(names))
+	for _, n := range names {
+		if v, ok := globals[n]; ok {
+			result = append(result, File{Name: n, Data: []byte(v)})
+			continue
+		}
+		d, err := yaml.Marshal(sections[n])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, File{Name: n, Data: d})
+	}
+	return result, nil
+}
+
+// setIniKey sets key k of mapping m to v, replacing any previous value of k.
+func setIniKey(m *yaml.Node, k string, v *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == k {
+			m.Content[i+1] = v
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, v)
+}
+
+// splitDocument splits b, a YAML or JSON document according to format, into a value per
+// top-level key.
+func splitDocument(b []byte, format string) ([]File, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(b, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping at the top level", m.Line)
+	}
+
+	result := make([]File, 0, len(m.Content)/2)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if v.Kind == yaml.AliasNode {
+			v = v.Alias
+		}
+		var d []byte
+		switch {
+		case v.Kind == yaml.ScalarNode:
+			d = []byte(v.Value)
+		default:
+			// Decoding resolves any anchors and aliases within v.
+			var x interface{}
+			err = v.Decode(&x)
+			if err == nil && format == "json" {
+				d, err = json.Marshal(x)
+			} else if err == nil {
+				d, err = yaml.Marshal(x)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", k.Line, k.Value, err)
+		}
+		result = append(result, File{Name: k.Value, Data: d})
+	}
+	return result, nil
+}
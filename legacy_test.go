@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLegacySource(t *testing.T) {
+	const ini = `; legacy settings
+name = billing
+debug: "true"
+
+[database]
+host = db1
+port = 5432
+password = "5432"
+port = 5433
+
+# trailing comment
+[cache]
+ttl = 30s
+`
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		format  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "ini",
+			file: "app.ini",
+			data: ini,
+			want: map[string]string{
+				"name":     "billing",
+				"debug":    "true",
+				"database": "host: db1\nport: 5433\npassword: \"5432\"\n",
+				"cache":    "ttl: 30s\n",
+			},
+		},
+		{
+			name: "yaml",
+			file: "app.yaml",
+			data: "name: billing\nport: 8080\ndefaults: &d {ttl: 30s}\ncache: *d\nhosts:\n  - a\n  - b\n",
+			want: map[string]string{
+				"name":     "billing",
+				"port":     "8080",
+				"defaults": "ttl: 30s\n",
+				"cache":    "ttl: 30s\n",
+				"hosts":    "- a\n- b\n",
+			},
+		},
+		{
+			name: "json",
+			file: "app.json",
+			data: `{"name": "billing", "database": {"host": "db1", "port": 5432}, "hosts": ["a"]}`,
+			want: map[string]string{
+				"name":     "billing",
+				"database": `{"host":"db1","port":5432}`,
+				"hosts":    `["a"]`,
+			},
+		},
+		{name: "format", file: "app.conf", data: "name: billing\n", format: "yaml", want: map[string]string{"name": "billing"}},
+		{name: "empty", file: "app.ini", data: "; nothing\n", want: map[string]string{}},
+		{name: "bad header", file: "app.ini", data: "[database\n", wantErr: true},
+		{name: "bad line", file: "app.ini", data: "[database]\nhost\n", wantErr: true},
+		{name: "section and key", file: "app.ini", data: "database = x\n[database]\n", wantErr: true},
+		{name: "not a mapping", file: "app.yaml", data: "- a\n", wantErr: true},
+		{name: "unknown format", file: "app.ini", data: "a = b\n", format: "toml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{tt.file: tt.data})
+			src := &LegacySource{Path: filepath.Join(dir, tt.file), Format: tt.format}
+			fs, err := src.Files(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Files() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got := map[string]string{}
+			for _, f := range fs {
+				got[f.Name] = string(f.Data)
+				if f.Path != src.Path+"#"+f.Name {
+					t.Errorf("Files() path = %q", f.Path)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Files() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Legacy(filepath.Join(t.TempDir(), "missing.ini")).Files(context.Background()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Files() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestWithLegacyFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.ini": "[database]\nhost = db1\nport = 5432\n", "conf/name": "billing"})
+	file := filepath.Join(dir, "app.ini")
+
+	for name, c := range map[string]*Config{
+		"option":      New(WithPath(filepath.Join(dir, "conf")), WithLegacyFile(file), WithMigration("database", "db.yaml", nil)),
+		"search path": New(WithPath(filepath.Join(dir, "conf")+string(os.PathListSeparator)+"legacy:"+file), WithMigration("database", "db.yaml", nil)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var db struct {
+				Host string
+				Port int
+			}
+			if err := c.InterfaceYaml("db.yaml", &db); err != nil {
+				t.Fatalf("InterfaceYaml() error = %v", err)
+			}
+			if db.Host != "db1" || db.Port != 5432 {
+				t.Errorf("InterfaceYaml() got = %+v", db)
+			}
+			if got, err := c.String("name"); err != nil || got != "billing" {
+				t.Errorf("String() = %q, %v, want billing", got, err)
+			}
+		})
+	}
+}
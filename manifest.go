@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultManifest is the name of the checksum manifest verified by default. See
+// WithManifest.
+const DefaultManifest = "SHA256SUMS"
+
+// WithManifest sets the name of the checksum manifest. If a search path directory
+// contains a file with this name, every file loaded from the directory must be listed in
+// it with a matching SHA-256 checksum, and every file it lists must be present, or Load
+// fails. This protects against partially synced or truncated mounts. Files in
+// subdirectories (see WithMaxDepth) are listed by their path relative to the manifest,
+// such as "db/primary.yaml", unless their own directory, or one between it and the
+// manifest, has a manifest of its own. The manifest uses the format of sha256sum, and is
+// not itself provided as a configuration value. An empty name disables verification. It
+// defaults to DefaultManifest.
+func WithManifest(name string) Option {
+	return func(o *options) {
+		o.manifest = name
+	}
+}
+
+// ManifestError is returned by Load when the files in a directory do not match its
+// checksum manifest.
+type ManifestError struct {
+	Manifest string // Manifest is the path of the manifest.
+	File     string // File is the name of the file that failed verification.
+	Reason   string // Reason describes the failure.
+}
+
+func (e *ManifestError) Error() string {
+	return fmt.Sprintf("config: %s failed verification against %s: %s", e.File, e.Manifest, e.Reason)
+}
+
+func (e *ManifestError) Is(target error) bool {
+	return target == ErrSource
+}
+
+// verifyManifest verifies fs, the files read from a directory, against the manifests named
+// manifest among them, in the directory or in its subdirectories. Each manifest lists
+// files by their path relative to its own directory, and each file is verified by the
+// manifest nearest to it. It returns fs without the manifests, and the manifests.
+func verifyManifest(manifest string, fs []File) ([]File, []File, error) {
+	var manifests []File
+	dirs := map[string]bool{}
+	result := make([]File, 0, len(fs))
+	for _, f := range fs {
+		if path.Base(f.Name) == manifest {
+			manifests = append(manifests, f)
+			dirs[path.Dir(f.Name)] = true
+			continue
+		}
+		result = append(result, f)
+	}
+	if len(manifests) == 0 {
+		return result, nil, nil
+	}
+
+	byName := make(map[string]File, len(result))
+	for _, f := range result {
+		byName[f.Name] = f
+	}
+	for _, m := range manifests {
+		err := verifyManifestDir(m, dirs, result, byName)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return result, manifests, nil
+}
+
+// verifyManifestDir verifies fs, also keyed by name in byName, against manifest m. Files
+// in a directory of dirs below the directory of m are verified by the manifest there, so
+// they need not be listed in m.
+func verifyManifestDir(m File, dirs map[string]bool, fs []File, byName map[string]File) error {
+	sums, err := parseManifest(m.Data)
+	if err != nil {
+		return fmt.Errorf("config: failed to parse manifest %q: %w", m.Path, err)
+	}
+
+	dir := path.Dir(m.Name)
+	rel := func(n string) string {
+		if dir == "." {
+			return n
+		}
+		return strings.TrimPrefix(n, dir+"/")
+	}
+	for _, f := range fs {
+		if nearestManifest(f.Name, dirs) != dir {
+			continue
+		}
+		want, ok := sums[rel(f.Name)]
+		if !ok {
+			return &ManifestError{Manifest: m.Path, File: f.Name, Reason: "not listed"}
+		}
+		got := sha256.Sum256(f.Data)
+		if hex.EncodeToString(got[:]) != want {
+			return &ManifestError{Manifest: m.Path, File: f.Name, Reason: "checksum mismatch"}
+		}
+	}
+
+	listed := make([]string, 0, len(sums))
+	for r := range sums {
+		listed = append(listed, r)
+	}
+	sort.Strings(listed)
+	for _, r := range listed {
+		n := path.Join(dir, r)
+		f, ok := byName[n]
+		if !ok {
+			return &ManifestError{Manifest: m.Path, File: n, Reason: "missing"}
+		}
+		// Files verified by a nearer manifest must still match if m lists them.
+		got := sha256.Sum256(f.Data)
+		if hex.EncodeToString(got[:]) != sums[r] {
+			return &ManifestError{Manifest: m.Path, File: n, Reason: "checksum mismatch"}
+		}
+	}
+	return nil
+}
+
+// nearestManifest returns the directory of dirs nearest to the file named n, or "" if
+// none of them contain it.
+func nearestManifest(n string, dirs map[string]bool) string {
+	for d := path.Dir(n); ; d = path.Dir(d) {
+		if dirs[d] {
+			return d
+		}
+		if d == "." || d == "/" {
+			return ""
+		}
+	}
+}
+
+// parseManifest parses a manifest in the format of sha256sum into lowercase hex checksums
+// keyed by the cleaned, slash-separated path of each file.
+func parseManifest(b []byte) (map[string]string, error) {
+	result := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		i := strings.IndexAny(l, " \t")
+		if i != sha256.Size*2 {
+			return nil, fmt.Errorf("line %d: expected checksum and file name", line)
+		}
+		sum := strings.ToLower(l[:i])
+		if _, err := hex.DecodeString(sum); err != nil {
+			return nil, fmt.Errorf("line %d: invalid checksum: %w", line, err)
+		}
+
+		// A leading "*" marks files checksummed in binary mode, which is
+		// irrelevant here.
+		n := strings.TrimPrefix(strings.TrimLeft(l[i:], " \t"), "*")
+		result[path.Clean(filepath.ToSlash(n))] = sum
+	}
+	return result, sc.Err()
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestWithManifest(t *testing.T) {
+	manifest := fmt.Sprintf("%s  db_url\n%s *timeout\n", sha256Hex("postgres://db"), sha256Hex("1s"))
+	nested := fmt.Sprintf("%s  primary.yaml\n%s  ./db/primary.yaml\n", sha256Hex("a: 1"), sha256Hex("b: 2"))
+	sub := fmt.Sprintf("%s  primary.yaml\n", sha256Hex("b: 2"))
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		opts       []Option
+		wantFile   string
+		wantReason string
+	}{
+		{
+			"valid",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s"},
+			nil,
+			"",
+			"",
+		},
+		{
+			"truncated",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://d", "timeout": "1s"},
+			nil,
+			"db_url",
+			"checksum mismatch",
+		},
+		{
+			"unlisted",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s", "extra": "x"},
+			nil,
+			"extra",
+			"not listed",
+		},
+		{
+			"missing",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db"},
+			nil,
+			"timeout",
+			"missing",
+		},
+		{
+			"custom name",
+			map[string]string{"CHECKSUMS": manifest, "db_url": "postgres://d", "timeout": "1s"},
+			[]Option{WithManifest("CHECKSUMS")},
+			"db_url",
+			"checksum mismatch",
+		},
+		{
+			"nested",
+			map[string]string{"SHA256SUMS": nested, "primary.yaml": "a: 1", "db/primary.yaml": "b: 2"},
+			[]Option{WithMaxDepth(1)},
+			"",
+			"",
+		},
+		{
+			"nested mismatch",
+			map[string]string{"SHA256SUMS": nested, "primary.yaml": "a: 1", "db/primary.yaml": "a: 1"},
+			[]Option{WithMaxDepth(1)},
+			"db/primary.yaml",
+			"checksum mismatch",
+		},
+		{
+			"nested unlisted",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s", "db/primary.yaml": "b: 2"},
+			[]Option{WithMaxDepth(1)},
+			"db/primary.yaml",
+			"not listed",
+		},
+		{
+			"nested manifest",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s", "db/SHA256SUMS": sub, "db/primary.yaml": "b: 2"},
+			[]Option{WithMaxDepth(1)},
+			"",
+			"",
+		},
+		{
+			"nested manifest mismatch",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s", "db/SHA256SUMS": sub, "db/primary.yaml": "b: 3"},
+			[]Option{WithMaxDepth(1)},
+			"db/primary.yaml",
+			"checksum mismatch",
+		},
+		{
+			"nested manifest missing",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s", "db/SHA256SUMS": sub},
+			[]Option{WithMaxDepth(1)},
+			"db/primary.yaml",
+			"missing",
+		},
+		{
+			"disabled",
+			map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://d", "timeout": "1s"},
+			[]Option{WithManifest("")},
+			"",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(append([]Option{WithPath(writeFiles(t, tt.files))}, tt.opts...)...)
+			err := c.Load()
+
+			var me *ManifestError
+			if tt.wantFile == "" {
+				if err != nil {
+					t.Fatalf("Load() error = %v, wantErr %v", err, false)
+				}
+				return
+			}
+			if !errors.As(err, &me) {
+				t.Fatalf("Load() error = %v, want %T", err, me)
+			}
+			if me.File != tt.wantFile || me.Reason != tt.wantReason {
+				t.Errorf("Load() error = %v, want %s: %s", err, tt.wantFile, tt.wantReason)
+			}
+		})
+	}
+
+	c := New(WithPath(writeFiles(t, map[string]string{"SHA256SUMS": manifest, "db_url": "postgres://db", "timeout": "1s", "db/SHA256SUMS": sub, "db/primary.yaml": "b: 2"})), WithMaxDepth(1))
+	for _, n := range []string{DefaultManifest, "db/" + DefaultManifest} {
+		if _, err := c.Bytes(n); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Bytes(%q) error = %v, want %v", n, err, os.ErrNotExist)
+		}
+	}
+}
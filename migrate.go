@@ -0,0 +1,57 @@
+package config
+
+import "fmt"
+
+// migration is a rename of a configuration value added with WithMigration.
+type migration struct {
+	from, to string
+	fn       Transformer
+}
+
+// WithMigration moves configuration value from to the name to when the Config is loaded,
+// so code can read values by their new names while deployments still provide the old
+// ones. If fn is not nil, the data of from is passed through it to convert the value to
+// its new shape, such as restructuring a document; its name argument is from. Each
+// migrated value produces a WarnMigrated Warning, which is logged if there are no warning
+// handlers, so the old files can be found and renamed.
+//
+// Migrations apply after transformers and decryption, and before template rendering and
+// validation, in the order they were added, so a value renamed more than once can be
+// migrated step by step. They only apply while to is absent: once a deployment provides
+// to, from is loaded under its own name and a Warning suggests removing it. A value that
+// fn fails on is handled according to the ErrorPolicy, under the name to.
+func WithMigration(from, to string, fn Transformer) Option {
+	return func(o *options) {
+		o.migrations = append(o.migrations, migration{from: from, to: to, fn: fn})
+	}
+}
+
+// migrate applies the migrations of s to result.
+func (s *store) migrate(report *LoadReport, result map[string]entry) error {
+	for _, m := range s.migrations {
+		e, ok := result[m.from]
+		if !ok {
+			continue
+		}
+		if cur, ok := result[m.to]; ok {
+			s.warn(Warning{Kind: WarnMigrated, Name: m.from, Path: e.path, Message: fmt.Sprintf("%s was not migrated because %s exists in %s; remove %s", m.from, m.to, cur.path, e.path)}, true)
+			continue
+		}
+
+		delete(result, m.from)
+		if m.fn != nil {
+			d, err := m.fn(m.from, e.data)
+			if err != nil {
+				err = s.fileFailed(report, result, m.to, e, fmt.Errorf("failed to migrate %s: %w", m.from, err))
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			e.data = d
+		}
+		result[m.to] = e
+		s.warn(Warning{Kind: WarnMigrated, Name: m.to, Path: e.path, Message: fmt.Sprintf("migrated %s to %s; rename %s", m.from, m.to, e.path)}, true)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithMigration(t *testing.T) {
+	wrap := func(_ string, b []byte) ([]byte, error) {
+		return append([]byte("server:\n  "), b...), nil
+	}
+	reject := func(string, []byte) ([]byte, error) {
+		return nil, errors.New("bad shape")
+	}
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		opts     []Option
+		want     map[string]string
+		warnings int
+		wantErr  bool
+	}{
+		{
+			name:     "rename",
+			files:    map[string]string{"dbhost": "db1"},
+			opts:     []Option{WithMigration("dbhost", "db.host", nil)},
+			want:     map[string]string{"db.host": "db1"},
+			warnings: 1,
+		},
+		{
+			name:     "reshape",
+			files:    map[string]string{"port.yaml": "port: 80"},
+			opts:     []Option{WithMigration("port.yaml", "server.yaml", wrap)},
+			want:     map[string]string{"server.yaml": "server:\n  port: 80"},
+			warnings: 1,
+		},
+		{
+			name:     "chained",
+			files:    map[string]string{"a": "1"},
+			opts:     []Option{WithMigration("a", "b", nil), WithMigration("b", "c", nil)},
+			want:     map[string]string{"c": "1"},
+			warnings: 2,
+		},
+		{
+			name:     "already migrated",
+			files:    map[string]string{"old": "1", "new": "2"},
+			opts:     []Option{WithMigration("old", "new", nil)},
+			want:     map[string]string{"old": "1", "new": "2"},
+			warnings: 1,
+		},
+		{
+			name:  "absent",
+			files: map[string]string{"new": "2"},
+			opts:  []Option{WithMigration("old", "new", nil)},
+			want:  map[string]string{"new": "2"},
+		},
+		{
+			name:    "failed",
+			files:   map[string]string{"old": "1"},
+			opts:    []Option{WithMigration("old", "new", reject)},
+			wantErr: true,
+		},
+		{
+			name:     "failed skipped",
+			files:    map[string]string{"old": "1"},
+			opts:     []Option{WithMigration("old", "new", reject), WithErrorPolicy(SkipOnError)},
+			want:     map[string]string{},
+			warnings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings []Warning
+			opts := append([]Option{WithPath(writeFiles(t, tt.files)), WithLoadRetry(0), WithWarnings(func(w Warning) { warnings = append(warnings, w) })}, tt.opts...)
+			c := New(opts...)
+			err := c.Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var fe *FileError
+				if !errors.As(err, &fe) || fe.Name != "new" || !strings.Contains(err.Error(), "bad shape") {
+					t.Errorf("Load() error = %v, want a *FileError for new", err)
+				}
+				return
+			}
+
+			got := map[string]string{}
+			for n := range tt.files {
+				if s, err := c.String(n); err == nil {
+					got[n] = s
+				}
+			}
+			for n := range tt.want {
+				if s, err := c.String(n); err == nil {
+					got[n] = s
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %v, want %v", got, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestWithMigration_logged(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	c := New(WithPath(writeFiles(t, map[string]string{"dbhost": "db1"})), WithMigration("dbhost", "db.host", nil))
+	if _, err := c.String("db.host"); err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "migrated dbhost to db.host") {
+		t.Errorf("log = %q, want a migration notice", buf.String())
+	}
+}
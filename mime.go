@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// MIMETypes calls c.String(n) and parses the result as a list of MIME types separated by
+// commas or newlines, such as an allowlist of upload types. Each is checked with
+// mime.ParseMediaType. The result is the set of media types, in lower case and without
+// parameters; "text/plain; charset=utf-8" is returned as "text/plain". Wildcards such as
+// "image/*" are kept as they are. Parsed values are cached; each call returns a new copy.
+//
+// To reject invalid lists when they are loaded, rather than when they are first used,
+// register ValidMIMETypes with WithValidator.
+func (c *Config) MIMETypes(n string) (map[string]bool, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.s.cachedParse("mimetypes", n, s, func(s string) (interface{}, error) {
+		result, err := parseMIMETypes(s)
+		if err != nil {
+			return nil, errorf(ErrDecode, "config: invalid MIME types in %s: %w", n, err)
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	types := result.(map[string]bool)
+	cp := make(map[string]bool, len(types))
+	for t := range types {
+		cp[t] = true
+	}
+	return cp, nil
+}
+
+// ValidMIMETypes is a Validator that checks that a configuration value is a list of MIME
+// types, as expected by MIMETypes.
+func ValidMIMETypes(_ string, b []byte) error {
+	_, err := parseMIMETypes(string(b))
+	return err
+}
+
+// parseMIMETypes parses s, a list of MIME types separated by commas or newlines.
+func parseMIMETypes(s string) (map[string]bool, error) {
+	result := map[string]bool{}
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		mt, _, err := mime.ParseMediaType(t)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", t, err)
+		}
+		if !strings.Contains(mt, "/") {
+			return nil, fmt.Errorf("%q: missing subtype", t)
+		}
+		result[mt] = true
+	}
+	return result, nil
+}
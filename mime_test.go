@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_MIMETypes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"uploads": "image/png, image/JPEG\ntext/plain; charset=utf-8,\napplication/*\n",
+		"invalid": "image/png, not a type",
+		"nosub":   "image",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{name: "uploads", want: map[string]bool{"image/png": true, "image/jpeg": true, "text/plain": true, "application/*": true}},
+		{name: "invalid", wantErr: true},
+		{name: "nosub", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.MIMETypes(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MIMETypes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MIMETypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	got, _ := c.MIMETypes("uploads")
+	delete(got, "image/png")
+	if again, _ := c.MIMETypes("uploads"); !again["image/png"] {
+		t.Errorf("MIMETypes() = %v, want callers not to share the cached set", again)
+	}
+
+	var ve *ValidationError
+	if err := New(WithPath(dir), WithValidator("invalid", ValidMIMETypes)).Load(); !errors.As(err, &ve) {
+		t.Errorf("Load() error = %v, want %T", err, ve)
+	}
+}
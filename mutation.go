@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+)
+
+// MutationPolicy controls whether a Config checks that the loaded values have not been
+// modified in-process, for example by a caller writing to the slice returned by Bytes, which
+// shares its memory with the loaded value.
+type MutationPolicy int
+
+const (
+	// IgnoreMutation does not check the loaded values.
+	IgnoreMutation MutationPolicy = iota
+
+	// ErrorOnMutation makes accessors return a *MutationError for a value that has been
+	// modified since it was loaded.
+	ErrorOnMutation
+
+	// PanicOnMutation makes accessors panic with a *MutationError for a value that has
+	// been modified since it was loaded.
+	PanicOnMutation
+)
+
+func (p MutationPolicy) String() string {
+	switch p {
+	case IgnoreMutation:
+		return "ignore"
+	case ErrorOnMutation:
+		return "error"
+	case PanicOnMutation:
+		return "panic"
+	}
+	return fmt.Sprintf("MutationPolicy(%d)", int(p))
+}
+
+// WithMutationPolicy sets whether the Config hashes each value when it is loaded and checks
+// the hash whenever the value is read. The default is IgnoreMutation. Checking hashes the
+// value on every read, so it is meant for tests and debugging rather than production.
+func WithMutationPolicy(p MutationPolicy) Option {
+	return func(o *options) {
+		o.mutationPolicy = p
+	}
+}
+
+// MutationError is returned or panicked with when a loaded configuration value has been
+// modified in-process. See WithMutationPolicy.
+type MutationError struct {
+	Name string // Name is the name of the configuration value.
+	Path string // Path is the file the configuration value was read from.
+}
+
+func (e *MutationError) Error() string {
+	return fmt.Sprintf("config: config entry %q (%s) was modified after it was loaded", e.Name, e.Path)
+}
+
+// seal prepares the values in result for reading: it gives them a place to cache their
+// conversion to a string for String and, if s checks for mutations, records their hashes.
+func (s *store) seal(result map[string]entry) {
+	for n, e := range result {
+		if e.packed != nil {
+			continue
+		}
+		e.str = &lazyString{}
+		if s.mutationPolicy != IgnoreMutation {
+			sum := sha256.Sum256(e.data)
+			e.sum = sum[:]
+		}
+		result[n] = e
+	}
+}
+
+// checkMutation applies the mutation policy of s to e, the entry for configuration value
+// n.
+func (s *store) checkMutation(n string, e entry) error {
+	if s.mutationPolicy == IgnoreMutation || e.sum == nil {
+		return nil
+	}
+	sum := sha256.Sum256(e.data)
+	if bytes.Equal(sum[:], e.sum) {
+		return nil
+	}
+
+	err := &MutationError{Name: n, Path: e.path}
+	if s.mutationPolicy == PanicOnMutation {
+		panic(err)
+	}
+	return err
+}
+
+// lazyString is data converted to a string the first time it is needed.
+type lazyString struct {
+	once sync.Once
+	s    string
+}
+
+// text returns the data of e as a string, converting it only once if e was sealed.
+func (e entry) text() string {
+	if e.str == nil {
+		return string(e.data)
+	}
+	e.str.once.Do(func() {
+		e.str.s = string(e.data)
+	})
+	return e.str.s
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithMutationPolicy(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "value", "other": "data"})
+
+	tests := []struct {
+		policy    MutationPolicy
+		wantErr   bool
+		wantPanic bool
+	}{
+		{policy: IgnoreMutation},
+		{policy: ErrorOnMutation, wantErr: true},
+		{policy: PanicOnMutation, wantPanic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			c := New(WithPath(dir), WithMutationPolicy(tt.policy))
+			b, err := c.Bytes("name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			b[0] = 'V'
+
+			if _, err := c.Bytes("other"); err != nil {
+				t.Errorf("Bytes() error = %v, want unmodified values to be readable", err)
+			}
+
+			defer func() {
+				r := recover()
+				if _, ok := r.(*MutationError); ok != tt.wantPanic {
+					t.Errorf("String() panicked with %v, wantPanic %v", r, tt.wantPanic)
+				}
+			}()
+			_, err = c.String("name")
+			var me *MutationError
+			if errors.As(err, &me) != tt.wantErr {
+				t.Errorf("String() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+// WithNameNormalizer sets fn to normalize the names of configuration values, so sources
+// with different conventions, such as files, environment variables and remote keys,
+// resolve to the same names. fn is applied to the name of every file read when the Config
+// is loaded, before anything else sees it, and to the names passed to accessors, after
+// the prefix of a Scoped view is added, so both can use any form that normalizes to the
+// same name:
+//
+//		c := config.New(config.WithNameNormalizer(func(n string) string {
+//			return strings.ReplaceAll(strings.ToLower(n), "-", "_")
+//		}))
+//		port, err := c.Int("HTTP-PORT") // reads http_port
+//
+// Since fn runs before the rest of the load, it should keep extensions: PGP decryption
+// (.gpg and .asc), templates (.tmpl), schemas, ExportEnv and EnvOverrides all depend on
+// the extensions of the normalized names. Files whose names normalize to the same name
+// are handled as duplicates; see DuplicateError. fn must be deterministic and idempotent;
+// its results for accessor names are cached, so it runs once for each name. Names given
+// to other options, such as WithRequired and WithDeprecated, are not normalized.
+func WithNameNormalizer(fn func(name string) string) Option {
+	return func(o *options) {
+		o.normalize = fn
+	}
+}
+
+// normalizeFiles returns a copy of fs in which the name of each file has been passed
+// through normalize.
+func normalizeFiles(fs []File, normalize func(string) string) []File {
+	result := make([]File, len(fs))
+	for i, f := range fs {
+		f.Name = normalize(f.Name)
+		result[i] = f
+	}
+	return result
+}
+
+// normalizeName returns n passed through the normalize option of s, caching the result.
+func (s *store) normalizeName(n string) string {
+	if s.normalize == nil {
+		return n
+	}
+
+	s.normalized.mu.RLock()
+	result, ok := s.normalized.m[n]
+	s.normalized.mu.RUnlock()
+	if ok {
+		return result
+	}
+
+	result = s.normalize(n)
+	s.normalized.mu.Lock()
+	if s.normalized.m == nil {
+		s.normalized.m = map[string]string{}
+	}
+	if len(s.normalized.m) < maxCachedNames {
+		s.normalized.m[n] = result
+	}
+	s.normalized.mu.Unlock()
+	return result
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithNameNormalizer(t *testing.T) {
+	normalize := func(n string) string {
+		n = strings.TrimSuffix(strings.ToLower(n), path.Ext(n))
+		return strings.NewReplacer("-", "_", ".", "_").Replace(n)
+	}
+	dir := writeFiles(t, map[string]string{"HTTP-Port.txt": "8080", "db_host": "db1"})
+	vals := map[string][]byte{"LOG_LEVEL": []byte("debug")}
+	c := New(WithPath(dir), WithOverrides(vals), WithNameNormalizer(normalize))
+
+	tests := []struct {
+		name string
+		c    *Config
+		n    string
+		want string
+	}{
+		{name: "normalized", c: c, n: "http_port", want: "8080"},
+		{name: "original", c: c, n: "HTTP-Port.txt", want: "8080"},
+		{name: "other form", c: c, n: "DB-HOST", want: "db1"},
+		{name: "source", c: c, n: "log_level", want: "debug"},
+		{name: "scoped", c: c.Scoped("DB_"), n: "Host", want: "db1"},
+		{name: "context", c: c.WithContext(WithValues(context.Background(), map[string]interface{}{"LOG-LEVEL": "info"})), n: "Log-Level", want: "info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.c.String(tt.n)
+			if err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("String() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if n, err := c.FirstOf("missing", "HTTP_PORT"); err != nil || n != "HTTP_PORT" {
+		t.Errorf("FirstOf() = %q, %v, want HTTP_PORT", n, err)
+	}
+	if o, err := c.Origin("http_port"); err != nil || !strings.HasSuffix(o.Path, "HTTP-Port.txt") {
+		t.Errorf("Origin() = %+v, %v", o, err)
+	}
+}
+
+func TestWithNameNormalizer_duplicate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"port": "80", "PORT": "8080"})
+	c := New(WithPath(dir), WithNameNormalizer(strings.ToLower), WithLoadRetry(0))
+
+	var de *DuplicateError
+	if err := c.Load(); !errors.As(err, &de) || de.Name != "port" {
+		t.Errorf("Load() error = %v, want a *DuplicateError for port", err)
+	}
+}
+
+func TestWithNameNormalizer_extensions(t *testing.T) {
+	var calls int32
+	normalize := func(n string) string {
+		atomic.AddInt32(&calls, 1)
+		return strings.ReplaceAll(strings.ToLower(n), "-", "_")
+	}
+	dir := writeFiles(t, map[string]string{
+		"Host":        "db1",
+		"DB-URL.tmpl": `postgres://{{ key "host" }}`,
+		"Limits.JSON": `{"max": 5}`,
+	})
+	c := New(WithPath(dir), WithTemplates(nil), WithNameNormalizer(normalize))
+
+	if got, err := c.String("DB-URL"); err != nil || got != "postgres://db1" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "postgres://db1")
+	}
+	if got, err := c.RawJson("LIMITS.json", "max"); err != nil || string(got) != "5" {
+		t.Errorf("RawJson() = %s, %v, want %s", got, err, "5")
+	}
+
+	before := atomic.LoadInt32(&calls)
+	for i := 0; i < 3; i++ {
+		if _, err := c.String("DB-URL"); err != nil {
+			t.Fatalf("String() error = %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != before {
+		t.Errorf("normalizer called %d times for names already read, want 0", got-before)
+	}
+}
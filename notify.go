@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+)
+
+// ReloadHook is told when a Config starts and finishes reloading, so process managers can
+// report the state of a daemon while its configuration changes. See WithReloadHook.
+type ReloadHook interface {
+	// Reloading is called before the search path is read again.
+	Reloading()
+	// Reloaded is called once the reload has finished, with its error, if any. The
+	// previous values are kept when err is not nil, so the Config is still usable.
+	Reloaded(err error)
+}
+
+// WithReloadHook registers h to be told about every reload of the Config, whether caused
+// by Reload or Watch. Loads that happen before the Config has been loaded successfully
+// are not reported, since readiness at startup is for the application to decide.
+//
+// Windows services can implement ReloadHook by sending svc.StartPending and svc.Running
+// statuses to their service control manager, as this package does not depend on the
+// Windows service APIs. On systemd, use SystemdNotify.
+func WithReloadHook(h ReloadHook) Option {
+	return func(o *options) {
+		o.reloadHooks = append(o.reloadHooks, h)
+	}
+}
+
+// SystemdNotify returns a ReloadHook that sends "RELOADING=1" to systemd before each reload
+// and "READY=1" after it, along with a STATUS message describing the error if it fails,
+// as described in sd_notify(3). This lets "systemctl reload" wait until the new
+// configuration is in effect for services with Type=notify. The socket is taken from the
+// NOTIFY_SOCKET environment variable when SystemdNotify is called; if it is not set, the
+// hook does nothing. Errors sending notifications are logged.
+func SystemdNotify() ReloadHook {
+	return systemdHook(os.Getenv("NOTIFY_SOCKET"))
+}
+
+// systemdHook is the ReloadHook returned by SystemdNotify. It holds the notification
+// socket, or "" if the process is not run by systemd.
+type systemdHook string
+
+func (h systemdHook) Reloading() {
+	h.notify("RELOADING=1")
+}
+
+func (h systemdHook) Reloaded(err error) {
+	if err != nil {
+		h.notify("READY=1\nSTATUS=" + strings.ReplaceAll(fmt.Sprintf("config reload failed: %v", err), "\n", " "))
+		return
+	}
+	h.notify("READY=1\nSTATUS=")
+}
+
+// notify sends state to the socket of h. Sockets starting with "@" are in the abstract
+// namespace.
+func (h systemdHook) notify(state string) {
+	if h == "" {
+		return
+	}
+
+	name := string(h)
+	if strings.HasPrefix(name, "@") {
+		name = "\x00" + name[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err == nil {
+		_, err = conn.Write([]byte(state))
+		_ = conn.Close()
+	}
+	if err != nil {
+		log.Printf("config: failed to notify systemd: %v", err)
+	}
+}
+
+// reloading tells the ReloadHooks of o that a reload is starting.
+func (o *options) reloading() {
+	for _, h := range o.reloadHooks {
+		h.Reloading()
+	}
+}
+
+// reloaded tells the ReloadHooks of o that a reload finished with err.
+func (o *options) reloaded(err error) {
+	for _, h := range o.reloadHooks {
+		h.Reloaded(err)
+	}
+}
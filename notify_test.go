@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// recordingHook is a ReloadHook that records the calls made to it.
+type recordingHook struct {
+	calls []string
+}
+
+func (h *recordingHook) Reloading() {
+	h.calls = append(h.calls, "Reloading")
+}
+
+func (h *recordingHook) Reloaded(err error) {
+	h.calls = append(h.calls, "Reloaded("+errString(err)+")")
+}
+
+func errString(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "nil"
+}
+
+func TestWithReloadHook(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "value"})
+	h := &recordingHook{}
+	c := New(WithPath(dir), WithReloadHook(h))
+
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err == nil {
+		t.Fatal("Reload() error = nil after the directory was removed")
+	}
+
+	want := []string{"Reloading", "Reloaded(nil)", "Reloading", "Reloaded(error)"}
+	if !reflect.DeepEqual(h.calls, want) {
+		t.Errorf("ReloadHook calls = %v, want %v", h.calls, want)
+	}
+}
+
+func TestSystemdNotify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("systemd notifications use unixgram sockets")
+	}
+
+	sock := filepath.Join(writeFiles(t, nil), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	defer func(v string, ok bool) {
+		if ok {
+			_ = os.Setenv("NOTIFY_SOCKET", v)
+		} else {
+			_ = os.Unsetenv("NOTIFY_SOCKET")
+		}
+	}(os.LookupEnv("NOTIFY_SOCKET"))
+	if err := os.Setenv("NOTIFY_SOCKET", sock); err != nil {
+		t.Fatal(err)
+	}
+	h := SystemdNotify()
+
+	h.Reloading()
+	h.Reloaded(nil)
+	h.Reloaded(errors.New("bad\nvalue"))
+
+	want := []string{"RELOADING=1", "READY=1\nSTATUS=", "READY=1\nSTATUS=config reload failed: bad value"}
+	buf := make([]byte, 1024)
+	for _, w := range want {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		if got := string(buf[:n]); got != w {
+			t.Errorf("notification = %q, want %q", got, w)
+		}
+	}
+
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"math/big"
+	"strings"
+)
+
+// WithHumanNumbers sets whether Int, IntInRange, Port and Decimal accept numbers written
+// for humans rather than strictly as Go would parse them: with thousands separators
+// ("1,000", "1_000" or "1 000") and SI unit suffixes ("2k", "1.5M", "3G", "1T", where k is
+// 1000). Separators must split the integer part into groups of three digits, and the
+// result of Int must be a whole number, so "1,00" and "1.5" are still rejected. It
+// defaults to false, since a value such as "1,5" means different things in different
+// locales; enable it for files that are edited by hand.
+func WithHumanNumbers(enabled bool) Option {
+	return func(o *options) {
+		o.humanNumbers = enabled
+	}
+}
+
+// numberSuffixes are the multipliers of the unit suffixes accepted by WithHumanNumbers.
+var numberSuffixes = map[byte]int64{
+	'k': 1e3,
+	'K': 1e3,
+	'M': 1e6,
+	'G': 1e9,
+	'T': 1e12,
+}
+
+// parseHumanNumber parses s as a decimal number that may contain thousands separators
+// and end in a unit suffix, as accepted by WithHumanNumbers. The result is exact.
+func parseHumanNumber(s string) (*big.Rat, bool) {
+	s = strings.TrimSpace(s)
+	mul := int64(1)
+	if len(s) > 0 {
+		if m, ok := numberSuffixes[s[len(s)-1]]; ok {
+			mul = m
+			s = strings.TrimSpace(s[:len(s)-1])
+		}
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	digits, ok := stripThousands(intPart)
+	if !ok || digits == "" || strings.Trim(digits, "0123456789") != "" || strings.Trim(frac, ".0123456789") != "" || strings.Count(frac, ".") > 1 {
+		return nil, false
+	}
+
+	result, ok := new(big.Rat).SetString(sign + digits + frac)
+	if !ok {
+		return nil, false
+	}
+	return result.Mul(result, new(big.Rat).SetInt64(mul)), true
+}
+
+// stripThousands removes the thousands separators from the integer part of a number,
+// checking that they split it into groups of three digits. Only one kind of separator
+// may be used.
+func stripThousands(s string) (string, bool) {
+	i := strings.IndexAny(s, ",_ ")
+	if i < 0 {
+		return s, true
+	}
+
+	groups := strings.Split(s, s[i:i+1])
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return "", false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWithHumanNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "plain", value: "42", want: 42},
+		{name: "underscores", value: "1_000", want: 1000},
+		{name: "commas", value: "1,000,000", want: 1000000},
+		{name: "spaces", value: "12 345", want: 12345},
+		{name: "kilo", value: "2k", want: 2000},
+		{name: "mega", value: "3M", want: 3000000},
+		{name: "fractional suffix", value: "1.5K", want: 1500},
+		{name: "negative", value: "-1,024", want: -1024},
+		{name: "giga", value: "2 G", want: 2000000000},
+		{name: "bad grouping", value: "1,00", wantErr: true},
+		{name: "mixed separators", value: "1,000_000", wantErr: true},
+		{name: "fraction", value: "1.5", wantErr: true},
+		{name: "lowercase mega", value: "3m", wantErr: true},
+		{name: "hex", value: "0x10", wantErr: true},
+		{name: "empty", value: "k", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"n": tt.value})
+
+			got, err := New(WithPath(dir), WithHumanNumbers(true)).Int("n")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Int() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Int() got = %v, want %v", got, tt.want)
+			}
+
+			strict, err := New(WithPath(dir)).Int("n")
+			if tt.value != "42" && err == nil {
+				t.Errorf("Int() without WithHumanNumbers got = %v, want error", strict)
+			}
+		})
+	}
+}
+
+func TestWithHumanNumbers_Decimal(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"price": "1,299.99", "budget": "1.5M", "third": "1/3"})
+	c := New(WithPath(dir), WithHumanNumbers(true))
+
+	for n, want := range map[string]*big.Rat{
+		"price":  big.NewRat(129999, 100),
+		"budget": big.NewRat(1500000, 1),
+		"third":  big.NewRat(1, 3),
+	} {
+		got, err := c.Decimal(n)
+		if err != nil || got.Cmp(want) != 0 {
+			t.Errorf("Decimal(%q) = %v, %v, want %v", n, got, err, want)
+		}
+	}
+}
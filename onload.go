@@ -0,0 +1,72 @@
+package config
+
+import "fmt"
+
+// loadHook is a function registered with OnLoad.
+type loadHook struct {
+	id int
+	fn func() error
+}
+
+// OnLoad registers fn to be called with c after every successful load of c, including the
+// initial load, reloads, commits, imports and scheduled switches. Hooks are called in the
+// order they were registered, after the new values are visible and before subscribers
+// registered with OnChange are told about them, so dependent subsystems such as loggers,
+// pools and feature flags can be initialized from c in one place, in a fixed order:
+//
+//		c.OnLoad(initLogging)
+//		c.OnLoad(initDatabase) // can rely on logging being configured
+//		err := c.Load()
+//
+// If a hook returns an error, the remaining hooks are not called, the previous values
+// are restored and the load fails with a *HookError. The hooks that already ran are not
+// called again for the restored values; readers may briefly observe the rejected values
+// while the hooks run. Hooks may read c, but must not reload or commit to it. Register
+// hooks before c is first loaded; a hook registered afterwards is first called on the next
+// load. Call the returned function to unregister fn.
+func (c *Config) OnLoad(fn func(*Config) error) (cancel func()) {
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextSub
+	s.nextSub++
+	s.onLoad = append(s.onLoad, loadHook{id: id, fn: func() error { return fn(c) }})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, h := range s.onLoad {
+			if h.id == id {
+				s.onLoad = append(s.onLoad[:i:i], s.onLoad[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// HookError is returned when a function registered with OnLoad rejects newly loaded
+// values.
+type HookError struct {
+	Index int   // Index is the position of the hook among those registered, starting at 0.
+	Err   error // Err is the error returned by the hook.
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("config: OnLoad hook %d rejected the loaded config: %v", e.Index, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// runLoadHooks calls hooks in order, stopping at the first one that fails.
+func runLoadHooks(hooks []loadHook) error {
+	for i, h := range hooks {
+		err := h.fn()
+		if err != nil {
+			return &HookError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_OnLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"level": "info", "pool": "10"})
+	c := New(WithPath(dir))
+
+	var calls []string
+	c.OnLoad(func(c *Config) error {
+		level, err := c.String("level")
+		calls = append(calls, "logging:"+level)
+		return err
+	})
+	cancel := c.OnLoad(func(c *Config) error {
+		calls = append(calls, "metrics")
+		return nil
+	})
+	c.OnLoad(func(c *Config) error {
+		size, err := c.Int("pool")
+		if err != nil {
+			return err
+		}
+		if size > 100 {
+			return errors.New("pool too large")
+		}
+		calls = append(calls, "pool")
+		return nil
+	})
+	var events int
+	c.OnChange(func(ChangeEvent) { events++ })
+
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"logging:info", "metrics", "pool"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	cancel()
+	calls = nil
+	if err := ioutil.WriteFile(filepath.Join(dir, "level"), []byte("debug"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "pool"), []byte("1000"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generation := c.Generation()
+	err := c.Reload()
+	var he *HookError
+	if !errors.As(err, &he) || he.Index != 1 {
+		t.Fatalf("Reload() error = %v, want a *HookError for hook 1", err)
+	}
+	if want := []string{"logging:debug"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if got, err := c.String("level"); err != nil || got != "info" {
+		t.Errorf("String() after rollback got = %q, %v, want info", got, err)
+	}
+	if got := c.Generation(); got != generation {
+		t.Errorf("Generation() after rollback = %d, want %d", got, generation)
+	}
+	if events != 0 {
+		t.Errorf("events = %d, want 0", events)
+	}
+}
+
+func TestConfig_OnLoad_initial(t *testing.T) {
+	c := New(WithPath(writeFiles(t, map[string]string{"pool": "1000"})), WithLoadRetry(0))
+	c.OnLoad(func(c *Config) error {
+		if _, err := c.Int("pool"); err != nil {
+			return err
+		}
+		return errors.New("rejected")
+	})
+
+	for i := 0; i < 2; i++ {
+		var he *HookError
+		if err := c.Load(); !errors.As(err, &he) {
+			t.Errorf("Load() error = %v, want a *HookError", err)
+		}
+		if _, err := c.Bytes("pool"); err == nil {
+			t.Errorf("Bytes() after a rejected load error = nil")
+		}
+	}
+}
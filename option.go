@@ -0,0 +1,31 @@
+package config
+
+// Option configures a Config created with New.
+type Option func(o *options)
+
+// WithPath sets the search path of the Config, overriding the CONFIG_PATH environment
+// variable. The path is a list of directories separated by os.PathListSeparator. An empty
+// path contains no directories, which is useful when all values come from other sources.
+// Directories ending in "?" are optional and skipped if they do not exist.
+func WithPath(p string) Option {
+	return func(o *options) {
+		o.path = p
+		o.pathSet = true
+	}
+}
+
+// WithTrimSpace controls whether the scalar accessors (String, Int, Duration and Url)
+// trim surrounding whitespace from configuration values. See TrimSpace.
+func WithTrimSpace(trim bool) Option {
+	return func(o *options) {
+		o.trimSpace = trim
+	}
+}
+
+// WithShadow controls whether files in earlier search path directories shadow files with
+// the same name in later ones, rather than causing Load to fail. See Shadow.
+func WithShadow(shadow bool) Option {
+	return func(o *options) {
+		o.shadow = shadow
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// PGPExts are the file name extensions of OpenPGP-encrypted configuration values that are
+// decrypted when decryption is enabled with WithPGP.
+var PGPExts = []string{".gpg", ".asc"}
+
+// PGPKeys locates the OpenPGP private keys used by WithPGP. Keys from every configured
+// location are used. Locations are read every time the Config is loaded, so keys can be
+// rotated without restarting.
+type PGPKeys struct {
+	// KeyringFile is the path of a keyring file, either binary or ASCII armored.
+	KeyringFile string
+	// KeyEnv is the name of an environment variable holding an ASCII armored private key.
+	KeyEnv string
+
+	// Passphrase decrypts private keys that are protected by a passphrase. If it is empty,
+	// the environment variable named by PassphraseEnv is used instead.
+	Passphrase    []byte
+	PassphraseEnv string
+}
+
+// WithPGP enables decryption of OpenPGP-encrypted values. Every configuration value whose
+// name ends in one of PGPExts is decrypted with keys after loading, and the plaintext is
+// provided under the name without the extension. Decryption happens before template
+// rendering, so templates can reference decrypted values.
+func WithPGP(keys PGPKeys) Option {
+	return func(o *options) {
+		o.pgp = &keys
+	}
+}
+
+// keyring reads the private keys located by k.
+func (k *PGPKeys) keyring() (openpgp.EntityList, error) {
+	var result openpgp.EntityList
+
+	if k.KeyringFile != "" {
+		b, err := ioutil.ReadFile(k.KeyringFile)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to read pgp keyring: %w", err)
+		}
+		el, err := readKeyring(b)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to parse pgp keyring %q: %w", k.KeyringFile, err)
+		}
+		result = append(result, el...)
+	}
+
+	if k.KeyEnv != "" {
+		v, ok := os.LookupEnv(k.KeyEnv)
+		if !ok {
+			return nil, fmt.Errorf("config: pgp key environment variable %s is not set", k.KeyEnv)
+		}
+		el, err := openpgp.ReadArmoredKeyRing(strings.NewReader(v))
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to parse pgp key from %s: %w", k.KeyEnv, err)
+		}
+		result = append(result, el...)
+	}
+
+	passphrase := k.Passphrase
+	if len(passphrase) == 0 && k.PassphraseEnv != "" {
+		passphrase = []byte(os.Getenv(k.PassphraseEnv))
+	}
+	if len(passphrase) > 0 {
+		for _, e := range result {
+			if e.PrivateKey != nil && e.PrivateKey.Encrypted {
+				err := e.PrivateKey.Decrypt(passphrase)
+				if err != nil {
+					return nil, fmt.Errorf("config: failed to decrypt pgp private key: %w", err)
+				}
+			}
+			for _, sk := range e.Subkeys {
+				if sk.PrivateKey != nil && sk.PrivateKey.Encrypted {
+					err := sk.PrivateKey.Decrypt(passphrase)
+					if err != nil {
+						return nil, fmt.Errorf("config: failed to decrypt pgp private subkey: %w", err)
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func readKeyring(b []byte) (openpgp.EntityList, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(b))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(b))
+}
+
+// decryptPGP decrypts the OpenPGP-encrypted values in result, adding the plaintext values
+// to it. Values that cannot be decrypted are handled according to the error policy of s.
+func (s *store) decryptPGP(report *LoadReport, result map[string]entry) error {
+	var keyring openpgp.EntityList
+	decrypted := map[string]entry{}
+	for n, e := range result {
+		name, ok := trimPGPExt(n)
+		if !ok {
+			continue
+		}
+
+		if keyring == nil {
+			var err error
+			keyring, err = s.pgp.keyring()
+			if err != nil {
+				return err
+			}
+		}
+
+		d, err := pgpDecrypt(keyring, e.data)
+		if err != nil {
+			err = s.fileFailed(report, decrypted, name, e, fmt.Errorf("failed to decrypt: %w", err))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if prev, ok := result[name]; ok {
+			return &DuplicateError{
+				Name:    name,
+				Paths:   [2]string{prev.path, e.path},
+				Indices: [2]int{prev.index, e.index},
+			}
+		}
+		decrypted[name] = entry{data: d, path: e.path, index: e.index}
+	}
+
+	for n, e := range decrypted {
+		result[n] = e
+	}
+	return nil
+}
+
+func trimPGPExt(n string) (string, bool) {
+	for _, ext := range PGPExts {
+		if strings.HasSuffix(n, ext) && len(n) > len(ext) {
+			return strings.TrimSuffix(n, ext), true
+		}
+	}
+	return n, false
+}
+
+func pgpDecrypt(keyring openpgp.EntityList, b []byte) ([]byte, error) {
+	var r io.Reader = bytes.NewReader(b)
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN")) {
+		block, err := armor.Decode(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		r = block.Body
+	}
+
+	md, err := openpgp.ReadMessage(r, keyring, func([]openpgp.Key, bool) ([]byte, error) {
+		return nil, errors.New("private key is protected by a passphrase")
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(md.UnverifiedBody)
+}
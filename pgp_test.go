@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func pgpEncrypt(t *testing.T, to *openpgp.Entity, plaintext string, armored bool) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var closer io.Closer
+	if armored {
+		aw, err := armor.Encode(&buf, "PGP MESSAGE", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, closer = aw, aw
+	}
+
+	pw, err := openpgp.Encrypt(out, []*openpgp.Entity{to}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pw.Write([]byte(plaintext)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if closer != nil {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.String()
+}
+
+func armoredPrivateKey(t *testing.T, e *openpgp.Entity) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestWithPGP(t *testing.T) {
+	partner, err := openpgp.NewEntity("partner", "", "partner@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"api_token.asc":     pgpEncrypt(t, partner, "token\n", true),
+		"credentials.gpg":   pgpEncrypt(t, partner, `{"username": "partner"}`, false),
+		"keys/partner.asc":  armoredPrivateKey(t, partner),
+		"other/secret.gpg":  pgpEncrypt(t, other, "x", false),
+		"plain/secret":      "plain",
+		"plain/secret.gpg":  pgpEncrypt(t, partner, "x", false),
+		"nokeys/secret.asc": pgpEncrypt(t, partner, "x", true),
+	})
+	keyring := filepath.Join(dir, "keys", "partner.asc")
+
+	c := New(WithPath(dir), WithPGP(PGPKeys{KeyringFile: keyring}))
+	if got, err := c.String("api_token"); err != nil || got != "token" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "token")
+	}
+	if got, err := c.Userinfo("credentials"); err != nil || got.Username() != "partner" {
+		t.Errorf("Userinfo() = %v, %v, want %v", got, err, "partner")
+	}
+
+	if err := os.Setenv("CONFIG_TEST_PGP_KEY", armoredPrivateKey(t, partner)); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("CONFIG_TEST_PGP_KEY")
+	c = New(WithPath(dir), WithPGP(PGPKeys{KeyEnv: "CONFIG_TEST_PGP_KEY"}))
+	if got, err := c.String("api_token"); err != nil || got != "token" {
+		t.Errorf("String() with key from environment = %q, %v, want %q", got, err, "token")
+	}
+
+	for _, sub := range []string{"other", "plain"} {
+		c := New(WithPath(filepath.Join(dir, sub)), WithPGP(PGPKeys{KeyringFile: keyring}))
+		if err := c.Load(); err == nil {
+			t.Errorf("Load() %s error = %v, wantErr %v", sub, err, true)
+		}
+	}
+
+	c = New(WithPath(filepath.Join(dir, "nokeys")), WithPGP(PGPKeys{KeyEnv: "CONFIG_TEST_PGP_MISSING"}))
+	if err := c.Load(); err == nil {
+		t.Errorf("Load() without keys error = %v, wantErr %v", err, true)
+	}
+
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "api_token.asc")); err != nil || !bytes.Contains(b, []byte("BEGIN PGP MESSAGE")) {
+		t.Errorf("api_token.asc is not armored: %v", err)
+	}
+}
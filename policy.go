@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorPolicy controls what happens when an individual file cannot be read, transformed,
+// decrypted or rendered while loading a Config.
+type ErrorPolicy int
+
+const (
+	// FailOnError fails the whole load, keeping the previously loaded values if any.
+	FailOnError ErrorPolicy = iota
+
+	// SkipOnError leaves the value out of the loaded set and records a warning in the
+	// LoadReport.
+	SkipOnError
+
+	// KeepOnError keeps the previously loaded version of the value and records a warning
+	// in the LoadReport. If there is no previous version, the value is left out as with
+	// SkipOnError.
+	KeepOnError
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case FailOnError:
+		return "fail"
+	case SkipOnError:
+		return "skip"
+	case KeepOnError:
+		return "keep"
+	}
+	return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+}
+
+// WithErrorPolicy sets how the Config handles files that cannot be read, transformed,
+// decrypted or rendered. The default is FailOnError, except that files that cannot be
+// read, such as dangling symbolic links, are skipped and listed in the LoadReport unless
+// a policy is set explicitly. Errors that are not specific to one file, such as an
+// unreadable directory or a missing PGP keyring, always fail the load.
+func WithErrorPolicy(p ErrorPolicy) Option {
+	return func(o *options) {
+		o.errorPolicy, o.errorPolicySet = p, true
+	}
+}
+
+// FileError is returned when an individual configuration file cannot be read, decrypted
+// or rendered.
+type FileError struct {
+	Name string // Name is the name of the configuration value the file provides.
+	Path string // Path is the file.
+	Err  error  // Err is the underlying error.
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("config: failed to load %q from %q: %v", e.Name, e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FileError) Is(target error) bool {
+	return target == ErrSource
+}
+
+// fileFailed applies the error policy of s to the failure of file e, which provides
+// configuration value n of result. It returns a non-nil error if loading should stop.
+// Otherwise n has been removed from result or restored to its previous version, and the
+// failure is recorded in report.
+func (s *store) fileFailed(report *LoadReport, result map[string]entry, n string, e entry, err error) error {
+	fe := &FileError{Name: n, Path: e.path, Err: err}
+	if s.errorPolicy == FailOnError {
+		return fe
+	}
+
+	// Another file may provide n, such as one in a later directory when an earlier one is
+	// unreadable. It is loaded as usual.
+	if cur, ok := result[n]; !ok || cur.path == e.path {
+		s.dropOrKeep(result, n)
+	}
+
+	s.warn(Warning{Kind: WarnFileError, Name: n, Path: e.path, Message: fmt.Sprintf("%v (%s)", strings.TrimPrefix(fe.Error(), "config: "), s.errorPolicy)}, true)
+	report.Warnings = append(report.Warnings, fe)
+	return nil
+}
+
+// dropOrKeep removes n from result, restoring the previously loaded version of it if the
+// error policy of s is KeepOnError.
+func (s *store) dropOrKeep(result map[string]entry, n string) {
+	delete(result, n)
+	if s.errorPolicy == KeepOnError {
+		s.mu.RLock()
+		prev, ok := s.val[n]
+		s.mu.RUnlock()
+		if ok {
+			result[n] = prev.unpacked()
+		}
+	}
+}
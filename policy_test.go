@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithErrorPolicy(t *testing.T) {
+	tests := []struct {
+		policy   ErrorPolicy
+		wantErr  bool
+		wantName string // wantName is the value of "name" after the reload, or "" if it is missing.
+	}{
+		{policy: FailOnError, wantErr: true, wantName: "{{ .Old }}"},
+		{policy: SkipOnError, wantName: ""},
+		{policy: KeepOnError, wantName: "{{ .Old }}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"name.tmpl": `{{ "{{ .Old }}" }}`,
+				"other":     "other",
+			})
+			c := New(WithPath(dir), WithTemplates(nil), WithErrorPolicy(tt.policy))
+			if err := c.Load(); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if err := ioutil.WriteFile(filepath.Join(dir, "name.tmpl"), []byte(`{{ key "missing" }}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "link")); err != nil {
+				t.Fatal(err)
+			}
+
+			err := c.Reload()
+			var fe *FileError
+			if (err != nil) != tt.wantErr || err != nil && !errors.As(err, &fe) {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := c.String("name")
+			if tt.wantName == "" {
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("String() = %q, %v, want %v", got, err, os.ErrNotExist)
+				}
+			} else if got != tt.wantName {
+				t.Errorf("String() = %q, %v, want %q", got, err, tt.wantName)
+			}
+			if got, _ := c.String("other"); got != "other" {
+				t.Errorf("String() = %q, want %q", got, "other")
+			}
+
+			if !tt.wantErr {
+				if w := c.Report().Warnings; len(w) != 2 {
+					t.Errorf("Report() warnings = %v, want 2", w)
+				}
+			}
+		})
+	}
+}
+
+func TestWithErrorPolicy_unreadableShadowed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"b/name": "b"})
+	if err := os.MkdirAll(filepath.Join(dir, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "a", "name")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(dir, "b"), filepath.Join(dir, "a", "..data")); err != nil {
+		t.Fatal(err)
+	}
+
+	c := New(WithPath(filepath.Join(dir, "a")+string(os.PathListSeparator)+filepath.Join(dir, "b")), WithErrorPolicy(SkipOnError))
+	if got, err := c.String("name"); got != "b" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "b")
+	}
+
+	c = New(WithPath(filepath.Join(dir, "a")))
+	if got, err := c.String("name"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("String() = %q, %v, want %v; unreadable files are skipped by default", got, err, os.ErrNotExist)
+	}
+
+	c = New(WithPath(filepath.Join(dir, "a")), WithErrorPolicy(FailOnError))
+	var fe *FileError
+	if err := c.Load(); !errors.As(err, &fe) || fe.Name != "name" {
+		t.Errorf("Load() error = %v, want *FileError for %q", err, "name")
+	}
+}
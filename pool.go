@@ -0,0 +1,103 @@
+package config
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DefaultPool is the PoolSpec returned by Config.PoolSettings for fields that are not set.
+var DefaultPool = PoolSpec{
+	MaxOpen:             10,
+	MaxIdle:             2,
+	MaxLifetime:         time.Hour,
+	MaxIdleTime:         30 * time.Minute,
+	HealthCheckInterval: time.Minute,
+}
+
+// PoolSpec is the settings of a connection pool, parsed by Config.PoolSettings. Zero
+// limits and durations mean unlimited.
+type PoolSpec struct {
+	MaxOpen             int           // MaxOpen is the maximum number of open connections.
+	MaxIdle             int           // MaxIdle is the maximum number of idle connections.
+	MaxLifetime         time.Duration // MaxLifetime is how long a connection may be reused.
+	MaxIdleTime         time.Duration // MaxIdleTime is how long a connection may be idle.
+	HealthCheckInterval time.Duration // HealthCheckInterval is how often idle connections are checked.
+}
+
+// Apply sets the limits of p on db. database/sql has no health checks, so
+// HealthCheckInterval is not applied. MaxIdleTime is not applied either, since
+// (*sql.DB).SetConnMaxIdleTime needs Go 1.15; callers on newer versions can set it
+// themselves.
+func (p PoolSpec) Apply(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpen)
+	db.SetMaxIdleConns(p.MaxIdle)
+	db.SetConnMaxLifetime(p.MaxLifetime)
+}
+
+type poolSpec struct {
+	MaxOpen             *int   `yaml:"maxOpen"`
+	MaxIdle             *int   `yaml:"maxIdle"`
+	MaxLifetime         string `yaml:"maxLifetime"`
+	MaxIdleTime         string `yaml:"maxIdleTime"`
+	HealthCheckInterval string `yaml:"healthCheckInterval"`
+}
+
+// PoolSettings parses configuration value n into a PoolSpec. It expects a yaml or json
+// object of the form
+//		maxOpen: 20
+//		maxIdle: 5
+//		maxLifetime: 1h
+//		maxIdleTime: 30m
+//		healthCheckInterval: 1m
+//
+// Fields that are not set are taken from DefaultPool; if maxOpen is set lower than the
+// default maxIdle, maxIdle defaults to maxOpen instead. Durations are parsed by
+// time.ParseDuration. maxIdle may not exceed maxOpen unless maxOpen is 0.
+func (c *Config) PoolSettings(n string) (PoolSpec, error) {
+	var raw poolSpec
+	err := c.InterfaceYaml(n, &raw)
+	if err != nil {
+		return PoolSpec{}, err
+	}
+
+	result := DefaultPool
+	if raw.MaxOpen != nil {
+		result.MaxOpen = *raw.MaxOpen
+		if result.MaxOpen > 0 && result.MaxIdle > result.MaxOpen {
+			result.MaxIdle = result.MaxOpen
+		}
+	}
+	if raw.MaxIdle != nil {
+		result.MaxIdle = *raw.MaxIdle
+	}
+	if result.MaxOpen < 0 || result.MaxIdle < 0 {
+		return PoolSpec{}, errorf(ErrValidation, "config: failed to unmarshal %s into pool settings: connection limits may not be negative", n)
+	}
+	if result.MaxOpen > 0 && result.MaxIdle > result.MaxOpen {
+		return PoolSpec{}, errorf(ErrValidation, "config: failed to unmarshal %s into pool settings: maxIdle (%d) exceeds maxOpen (%d)", n, result.MaxIdle, result.MaxOpen)
+	}
+
+	durations := []struct {
+		name string
+		raw  string
+		dst  *time.Duration
+	}{
+		{"maxLifetime", raw.MaxLifetime, &result.MaxLifetime},
+		{"maxIdleTime", raw.MaxIdleTime, &result.MaxIdleTime},
+		{"healthCheckInterval", raw.HealthCheckInterval, &result.HealthCheckInterval},
+	}
+	for _, d := range durations {
+		if d.raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.raw)
+		if err != nil {
+			return PoolSpec{}, errorf(ErrDecode, "config: failed to unmarshal %s into pool settings: %s: %w", n, d.name, err)
+		}
+		if v < 0 {
+			return PoolSpec{}, errorf(ErrValidation, "config: failed to unmarshal %s into pool settings: %s is negative", n, d.name)
+		}
+		*d.dst = v
+	}
+	return result, nil
+}
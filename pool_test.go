@@ -0,0 +1,58 @@
+package config
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestConfig_PoolSettings(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"primary":   "maxOpen: 20\nmaxIdle: 5\nmaxLifetime: 2h\nmaxIdleTime: 0\nhealthCheckInterval: 15s\n",
+		"empty":     "{}",
+		"small":     `{"maxOpen": 1}`,
+		"unlimited": "maxOpen: 0\nmaxIdle: 50\n",
+		"idle":      "maxOpen: 5\nmaxIdle: 6\n",
+		"negative":  "maxOpen: -1\n",
+		"lifetime":  "maxLifetime: forever\n",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    PoolSpec
+		wantErr bool
+	}{
+		{name: "primary", want: PoolSpec{MaxOpen: 20, MaxIdle: 5, MaxLifetime: 2 * time.Hour, HealthCheckInterval: 15 * time.Second}},
+		{name: "empty", want: DefaultPool},
+		{name: "small", want: PoolSpec{MaxOpen: 1, MaxIdle: 1, MaxLifetime: time.Hour, MaxIdleTime: 30 * time.Minute, HealthCheckInterval: time.Minute}},
+		{name: "unlimited", want: PoolSpec{MaxIdle: 50, MaxLifetime: time.Hour, MaxIdleTime: 30 * time.Minute, HealthCheckInterval: time.Minute}},
+		{name: "idle", wantErr: true},
+		{name: "negative", wantErr: true},
+		{name: "lifetime", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.PoolSettings(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PoolSettings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("PoolSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolSpec_Apply(t *testing.T) {
+	db, err := sql.Open("configtest", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	PoolSpec{MaxOpen: 7, MaxIdle: 3}.Apply(db)
+	if got := db.Stats().MaxOpenConnections; got != 7 {
+		t.Errorf("MaxOpenConnections = %d, want 7", got)
+	}
+}
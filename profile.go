@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the name of the profile that other profiles are merged over by
+// Profile.
+const DefaultProfile = "default"
+
+// Profile calls c.Load() then decodes the profile named profile of the YAML (or JSON)
+// configuration value named n into v. The value is a mapping from profile names to
+// profiles, such as:
+//		default:
+//		  server: {host: localhost, port: 8080}
+//		prod:
+//		  server: {host: example.com}
+// The profile is merged over the DefaultProfile profile, if there is one: mappings are
+// merged key by key, and any other value replaces the default. In the example above, the
+// "prod" profile has the server port 8080. Lists replace the default unless another
+// ListMerge is set with WithListMerge. If the value has no profile named profile, the
+// error wraps os.ErrNotExist.
+func (c *Config) Profile(n, profile string, v interface{}) error {
+	e, err := c.lookup(n)
+	if err != nil {
+		return err
+	}
+
+	root, err := c.yamlNode(n, e, v)
+	c.s.recordDecode(c.fullName(n), err)
+	if err != nil {
+		return err
+	}
+
+	profiles := root
+	if profiles.Kind == yaml.DocumentNode && len(profiles.Content) > 0 {
+		profiles = profiles.Content[0]
+	}
+	if profiles.Kind != yaml.MappingNode {
+		return newYamlError(n, e, v, fmt.Errorf("line %d: profiles must be a mapping", profiles.Line))
+	}
+
+	result := mappingValue(profiles, DefaultProfile)
+	p := mappingValue(profiles, profile)
+	if p == nil {
+		return errorf(ErrNotFound, "config: profile %q not found in %s: %w", profile, n, os.ErrNotExist)
+	}
+	result = mergeYaml(result, p, "", c.s.listMerges[c.fullName(n)])
+
+	err = result.Decode(v)
+	if err != nil {
+		return newYamlError(n, e, v, err)
+	}
+	return nil
+}
+
+// mappingValue returns the value of key k in mapping node m, or nil if there is none.
+func mappingValue(m *yaml.Node, k string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == k {
+			return resolveAlias(m.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node aliased by n if n is an alias, otherwise n.
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// ListMerge controls how a list is merged over the list it overrides. The zero ListMerge
+// replaces the list.
+type ListMerge struct {
+	mode listMode
+	key  string
+}
+
+type listMode int
+
+const (
+	listReplace listMode = iota
+	listAppend
+	listMergeByKey
+)
+
+var (
+	// ListReplace replaces the overridden list. It is the default.
+	ListReplace = ListMerge{mode: listReplace}
+	// ListAppend appends the list to the overridden list.
+	ListAppend = ListMerge{mode: listAppend}
+)
+
+// ListMergeByKey merges lists of mappings by the value of their key: items whose key
+// matches an item of the overridden list are merged over that item, and the others are
+// appended. This allows, for example, a middleware chain to be amended by name.
+func ListMergeByKey(key string) ListMerge {
+	return ListMerge{mode: listMergeByKey, key: key}
+}
+
+// WithListMerge sets how the list at path of configuration value n is merged by Profile.
+// path is a list of mapping keys separated by dots, relative to the profile, such as
+// "server.middleware"; items of lists do not add to it. Lists that have no ListMerge set
+// are replaced.
+func WithListMerge(n, path string, m ListMerge) Option {
+	return func(o *options) {
+		if o.listMerges == nil {
+			o.listMerges = map[string]map[string]ListMerge{}
+		}
+		if o.listMerges[n] == nil {
+			o.listMerges[n] = map[string]ListMerge{}
+		}
+		o.listMerges[n][path] = m
+	}
+}
+
+// mergeYaml returns the result of merging node src over dst, where p is the path of the
+// nodes and lists holds the ListMerge of each path. Mappings are merged key by key, and
+// lists according to their ListMerge; any other src replaces dst. Neither node is
+// modified.
+func mergeYaml(dst, src *yaml.Node, p string, lists map[string]ListMerge) *yaml.Node {
+	if dst != nil && dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode {
+		return mergeYamlList(dst, src, p, lists)
+	}
+	if dst == nil || dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		return src
+	}
+
+	result := *dst
+	result.Content = append([]*yaml.Node(nil), dst.Content...)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		k, v := src.Content[i], resolveAlias(src.Content[i+1])
+		kp := k.Value
+		if p != "" {
+			kp = p + "." + k.Value
+		}
+
+		found := false
+		for j := 0; j+1 < len(result.Content); j += 2 {
+			if result.Content[j].Value == k.Value {
+				result.Content[j+1] = mergeYaml(resolveAlias(result.Content[j+1]), v, kp, lists)
+				found = true
+				break
+			}
+		}
+		if !found {
+			result.Content = append(result.Content, k, v)
+		}
+	}
+	return &result
+}
+
+// mergeYamlList returns the result of merging sequence node src over dst, which are at
+// path p, according to their ListMerge in lists.
+func mergeYamlList(dst, src *yaml.Node, p string, lists map[string]ListMerge) *yaml.Node {
+	m := lists[p]
+	if m.mode == listReplace {
+		return src
+	}
+
+	result := *dst
+	result.Content = append([]*yaml.Node(nil), dst.Content...)
+	for _, item := range src.Content {
+		item = resolveAlias(item)
+		if m.mode == listMergeByKey && item.Kind == yaml.MappingNode {
+			if k := mappingValue(item, m.key); k != nil {
+				if i := findListItem(result.Content, m.key, k.Value); i >= 0 {
+					result.Content[i] = mergeYaml(resolveAlias(result.Content[i]), item, p, lists)
+					continue
+				}
+			}
+		}
+		result.Content = append(result.Content, item)
+	}
+	return &result
+}
+
+// findListItem returns the index of the first mapping in items whose key has value v, or
+// -1 if there is none.
+func findListItem(items []*yaml.Node, key, v string) int {
+	for i, item := range items {
+		item = resolveAlias(item)
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if k := mappingValue(item, key); k != nil && k.Value == v {
+			return i
+		}
+	}
+	return -1
+}
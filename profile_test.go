@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_Profile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml": `default:
+  server: {host: localhost, port: 8080}
+  features: [a, b]
+dev:
+  debug: true
+prod:
+  server: {host: example.com}
+  features: [c]
+`,
+		"list.yaml": "- a\n",
+	})
+	c := New(WithPath(dir))
+
+	type server struct {
+		Host string
+		Port int
+	}
+	type app struct {
+		Server   server
+		Features []string
+		Debug    bool
+	}
+
+	tests := []struct {
+		name    string
+		n       string
+		profile string
+		want    app
+		wantErr error
+	}{
+		{name: "default", n: "app.yaml", profile: DefaultProfile, want: app{Server: server{"localhost", 8080}, Features: []string{"a", "b"}}},
+		{name: "dev", n: "app.yaml", profile: "dev", want: app{Server: server{"localhost", 8080}, Features: []string{"a", "b"}, Debug: true}},
+		{name: "prod", n: "app.yaml", profile: "prod", want: app{Server: server{"example.com", 8080}, Features: []string{"c"}}},
+		{name: "missing profile", n: "app.yaml", profile: "staging", wantErr: os.ErrNotExist},
+		{name: "missing value", n: "missing.yaml", profile: "dev", wantErr: os.ErrNotExist},
+		{name: "not a mapping", n: "list.yaml", profile: "dev", wantErr: &DecodeError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got app
+			err := c.Profile(tt.n, tt.profile, &got)
+			if tt.wantErr != nil {
+				var de *DecodeError
+				if !errors.Is(err, tt.wantErr) && !(errors.As(tt.wantErr, &de) && errors.As(err, &de)) {
+					t.Errorf("Profile() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Profile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Profile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var def app
+	if err := c.Profile("app.yaml", DefaultProfile, &def); err != nil || def.Debug || def.Server.Host != "localhost" {
+		t.Errorf("Profile() = %+v, %v; merging must not modify the default profile", def, err)
+	}
+}
+
+func TestWithListMerge(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml": `default:
+  allow: [10.0.0.0/8]
+  hosts: [a]
+  middleware:
+    - {name: auth, enabled: true}
+    - {name: log, level: info}
+prod:
+  allow: [192.168.0.0/16]
+  hosts: [b]
+  middleware:
+    - {name: log, level: warn}
+    - {name: gzip}
+`,
+	})
+	c := New(
+		WithPath(dir),
+		WithListMerge("app.yaml", "allow", ListAppend),
+		WithListMerge("app.yaml", "middleware", ListMergeByKey("name")),
+	)
+
+	type middleware struct {
+		Name    string
+		Enabled bool
+		Level   string
+	}
+	var got struct {
+		Allow      []string
+		Hosts      []string
+		Middleware []middleware
+	}
+	if err := c.Profile("app.yaml", "prod", &got); err != nil {
+		t.Fatalf("Profile() error = %v", err)
+	}
+
+	if want := []string{"10.0.0.0/8", "192.168.0.0/16"}; !reflect.DeepEqual(got.Allow, want) {
+		t.Errorf("Profile() allow = %q, want %q", got.Allow, want)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(got.Hosts, want) {
+		t.Errorf("Profile() hosts = %q, want %q", got.Hosts, want)
+	}
+	want := []middleware{{Name: "auth", Enabled: true}, {Name: "log", Level: "warn"}, {Name: "gzip"}}
+	if !reflect.DeepEqual(got.Middleware, want) {
+		t.Errorf("Profile() middleware = %+v, want %+v", got.Middleware, want)
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a rate limit parsed by Config.RateLimit.
+type Rate struct {
+	Events   int           // Events is the number of events allowed per Interval.
+	Interval time.Duration // Interval is the period over which Events are allowed.
+	Burst    int           // Burst is the maximum number of events allowed at once.
+}
+
+// Limit returns the sustained rate of r in events per second, as expected by
+// golang.org/x/time/rate.Limit.
+func (r Rate) Limit() float64 {
+	return float64(r.Events) / r.Interval.Seconds()
+}
+
+// rateLimitUnits are the units that a rate limit can be given per.
+var rateLimitUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour,
+}
+
+// RateLimit calls c.String(n) and parses the result as a rate limit of the form
+// "<events>/<interval> [burst=<n>]", such as "100/s" or "5000/m burst=200". The interval is
+// a unit (ms, s, m, h or d, or sec, min, second, minute, hour or day) or a duration as
+// parsed by time.ParseDuration, such as "100/10s". Burst defaults to the number of events
+// allowed per second, rounded up, and at least 1.
+func (c *Config) RateLimit(n string) (Rate, error) {
+	s, err := c.String(n)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	result, err := parseRateLimit(s)
+	if err != nil {
+		return Rate{}, errorf(ErrDecode, "config: failed to unmarshal %s into a rate limit: %w", n, err)
+	}
+	return result, nil
+}
+
+func parseRateLimit(s string) (Rate, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Rate{}, fmt.Errorf("invalid rate limit %q: expected <events>/<interval> [burst=<n>]", s)
+	}
+
+	i := strings.Index(fields[0], "/")
+	if i < 0 {
+		return Rate{}, fmt.Errorf("invalid rate limit %q: expected <events>/<interval>", s)
+	}
+	events, err := strconv.Atoi(fields[0][:i])
+	if err != nil || events < 0 {
+		return Rate{}, fmt.Errorf("invalid rate limit %q: invalid number of events", s)
+	}
+
+	unit := fields[0][i+1:]
+	interval, ok := rateLimitUnits[strings.ToLower(unit)]
+	if !ok {
+		interval, err = time.ParseDuration(unit)
+		if err != nil {
+			return Rate{}, fmt.Errorf("invalid rate limit %q: invalid interval %q", s, unit)
+		}
+	}
+	if interval <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate limit %q: interval must be positive", s)
+	}
+
+	result := Rate{Events: events, Interval: interval}
+	if len(fields) == 2 {
+		if !strings.HasPrefix(fields[1], "burst=") {
+			return Rate{}, fmt.Errorf("invalid rate limit %q: unknown option %q", s, fields[1])
+		}
+		result.Burst, err = strconv.Atoi(strings.TrimPrefix(fields[1], "burst="))
+		if err != nil || result.Burst < 1 {
+			return Rate{}, fmt.Errorf("invalid rate limit %q: invalid burst", s)
+		}
+		return result, nil
+	}
+
+	result.Burst = int(math.Ceil(result.Limit()))
+	if result.Burst < 1 {
+		result.Burst = 1
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_RateLimit(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"api":      "100/s",
+		"bulk":     "5000/m burst=200",
+		"slow":     "1/hour",
+		"window":   "30/10s\n",
+		"noslash":  "100",
+		"unit":     "100/fortnight",
+		"burst":    "100/s burst=0",
+		"option":   "100/s limit=5",
+		"negative": "-1/s",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name    string
+		want    Rate
+		wantErr bool
+	}{
+		{name: "api", want: Rate{Events: 100, Interval: time.Second, Burst: 100}},
+		{name: "bulk", want: Rate{Events: 5000, Interval: time.Minute, Burst: 200}},
+		{name: "slow", want: Rate{Events: 1, Interval: time.Hour, Burst: 1}},
+		{name: "window", want: Rate{Events: 30, Interval: 10 * time.Second, Burst: 3}},
+		{name: "noslash", wantErr: true},
+		{name: "unit", wantErr: true},
+		{name: "burst", wantErr: true},
+		{name: "option", wantErr: true},
+		{name: "negative", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.RateLimit(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RateLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("RateLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got, _ := c.RateLimit("bulk"); got.Limit() != 5000.0/60 {
+		t.Errorf("Limit() = %v, want %v", got.Limit(), 5000.0/60)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// DefaultPrune are the name patterns of the directories that are not searched when
+// searching recursively. They exclude hidden directories such as .git and the ..data
+// directories of Kubernetes volumes, whose files are also linked from the volume's root.
+var DefaultPrune = []string{".*", "node_modules"}
+
+// WithMaxDepth enables recursive searching of search path directories up to depth levels
+// of subdirectories. Files in subdirectories are named by their slash-separated path
+// relative to the search path directory, e.g. "db/primary.yaml". A depth of 0 (the
+// default) disables recursive searching.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// WithPrune sets the name patterns (see path.Match) of the directories that are not
+// searched when searching recursively. It defaults to DefaultPrune; call it with no
+// patterns to search every directory.
+func WithPrune(patterns ...string) Option {
+	return func(o *options) {
+		o.prune = append([]string{}, patterns...)
+	}
+}
+
+// walkDir calls fn for each file in directory p and in its subdirectories up to depth
+// levels deep, following symbolic links. Entries are named by their slash-separated path
+// relative to p. Directories that are not searched, because they are too deep or match one
+// of the prune patterns, are passed to fn along with the reason they were skipped.
+func walkDir(p string, depth int, prune []string, fn func(n, f string, fi os.FileInfo, skip string)) error {
+	return walkTree(p, "", depth, prune, fn)
+}
+
+func walkTree(root, rel string, depth int, prune []string, fn func(n, f string, fi os.FileInfo, skip string)) error {
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	fis, err := ioutil.ReadDir(p)
+	if err != nil {
+		return fmt.Errorf("config: error reading directory %q: %w", p, err)
+	}
+
+	for _, fi := range fis {
+		n := path.Join(rel, fi.Name())
+		f := filepath.Join(p, fi.Name())
+		if fi.Mode()&os.ModeSymlink != 0 {
+			// Kubernetes volumes link ..data to a directory of the current files.
+			if target, err := os.Stat(f); err == nil {
+				fi = target
+			}
+		}
+		if !fi.IsDir() {
+			fn(n, f, fi, "")
+			continue
+		}
+
+		switch {
+		case depth <= 0:
+			fn(n, f, fi, "directory")
+		case pruned(fi.Name(), prune):
+			fn(n, f, fi, "pruned directory")
+		default:
+			if err := walkTree(root, n, depth-1, prune, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pruned reports whether directory name n matches one of the prune patterns.
+func pruned(n string, prune []string) bool {
+	for _, p := range prune {
+		if ok, _ := path.Match(p, n); ok {
+			return true
+		}
+	}
+	return false
+}
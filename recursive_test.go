@@ -0,0 +1,55 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWithMaxDepth(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"name":                      "root",
+		"db/primary.yaml":           "host: db",
+		"db/replica/secondary.yaml": "host: replica",
+		".git/HEAD":                 "ref: refs/heads/main",
+		"node_modules/pkg/index.js": "",
+	})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{name: "default", want: []string{"name"}},
+		{name: "depth 1", opts: []Option{WithMaxDepth(1)}, want: []string{"db/primary.yaml", "name"}},
+		{name: "depth 2", opts: []Option{WithMaxDepth(2)}, want: []string{"db/primary.yaml", "db/replica/secondary.yaml", "name"}},
+		{name: "no prune", opts: []Option{WithMaxDepth(1), WithPrune()}, want: []string{".git/HEAD", "db/primary.yaml", "name"}},
+		{name: "custom prune", opts: []Option{WithMaxDepth(2), WithPrune("replica", ".*", "node_modules")}, want: []string{"db/primary.yaml", "name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(append([]Option{WithPath(dir)}, tt.opts...)...)
+			if err := c.Load(); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			var got []string
+			for _, sr := range c.Report().Sources {
+				for _, f := range sr.Files {
+					if f.Skipped == "" {
+						got = append(got, f.Name)
+					}
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() names = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	c := New(WithPath(dir), WithMaxDepth(1))
+	if got, err := c.String("db/primary.yaml"); err != nil || got != "host: db" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "host: db")
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedisConn is the subset of a Redis client used by RedisSource. For
+// github.com/go-redis/redis, an adapter looks like this:
+//		type redisConn struct{ *redis.Client }
+//
+//		func (c redisConn) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+//			return c.Client.Scan(ctx, cursor, match, count).Result()
+//		}
+//
+//		func (c redisConn) Get(ctx context.Context, key string) (string, bool, error) {
+//			v, err := c.Client.Get(ctx, key).Result()
+//			if err == redis.Nil {
+//				return "", false, nil
+//			}
+//			return v, err == nil, err
+//		}
+//
+//		func (c redisConn) PSubscribe(ctx context.Context, pattern string) (<-chan struct{}, error) {
+//			sub := c.Client.PSubscribe(ctx, pattern)
+//			result := make(chan struct{})
+//			go func() {
+//				defer close(result)
+//				for range sub.Channel() {
+//					result <- struct{}{}
+//				}
+//			}()
+//			return result, nil
+//		}
+//
+// with Type and HGetAll following the pattern of Scan.
+type RedisConn interface {
+	// Scan returns a page of the keys matching match, starting at cursor, along with the
+	// cursor of the next page, which is 0 after the last page. Count is a hint of the
+	// number of keys to return. Keys may be returned more than once.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+	// Type returns the type of the value stored at key, e.g. "string" or "hash".
+	Type(ctx context.Context, key string) (string, error)
+	// Get returns the string value stored at key, and false if key does not exist.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// HGetAll returns the fields and values of the hash stored at key.
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// PSubscribe subscribes to the channels matching pattern. The returned channel
+	// receives a value for every message published and is closed when the
+	// subscription ends.
+	PSubscribe(ctx context.Context, pattern string) (<-chan struct{}, error)
+}
+
+// RedisSource is a Source that provides the keys in a Redis database that start with
+// Prefix, named by the remainder of the key after Prefix. Each field of a hash is provided
+// individually as "<name>.<field>". Keys of other types are ignored. The keys are listed
+// with SCAN, so listing does not block the server, and keys deleted while they are read
+// are left out.
+//
+// RedisSource implements Notifier using keyspace notifications, which must be enabled on
+// the server (e.g. notify-keyspace-events "KA"), so Watch reloads the Config when a key
+// under Prefix changes.
+type RedisSource struct {
+	Conn   RedisConn
+	Prefix string
+	DB     int // DB is the database number, used to subscribe to keyspace notifications.
+
+	changeNotifier
+	subscribe sync.Once
+}
+
+// Redis returns a RedisSource for the keys in database 0 that start with prefix.
+func Redis(conn RedisConn, prefix string) *RedisSource {
+	return &RedisSource{Conn: conn, Prefix: prefix}
+}
+
+func (s *RedisSource) Files(ctx context.Context) ([]File, error) {
+	s.subscribe.Do(s.watch)
+	s.reset()
+
+	keys, err := s.keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to list redis keys with prefix %q: %w", s.Prefix, err)
+	}
+
+	var result []File
+	for _, k := range keys {
+		n := strings.TrimPrefix(k, s.Prefix)
+
+		t, err := s.Conn.Type(ctx, k)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to read redis key %q: %w", k, err)
+		}
+
+		switch t {
+		case "string":
+			v, ok, err := s.Conn.Get(ctx, k)
+			if err != nil {
+				return nil, fmt.Errorf("config: failed to read redis key %q: %w", k, err)
+			}
+			if !ok {
+				continue
+			}
+			result = append(result, File{Name: n, Path: "redis://" + k, Data: []byte(v)})
+		case "hash":
+			m, err := s.Conn.HGetAll(ctx, k)
+			if err != nil {
+				return nil, fmt.Errorf("config: failed to read redis key %q: %w", k, err)
+			}
+			fields := make([]string, 0, len(m))
+			for f := range m {
+				fields = append(fields, f)
+			}
+			sort.Strings(fields)
+			for _, f := range fields {
+				result = append(result, File{Name: n + "." + f, Path: "redis://" + k + "#" + f, Data: []byte(m[f])})
+			}
+		}
+	}
+	return result, nil
+}
+
+// redisScanCount is the number of keys RedisSource asks for in each SCAN.
+const redisScanCount = 100
+
+// keys returns the sorted keys under s.Prefix.
+func (s *RedisSource) keys(ctx context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	var result []string
+	var cursor uint64
+	for {
+		keys, next, err := s.Conn.Scan(ctx, cursor, redisEscape(s.Prefix)+"*", redisScanCount)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				result = append(result, k)
+			}
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// watch subscribes to the keyspace notifications for keys under s.Prefix. Failing to
+// subscribe is not fatal; the source simply never reports changes.
+func (s *RedisSource) watch() {
+	pattern := fmt.Sprintf("__keyspace@%d__:%s*", s.DB, redisEscape(s.Prefix))
+	events, err := s.Conn.PSubscribe(context.Background(), pattern)
+	if err != nil {
+		log.Printf("config: failed to subscribe to redis keyspace notifications %q: %v", pattern, err)
+		return
+	}
+
+	go func() {
+		for range events {
+			s.notify()
+		}
+	}()
+}
+
+// redisEscape escapes the glob-style pattern characters in s.
+func redisEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
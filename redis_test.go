@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-memory RedisConn. It scans two keys at a time, returning the last key
+// of each page again at the start of the next, as SCAN may. Keys in deleted are scanned and
+// typed as strings, as if they were deleted just before being read.
+type fakeRedis struct {
+	mu       sync.Mutex
+	strings  map[string]string
+	hashes   map[string]map[string]string
+	deleted  map[string]bool
+	patterns []string
+	events   chan struct{}
+}
+
+func (r *fakeRedis) Scan(_ context.Context, cursor uint64, match string, _ int64) ([]string, uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var all []string
+	for _, m := range []map[string]string{r.strings, r.keysOf(r.hashes)} {
+		for k := range m {
+			if ok, _ := path.Match(match, k); ok {
+				all = append(all, k)
+			}
+		}
+	}
+	for k := range r.deleted {
+		if ok, _ := path.Match(match, k); ok {
+			all = append(all, k)
+		}
+	}
+	sort.Strings(all)
+
+	start := int(cursor)
+	if start > 0 {
+		start--
+	}
+	end := int(cursor) + 2
+	if end >= len(all) {
+		return all[start:], 0, nil
+	}
+	return all[start:end], uint64(end), nil
+}
+
+func (r *fakeRedis) keysOf(m map[string]map[string]string) map[string]string {
+	result := map[string]string{}
+	for k := range m {
+		result[k] = ""
+	}
+	return result
+}
+
+func (r *fakeRedis) Type(_ context.Context, key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.strings[key]; ok || r.deleted[key] {
+		return "string", nil
+	}
+	if _, ok := r.hashes[key]; ok {
+		return "hash", nil
+	}
+	return "none", nil
+}
+
+func (r *fakeRedis) Get(_ context.Context, key string) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.strings[key]
+	return v, ok, nil
+}
+
+func (r *fakeRedis) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.hashes[key], nil
+}
+
+func (r *fakeRedis) PSubscribe(_ context.Context, pattern string) (<-chan struct{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.patterns = append(r.patterns, pattern)
+	return r.events, nil
+}
+
+func (r *fakeRedis) set(key, value string) {
+	r.mu.Lock()
+	r.strings[key] = value
+	r.mu.Unlock()
+
+	r.events <- struct{}{}
+}
+
+func TestRedisSource(t *testing.T) {
+	r := &fakeRedis{
+		strings: map[string]string{
+			"cfg:db_url":  "postgres://redis",
+			"cfg:timeout": "1s",
+			"other:key":   "ignored",
+		},
+		hashes: map[string]map[string]string{
+			"cfg:limits": {"rps": "100", "burst": "20"},
+		},
+		deleted: map[string]bool{"cfg:gone": true},
+		events:  make(chan struct{}),
+	}
+	c := New(WithPath(""), WithSource(Redis(r, "cfg:")))
+
+	for n, want := range map[string]string{
+		"db_url":       "postgres://redis",
+		"limits.rps":   "100",
+		"limits.burst": "20",
+	} {
+		if got, err := c.String(n); err != nil || got != want {
+			t.Errorf("String(%q) = %q, %v, want %q", n, got, err, want)
+		}
+	}
+	for _, n := range []string{"key", "gone"} {
+		if _, err := c.Bytes(n); err == nil {
+			t.Errorf("Bytes(%q) error = %v, wantErr %v", n, err, true)
+		}
+	}
+	if got := c.s.names(); len(got) != 4 {
+		t.Errorf("names() = %q, want 4 names", got)
+	}
+	if want := []string{"__keyspace@0__:cfg:*"}; strings.Join(r.patterns, ",") != want[0] {
+		t.Errorf("PSubscribe() patterns = %q, want %q", r.patterns, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = c.Watch(ctx)
+	}()
+
+	r.set("cfg:timeout", "2s")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := c.Duration("timeout")
+		if err == nil && got == 2*time.Second {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Duration() = %v, %v, want %v after change", got, err, 2*time.Second)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func Test_redisEscape(t *testing.T) {
+	if got, want := redisEscape(`a*b?[c]\`), `a\*b\?\[c\]\\`; got != want {
+		t.Errorf("redisEscape() = %q, want %q", got, want)
+	}
+}
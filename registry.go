@@ -0,0 +1,62 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// SourceFactory returns the Source for a search path entry, which is passed to it parsed as
+// a URL. See RegisterSource.
+type SourceFactory func(u *url.URL) (Source, error)
+
+// RegisterSource makes the sources returned by factory available in search paths as
+// entries of the form "scheme://...". It allows other modules to provide sources, such as
+// proprietary secret stores or inventory databases, without changes to this package:
+//
+//		func init() {
+//			config.RegisterSource("vault", func(u *url.URL) (config.Source, error) {
+//				return newVaultSource(u)
+//			})
+//		}
+//
+// Sources returned by factory are used like those added with WithSource. Source is stable:
+// capabilities added in later versions, like Notifier and fmt.Stringer before them, are
+// separate interfaces that a Source may optionally implement, so existing sources keep
+// compiling and working.
+//
+// Schemes must be valid URL schemes of at least two characters, so that they are not
+// mistaken for drive letters. RegisterSource panics if scheme is invalid, factory is nil,
+// or scheme is already registered, including by this package (e.g. "http" or "exec").
+// It is meant to be called from init functions.
+func RegisterSource(scheme string, factory SourceFactory) {
+	if !schemeRegexp.MatchString(scheme) {
+		panic("config: RegisterSource: invalid scheme " + scheme)
+	}
+	if factory == nil {
+		panic("config: RegisterSource: factory is nil for scheme " + scheme)
+	}
+
+	pathSourcesMu.Lock()
+	defer pathSourcesMu.Unlock()
+	if _, ok := pathSources[scheme]; ok {
+		panic("config: RegisterSource called twice for scheme " + scheme)
+	}
+	pathSources[scheme] = factory
+}
+
+// schemeRegexp matches the schemes accepted by RegisterSource.
+var schemeRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]+$`)
+
+// pathSourcesMu guards pathSources and opaquePathSources against sources registered
+// while search paths are being read.
+var pathSourcesMu sync.RWMutex
+
+// pathSourceFactory returns the SourceFactory registered for scheme, and whether entries
+// with the scheme are opaque (see opaquePathSources).
+func pathSourceFactory(scheme string) (factory SourceFactory, opaque bool, ok bool) {
+	pathSourcesMu.RLock()
+	defer pathSourcesMu.RUnlock()
+	factory, ok = pathSources[scheme]
+	return factory, opaquePathSources[scheme], ok
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+// tableSource is a Source provided by a registered scheme in tests.
+type tableSource struct {
+	table string
+}
+
+func (s tableSource) Files(context.Context) ([]File, error) {
+	return []File{{Name: "table", Path: "cmdb:" + s.table, Data: []byte(s.table)}}, nil
+}
+
+func init() {
+	RegisterSource("cmdb-test", func(u *url.URL) (Source, error) {
+		return tableSource{table: u.Host}, nil
+	})
+}
+
+func TestRegisterSource(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "value"})
+	c := New(WithPath(dir + ":cmdb-test://hosts"))
+	got, err := c.String("table")
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	if got != "hosts" {
+		t.Errorf("String() got = %q, want %q", got, "hosts")
+	}
+	if got, err := c.String("name"); err != nil || got != "value" {
+		t.Errorf("String() got = %q, %v, want %q", got, err, "value")
+	}
+}
+
+func TestRegisterSource_panics(t *testing.T) {
+	factory := func(u *url.URL) (Source, error) { return tableSource{}, nil }
+
+	tests := []struct {
+		name    string
+		scheme  string
+		factory SourceFactory
+	}{
+		{name: "builtin", scheme: "http", factory: factory},
+		{name: "drive letter", scheme: "c", factory: factory},
+		{name: "invalid", scheme: "my_source", factory: factory},
+		{name: "nil factory", scheme: "nil-test", factory: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterSource(%q) did not panic", tt.scheme)
+				}
+			}()
+			RegisterSource(tt.scheme, tt.factory)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+)
+
+// RemovalPolicy controls what happens to a loaded configuration value when its file is no
+// longer found the next time the Config is reloaded.
+type RemovalPolicy int
+
+const (
+	// RemoveMissing removes the value, so reading it afterwards fails with os.ErrNotExist.
+	RemoveMissing RemovalPolicy = iota
+
+	// KeepMissing keeps the last known version of the value, marks it stale (see IsStale)
+	// and produces a WarnStale Warning. The value is no longer stale once its file is back.
+	KeepMissing
+
+	// FailOnMissing fails the reload with a *RemovedError, keeping the previously loaded
+	// values.
+	FailOnMissing
+)
+
+func (p RemovalPolicy) String() string {
+	switch p {
+	case RemoveMissing:
+		return "remove"
+	case KeepMissing:
+		return "keep"
+	case FailOnMissing:
+		return "fail"
+	}
+	return fmt.Sprintf("RemovalPolicy(%d)", int(p))
+}
+
+// removalRule is the RemovalPolicy of the names matching pattern.
+type removalRule struct {
+	pattern string
+	policy  RemovalPolicy
+}
+
+// WithRemovalPolicy sets the RemovalPolicy of the configuration values whose names match
+// pattern, as accepted by path.Match. If a name matches more than one pattern, the policy
+// added first applies; names that match none are removed. This protects critical values
+// from transient blips, such as an NFS mount or a ConfigMap briefly missing a file:
+//
+//		c := config.New(
+//			config.WithRemovalPolicy("db_*", config.KeepMissing),
+//			config.WithRemovalPolicy("tls.*", config.FailOnMissing),
+//		)
+//
+// Policies apply to reloads and Stage; the first load has no previous values to keep.
+func WithRemovalPolicy(pattern string, p RemovalPolicy) Option {
+	return func(o *options) {
+		o.removals = append(o.removals, removalRule{pattern: pattern, policy: p})
+	}
+}
+
+// RemovedError is returned by Reload when the file of a configuration value with the
+// FailOnMissing policy is gone. It wraps os.ErrNotExist.
+type RemovedError struct {
+	Name string // Name is the name of the configuration value.
+	Path string // Path is the file the value was previously read from.
+}
+
+func (e *RemovedError) Error() string {
+	return fmt.Sprintf("config: config entry %q was removed (previously read from %s)", e.Name, e.Path)
+}
+
+func (e *RemovedError) Unwrap() error {
+	return os.ErrNotExist
+}
+
+func (e *RemovedError) Is(target error) bool {
+	return target == ErrSource
+}
+
+// IsStale reports whether configuration value n is a last known version kept by the
+// KeepMissing policy after its file disappeared. It does not load c.
+func (c *Config) IsStale(n string) bool {
+	n = c.fullName(n)
+	for s := c.s; s != nil; s = s.parent {
+		s.mu.RLock()
+		e, ok := s.val[n]
+		s.mu.RUnlock()
+		if ok {
+			return e.stale
+		}
+	}
+	return false
+}
+
+// removalPolicy returns the RemovalPolicy of configuration value n.
+func (o *options) removalPolicy(n string) RemovalPolicy {
+	for _, r := range o.removals {
+		if ok, _ := path.Match(r.pattern, n); ok {
+			return r.policy
+		}
+	}
+	return RemoveMissing
+}
+
+// applyRemovals applies the removal policies of s to the loaded values of s that are
+// missing from result, restoring the ones that are kept.
+func (s *store) applyRemovals(result map[string]entry) error {
+	if len(s.removals) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	prev := s.val
+	s.mu.RUnlock()
+
+	var missing []string
+	for n := range prev {
+		if _, ok := result[n]; !ok {
+			missing = append(missing, n)
+		}
+	}
+	sort.Strings(missing)
+
+	for _, n := range missing {
+		e := prev[n].unpacked()
+		switch s.removalPolicy(n) {
+		case KeepMissing:
+			e.stale = true
+			result[n] = e
+			s.warn(Warning{Kind: WarnStale, Name: n, Path: e.path, Message: fmt.Sprintf("%s is gone; keeping the last known value of %q", e.path, n)}, true)
+		case FailOnMissing:
+			return &RemovedError{Name: n, Path: e.path}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithRemovalPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RemovalPolicy
+		wantErr   bool
+		wantValue bool
+		wantStale bool
+	}{
+		{name: "remove", policy: RemoveMissing},
+		{name: "keep", policy: KeepMissing, wantValue: true, wantStale: true},
+		{name: "fail", policy: FailOnMissing, wantErr: true, wantValue: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"db_url": "postgres://db", "other": "x"})
+			var warnings []Warning
+			c := New(
+				WithPath(dir),
+				WithRemovalPolicy("db_*", tt.policy),
+				WithRemovalPolicy("*", RemoveMissing),
+				WithWarnings(func(w Warning) { warnings = append(warnings, w) }),
+			)
+			if err := c.Load(); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if err := os.Remove(filepath.Join(dir, "db_url")); err != nil {
+				t.Fatal(err)
+			}
+			err := c.Reload()
+			var re *RemovedError
+			if tt.wantErr != errors.As(err, &re) {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && (re.Name != "db_url" || !errors.Is(err, os.ErrNotExist)) {
+				t.Errorf("Reload() error = %#v", re)
+			}
+
+			got, err := c.String("db_url")
+			if (err == nil) != tt.wantValue || tt.wantValue && got != "postgres://db" {
+				t.Errorf("String() got = %q, %v, want value %v", got, err, tt.wantValue)
+			}
+			if got := c.IsStale("db_url"); got != tt.wantStale {
+				t.Errorf("IsStale() got = %v, want %v", got, tt.wantStale)
+			}
+			if got := len(warnings) == 1 && warnings[0].Kind == WarnStale; got != tt.wantStale {
+				t.Errorf("warnings = %v, want stale warning %v", warnings, tt.wantStale)
+			}
+
+			if err := ioutil.WriteFile(filepath.Join(dir, "db_url"), []byte("postgres://db2"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Reload(); err != nil {
+				t.Fatalf("Reload() error = %v", err)
+			}
+			if got, _ := c.String("db_url"); got != "postgres://db2" || c.IsStale("db_url") {
+				t.Errorf("String() after restore got = %q, stale %v", got, c.IsStale("db_url"))
+			}
+		})
+	}
+}
+
+func TestRemovalPolicy_String(t *testing.T) {
+	for p, want := range map[RemovalPolicy]string{RemoveMissing: "remove", KeepMissing: "keep", FailOnMissing: "fail", 7: "RemovalPolicy(7)"} {
+		if got := p.String(); got != want {
+			t.Errorf("String() got = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"time"
+)
+
+// LoadReport describes the most recent attempt to load a Config.
+type LoadReport struct {
+	Path     string         // Path is the search path that was loaded.
+	Start    time.Time      // Start is when loading started.
+	Duration time.Duration  // Duration is how long loading took.
+	Err      error          // Err is the error that stopped loading, if any.
+	Sources  []SourceReport // Sources describe the directories and sources read, in order.
+	Warnings []error        // Warnings are the *FileErrors tolerated because of the ErrorPolicy.
+}
+
+// SourceReport describes the files read from a search path directory or Source.
+type SourceReport struct {
+	Source   string        // Source is the search path entry, or a description of the Source.
+	Priority int           // Priority is the priority of the source; see WithSourcePriority.
+	Duration time.Duration // Duration is how long reading the source took.
+	Err      error         // Err is the error returned while reading the source, if any.
+	Missing  bool          // Missing is true if the source is an optional search path entry that does not exist.
+	Files    []FileReport  // Files describe the files provided by or skipped in the source.
+}
+
+// FileReport describes a single file found while loading a Config.
+type FileReport struct {
+	Name    string // Name is the name of the configuration value.
+	Path    string // Path is where the file was read from.
+	Size    int    // Size is the length of the file's data in bytes.
+	Skipped string // Skipped is the reason the file was not loaded, or "" if it was.
+
+	// Decode is the outcome of the most recent attempt to decode the value as JSON or
+	// YAML: "" if it has not been decoded, "ok", or the error message.
+	Decode string
+
+	err error // err is the error that caused the file to be skipped, if it is subject to the ErrorPolicy.
+}
+
+// Report returns a report of the most recent attempt to load or reload c, including
+// attempts that failed. It returns the zero LoadReport if c has not been loaded.
+func (c *Config) Report() LoadReport {
+	s := c.s
+	s.mu.RLock()
+	r := s.report
+	s.mu.RUnlock()
+	if r == nil {
+		return LoadReport{}
+	}
+
+	result := *r
+	result.Warnings = append([]error(nil), r.Warnings...)
+	result.Sources = make([]SourceReport, len(r.Sources))
+	for i, sr := range r.Sources {
+		sr.Files = append([]FileReport(nil), sr.Files...)
+		for j, f := range sr.Files {
+			if f.Skipped != "" {
+				continue
+			}
+			if v, ok := s.decodeStatus.Load(f.Name); ok {
+				sr.Files[j].Decode = v.(string)
+			}
+		}
+		result.Sources[i] = sr
+	}
+	return result
+}
+
+// loaded returns the paths of the files that were loaded.
+func (r *LoadReport) loaded() []string {
+	var result []string
+	for _, sr := range r.Sources {
+		for _, f := range sr.Files {
+			if f.Skipped == "" {
+				result = append(result, f.Path)
+			}
+		}
+	}
+	return result
+}
+
+// skip marks the loaded file e as skipped for reason.
+func (r *LoadReport) skip(e entry, reason string) {
+	files := r.Sources[e.index].Files
+	for i := range files {
+		if files[i].Path == e.path && files[i].Skipped == "" {
+			files[i].Skipped = reason
+		}
+	}
+}
+
+// recordDecode records the outcome of decoding configuration value n for Report.
+func (s *store) recordDecode(n string, err error) {
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	s.decodeStatus.Store(n, status)
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig_Report(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/good":       `{"a": 1}`,
+		"a/bad":        `{"a": `,
+		"a/sub/x":      "x",
+		"b/good":       "shadowed",
+		"b/SHA256SUMS": "",
+	})
+	p := strings.Join([]string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, string(os.PathListSeparator))
+
+	c := New(WithPath(p), WithShadow(true), WithManifest(""))
+	if r := c.Report(); r.Sources != nil {
+		t.Errorf("Report() before Load() = %+v, want zero value", r)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var v interface{}
+	if err := c.InterfaceJson("good", &v); err != nil {
+		t.Fatalf("InterfaceJson() error = %v", err)
+	}
+	if err := c.InterfaceJson("bad", &v); err == nil {
+		t.Fatalf("InterfaceJson() error = nil, want error")
+	}
+
+	r := c.Report()
+	if r.Path != p || r.Err != nil || r.Start.IsZero() {
+		t.Errorf("Report() = %+v, want path %q and no error", r, p)
+	}
+	if len(r.Sources) != 2 {
+		t.Fatalf("Report() sources = %+v, want 2", r.Sources)
+	}
+
+	files := map[string]FileReport{}
+	for _, sr := range r.Sources {
+		for _, f := range sr.Files {
+			files[f.Path] = f
+		}
+	}
+
+	tests := []struct {
+		path        string
+		wantSkipped string
+		wantDecode  string
+		wantSize    int
+	}{
+		{path: "a/good", wantDecode: "ok", wantSize: 8},
+		{path: "a/bad", wantDecode: "config: failed to unmarshal bad", wantSize: 6},
+		{path: "a/sub", wantSkipped: "directory"},
+		{path: "b/good", wantSkipped: "shadowed by " + filepath.Join(dir, "a", "good"), wantSize: 8},
+		{path: "b/SHA256SUMS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f, ok := files[filepath.Join(dir, filepath.FromSlash(tt.path))]
+			if !ok {
+				t.Fatalf("Report() has no file %s", tt.path)
+			}
+			if f.Skipped != tt.wantSkipped {
+				t.Errorf("Report() skipped = %q, want %q", f.Skipped, tt.wantSkipped)
+			}
+			if !strings.HasPrefix(f.Decode, tt.wantDecode) || (tt.wantDecode == "") != (f.Decode == "") {
+				t.Errorf("Report() decode = %q, want %q", f.Decode, tt.wantDecode)
+			}
+			if f.Size != tt.wantSize {
+				t.Errorf("Report() size = %d, want %d", f.Size, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestConfig_Report_failed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a/name": "a"})
+	missing := filepath.Join(dir, "missing")
+	c := New(WithPath(filepath.Join(dir, "a") + string(os.PathListSeparator) + missing))
+
+	err := c.Load()
+	if err == nil {
+		t.Fatalf("Load() error = nil, want error")
+	}
+
+	r := c.Report()
+	if !errors.Is(r.Err, os.ErrNotExist) {
+		t.Errorf("Report() error = %v, want %v", r.Err, os.ErrNotExist)
+	}
+	if len(r.Sources) != 2 || r.Sources[1].Source != missing || r.Sources[1].Err == nil {
+		t.Errorf("Report() sources = %+v, want failure reading %s", r.Sources, missing)
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLoadRetry is how long a Config waits after its initial load fails before trying
+// again. See WithLoadRetry.
+const DefaultLoadRetry = time.Second
+
+// WithLoadRetry sets how long a Config waits after its initial load fails before Load,
+// or any accessor that calls it, tries to load it again. Until then, the error of the
+// failed load is returned without reading the search path. This lets a Config recover
+// from transient failures, such as a directory that has not been mounted yet, rather
+// than returning the same error forever. A d of 0 retries on every call, and a negative
+// d never retries: the error is returned until Reload succeeds.
+func WithLoadRetry(d time.Duration) Option {
+	return func(o *options) {
+		o.loadRetry = d
+	}
+}
+
+// loadOnce is like sync.Once, except that a function that fails can be called again.
+// It guards the initial load of a store. The zero value is ready to use.
+type loadOnce struct {
+	done uint32 // done is 1 once a call has succeeded. It is read without holding mu.
+
+	mu       sync.Mutex
+	failedAt time.Time // failedAt is when the last call failed, if any.
+}
+
+// Do calls f, unless a previous call to f succeeded or failed less than retry ago. A
+// negative retry means a failed call is never retried. Do reports whether f was called.
+// Like sync.Once, no call to Do returns until the call to f it may be waiting on does.
+func (o *loadOnce) Do(f func() error, retry time.Duration) bool {
+	if atomic.LoadUint32(&o.done) == 1 {
+		return false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.done == 1 {
+		return false
+	}
+	if !o.failedAt.IsZero() && (retry < 0 || timeNow().Sub(o.failedAt) < retry) {
+		return false
+	}
+
+	err := f()
+	if err != nil {
+		o.failedAt = timeNow()
+		return true
+	}
+	atomic.StoreUint32(&o.done, 1)
+	return true
+}
+
+// Loaded reports whether a call to Do has succeeded or MarkLoaded has been called.
+func (o *loadOnce) Loaded() bool {
+	return atomic.LoadUint32(&o.done) == 1
+}
+
+// MarkLoaded records that the initial load happened by other means, such as a commit, so
+// that f is not called by later calls to Do.
+func (o *loadOnce) MarkLoaded() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	atomic.StoreUint32(&o.done, 1)
+}
+
+// setLoaded sets whether the initial load has happened, without waiting for a call to Do
+// in progress, so that functions called by f can read the values it loaded. It returns
+// the previous setting.
+func (o *loadOnce) setLoaded(loaded bool) bool {
+	var v uint32
+	if loaded {
+		v = 1
+	}
+	return atomic.SwapUint32(&o.done, v) == 1
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithLoadRetry(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	tests := []struct {
+		name    string
+		retry   time.Duration
+		elapsed time.Duration
+		wantErr bool
+	}{
+		{name: "within interval", retry: time.Minute, elapsed: 30 * time.Second, wantErr: true},
+		{name: "after interval", retry: time.Minute, elapsed: time.Minute},
+		{name: "every call", retry: 0},
+		{name: "never", retry: -1, elapsed: time.Hour, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+			dir := filepath.Join(writeFiles(t, nil), "mnt")
+			c := New(WithPath(dir), WithLoadRetry(tt.retry))
+			if _, err := c.String("name"); err == nil {
+				t.Fatal("String() error = nil before the directory exists")
+			}
+
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, "name"), []byte("value"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			now = now.Add(tt.elapsed)
+
+			got, err := c.String("name")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("String() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != "value" {
+				t.Errorf("String() got = %q, want %q", got, "value")
+			}
+			if tt.wantErr {
+				if err := c.Reload(); err != nil {
+					t.Fatalf("Reload() error = %v", err)
+				}
+				if _, err := c.String("name"); err != nil {
+					t.Errorf("String() after Reload() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_Load_concurrent(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "value"})
+	c := New(WithPath(dir))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.String("name"); err != nil {
+				t.Errorf("String() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := c.Generation(); got != 1 {
+		t.Errorf("Generation() got = %d, want 1", got)
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ScheduleSeparator separates the name of a configuration value from the time at which a
+// scheduled version of it takes effect, as in "fees.yaml@2026-11-01".
+const ScheduleSeparator = "@"
+
+// ScheduledValue is a version of a configuration value that takes effect in the future.
+type ScheduledValue struct {
+	Name      string    // Name is the name of the configuration value.
+	Path      string    // Path is the file the scheduled version was read from.
+	Effective time.Time // Effective is when the scheduled version replaces the current one.
+}
+
+// scheduledEntry is a version of configuration value name that takes effect at at.
+type scheduledEntry struct {
+	name string
+	at   time.Time
+	e    entry
+}
+
+// Schedule calls c.Load() then returns the scheduled versions of the configuration values
+// of c that have not taken effect yet, soonest first. A value is scheduled to change by
+// providing a file named after it, followed by ScheduleSeparator and the time at which it
+// takes effect, such as
+//		fees.yaml                       # the current fees
+//		fees.yaml@2026-11-01            # the fees from midnight UTC on November 1st
+//		fees.yaml@2027-01-01T09:00:00Z  # the fees from 9am UTC on January 1st
+//
+// Times are in RFC 3339 format, or dates alone, which are taken as midnight UTC. Once its
+// time has passed, the latest version of a value replaces the file without a time, so
+// accessors return the active version and the scheduled files are not values of their
+// own. Files whose names contain ScheduleSeparator but do not end in a time are ordinary
+// values. Scheduled versions are checked by the validators and schemas of the value when
+// they are loaded, not when they take effect.
+//
+// A Config switches to a scheduled version the first time it is read after the version
+// takes effect, and Watch switches at that time, so subscribers registered with OnChange
+// are told about the change as it happens, with the trigger "Schedule".
+func (c *Config) Schedule() ([]ScheduledValue, error) {
+	err := c.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.s.mu.RLock()
+	defer c.s.mu.RUnlock()
+	var result []ScheduledValue
+	for _, x := range c.s.schedule {
+		if strings.HasPrefix(x.name, c.prefix) {
+			result = append(result, ScheduledValue{Name: strings.TrimPrefix(x.name, c.prefix), Path: x.e.path, Effective: x.at})
+		}
+	}
+	return result, nil
+}
+
+// splitSchedule removes the scheduled versions of values from result, replacing each value
+// with the latest of its versions that has taken effect at now, and returns the versions
+// that have not, soonest first.
+func splitSchedule(report *LoadReport, result map[string]entry, now time.Time) []scheduledEntry {
+	var active, pending []scheduledEntry
+	for n, e := range result {
+		i := strings.LastIndex(n, ScheduleSeparator)
+		if i <= 0 {
+			continue
+		}
+		at, err := parseExpiry(n[i+len(ScheduleSeparator):])
+		if err != nil {
+			continue
+		}
+
+		delete(result, n)
+		x := scheduledEntry{name: n[:i], at: at, e: e}
+		if at.After(now) {
+			pending = append(pending, x)
+			if report != nil {
+				report.skip(e, "scheduled for "+at.Format(time.RFC3339))
+			}
+		} else {
+			active = append(active, x)
+		}
+	}
+
+	sortSchedule(active)
+	for _, x := range active {
+		if prev, ok := result[x.name]; ok && report != nil {
+			report.skip(prev, "superseded by "+x.e.path)
+		}
+		result[x.name] = x.e
+	}
+	sortSchedule(pending)
+	return pending
+}
+
+// sortSchedule sorts xs by the time they take effect, then by name.
+func sortSchedule(xs []scheduledEntry) {
+	sort.Slice(xs, func(i, j int) bool {
+		if !xs[i].at.Equal(xs[j].at) {
+			return xs[i].at.Before(xs[j].at)
+		}
+		return xs[i].name < xs[j].name
+	})
+}
+
+// checkSchedule runs the validators and schemas of each scheduled value in xs on it.
+func (s *store) checkSchedule(xs []scheduledEntry) error {
+	for _, x := range xs {
+		err := s.check(map[string]entry{x.name: x.e})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSchedule records xs as the scheduled versions of the values of s. s.mu must be held.
+func (s *store) setSchedule(xs []scheduledEntry) {
+	s.schedule = xs
+	var next int64
+	if len(xs) > 0 {
+		next = xs[0].at.UnixNano()
+	}
+	atomic.StoreInt64(&s.nextSwitch, next)
+}
+
+// nextSchedule returns when the next scheduled version of a value of s takes effect, or
+// the zero time if there is none.
+func (s *store) nextSchedule() time.Time {
+	next := atomic.LoadInt64(&s.nextSwitch)
+	if next == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, next)
+}
+
+// switchSchedule replaces the values of s with their scheduled versions that have taken
+// effect, if any, and commits the result. Only one caller switches at a time; the others
+// return nil immediately.
+func (s *store) switchSchedule() error {
+	next := atomic.LoadInt64(&s.nextSwitch)
+	if next == 0 {
+		return nil
+	}
+	now := timeNow()
+	if now.UnixNano() < next || !atomic.CompareAndSwapInt64(&s.nextSwitch, next, 0) {
+		return nil
+	}
+
+	s.mu.RLock()
+	val := make(map[string]entry, len(s.val))
+	for n, e := range s.val {
+		val[n] = e
+	}
+	i := 0
+	for ; i < len(s.schedule) && !s.schedule[i].at.After(now); i++ {
+		val[s.schedule[i].name] = s.schedule[i].e
+	}
+	st := &Staged{s: s, path: s.resolved, val: val, report: s.report, schedule: s.schedule[i:], committed: true}
+	s.mu.RUnlock()
+
+	err := st.commit("Schedule")
+	if err != nil {
+		return fmt.Errorf("config: failed to switch to scheduled config: %w", err)
+	}
+	return nil
+}
+
+// switchScheduleOrLog calls s.switchSchedule, logging its error, if any.
+func (s *store) switchScheduleOrLog() {
+	err := s.switchSchedule()
+	if err != nil {
+		log.Printf("%v", err)
+	}
+}
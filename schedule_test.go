@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfig_Schedule(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"fee":                      "1.00",
+		"fee@2026-10-01":           "0.90",
+		"fee@2026-11-01":           "1.10",
+		"fee@2026-12-01T09:00:00Z": "1.20",
+		"user@example":             "not scheduled",
+	})
+	c := New(WithPath(dir))
+	var events []ChangeEvent
+	c.OnChange(func(ev ChangeEvent) { events = append(events, ev) })
+
+	want := []ScheduledValue{
+		{Name: "fee", Effective: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "fee", Effective: time.Date(2026, 12, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	got, err := c.Schedule()
+	if err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	for i := range got {
+		got[i].Path = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Schedule() got = %v, want %v", got, want)
+	}
+
+	if names := c.s.names(); !reflect.DeepEqual(names, []string{"fee", "user@example"}) {
+		t.Errorf("names() = %v, want [fee user@example]", names)
+	}
+	tests := []struct {
+		advance time.Duration
+		want    string
+		events  int
+	}{
+		{want: "0.90"},
+		{advance: 59 * time.Minute, want: "0.90"},
+		{advance: time.Minute, want: "1.10", events: 1},
+		{advance: 24 * time.Hour, want: "1.10", events: 1},
+		{advance: 60 * 24 * time.Hour, want: "1.20", events: 2},
+	}
+	for _, tt := range tests {
+		advance(tt.advance)
+		got, err := c.String("fee")
+		if err != nil || got != tt.want {
+			t.Errorf("String() at %v got = %q, %v, want %q", timeNow(), got, err, tt.want)
+		}
+		if len(events) != tt.events {
+			t.Errorf("events at %v = %v, want %d", timeNow(), events, tt.events)
+		}
+	}
+	if events[0].Trigger != "Schedule" || !reflect.DeepEqual(events[0].Changed, []string{"fee"}) {
+		t.Errorf("events[0] = %+v, want a Schedule change of fee", events[0])
+	}
+	if got, err := c.Schedule(); err != nil || len(got) != 0 {
+		t.Errorf("Schedule() after switching got = %v, %v, want none", got, err)
+	}
+}
+
+func TestConfig_Schedule_validator(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"fee": "1.00", "fee@2999-01-01": "free"})
+	c := New(WithPath(dir), WithValidator("fee", func(n string, b []byte) error {
+		if string(b) == "free" {
+			return errors.New("fee must be a number")
+		}
+		return nil
+	}))
+	var ve *ValidationError
+	if err := c.Load(); !errors.As(err, &ve) || ve.Name != "fee" {
+		t.Errorf("Load() error = %v, want a *ValidationError for fee", err)
+	}
+}
+
+func TestConfig_Watch_schedule(t *testing.T) {
+	at := time.Now().Add(50 * time.Millisecond).UTC().Format(time.RFC3339Nano)
+	c := New(WithPath(writeFiles(t, map[string]string{"mode": "old", "mode@" + at: "new"})))
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	changed := make(chan ChangeEvent, 1)
+	c.OnChange(func(ev ChangeEvent) { changed <- ev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Watch(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	select {
+	case ev := <-changed:
+		if ev.Trigger != "Schedule" {
+			t.Errorf("Trigger = %q, want Schedule", ev.Trigger)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not switch to the scheduled value")
+	}
+	if got := c.s.val["mode"]; got.text() != "new" {
+		t.Errorf("mode = %q, want new", got.text())
+	}
+}
@@ -0,0 +1,519 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema checks the content of a configuration value. See RegisterSchema. JSONSchema and
+// StructSchema provide the common kinds; other schema languages, such as CUE, can be
+// supported by implementing Schema.
+type Schema interface {
+	// Check checks data, the content of configuration value n, and returns a SchemaProblem
+	// for each way in which it does not conform. Values whose names end in ".json" are JSON,
+	// and others are YAML.
+	Check(n string, data []byte) []SchemaProblem
+}
+
+// SchemaProblem is a way in which a configuration value does not conform to its Schema.
+type SchemaProblem struct {
+	Field   string // Field is the dotted path of the offending field (e.g. "servers.0.port"), or "" for the whole value.
+	Message string // Message describes the problem.
+}
+
+func (p SchemaProblem) String() string {
+	if p.Field == "" {
+		return p.Message
+	}
+	return p.Field + ": " + p.Message
+}
+
+// RegisterSchema registers schema as the Schema of configuration value name. Every Config
+// checks the values that have a registered Schema whenever a new set of values is loaded
+// or staged, and fails with a *SchemaError listing every problem found if any of them do
+// not conform, keeping the previously loaded values. Values that do not exist are not
+// checked; see WithRequired. This lets each package declare the shape of the values it
+// reads next to the code that reads them, rather than validating them in service init
+// code:
+//
+//		func init() {
+//			config.RegisterSchema("db.yaml", config.StructSchema(DBConfig{}))
+//		}
+//
+// RegisterSchema panics if schema is nil or name already has a Schema. It is meant to be
+// called from init functions.
+func RegisterSchema(name string, schema Schema) {
+	if schema == nil {
+		panic("config: RegisterSchema: schema is nil for " + name)
+	}
+
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	if _, ok := schemas[name]; ok {
+		panic("config: RegisterSchema called twice for " + name)
+	}
+	schemas[name] = schema
+}
+
+var (
+	schemasMu sync.RWMutex
+	schemas   = map[string]Schema{}
+)
+
+// SchemaError is returned by Load when one or more configuration values do not conform to
+// their registered Schema. Its message is a report of every problem found, one per line.
+type SchemaError struct {
+	Entries []SchemaEntry // Entries are the values that do not conform, in sorted order.
+}
+
+// SchemaEntry lists the problems of a configuration value that does not conform to its
+// Schema.
+type SchemaEntry struct {
+	Name     string          // Name is the name of the configuration value.
+	Path     string          // Path is the file the configuration value was read from.
+	Problems []SchemaProblem // Problems are the ways in which the value does not conform.
+}
+
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "config: %d config entries do not conform to their schema:", len(e.Entries))
+	for _, se := range e.Entries {
+		for _, p := range se.Problems {
+			fmt.Fprintf(&b, "\n\t%s (%s): %s", se.Name, se.Path, p)
+		}
+	}
+	return b.String()
+}
+
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// checkSchemas checks the values in result that have a registered Schema.
+func checkSchemas(result map[string]entry) error {
+	schemasMu.RLock()
+	names := make([]string, 0, len(schemas))
+	for n := range schemas {
+		if _, ok := result[n]; ok {
+			names = append(names, n)
+		}
+	}
+	schemasMu.RUnlock()
+	sort.Strings(names)
+
+	var se SchemaError
+	for _, n := range names {
+		schemasMu.RLock()
+		schema := schemas[n]
+		schemasMu.RUnlock()
+
+		e := result[n].unpacked()
+		problems := schema.Check(n, e.data)
+		if len(problems) > 0 {
+			se.Entries = append(se.Entries, SchemaEntry{Name: n, Path: e.path, Problems: problems})
+		}
+	}
+	if len(se.Entries) > 0 {
+		return &se
+	}
+	return nil
+}
+
+// decodeValue decodes data, the content of configuration value n, into v as JSON if n ends
+// in ".json" and as YAML otherwise. Fields in data that match no field of the structs in
+// v are an error.
+func decodeValue(n string, data []byte, v interface{}) error {
+	if path.Ext(n) == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(v)
+	if err != nil && len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return err
+}
+
+// StructSchema returns a Schema that checks that values decode into a new value of the type
+// of exemplar, which is usually a struct, with no unknown fields. If the decoded value has
+// a Validate() error method, as with pointer receivers, it must also return nil.
+func StructSchema(exemplar interface{}) Schema {
+	return structSchema{t: reflect.TypeOf(exemplar)}
+}
+
+type structSchema struct {
+	t reflect.Type
+}
+
+func (s structSchema) Check(n string, data []byte) []SchemaProblem {
+	v := reflect.New(s.t)
+	err := decodeValue(n, data, v.Interface())
+	if err != nil {
+		return []SchemaProblem{{Message: err.Error()}}
+	}
+	if val, ok := v.Interface().(interface{ Validate() error }); ok {
+		err := val.Validate()
+		if err != nil {
+			return []SchemaProblem{{Message: err.Error()}}
+		}
+	}
+	return nil
+}
+
+// JSONSchema parses b as a JSON Schema and returns it as a Schema. It may be written in
+// JSON or YAML. The validation keywords of JSON Schema that are supported are:
+//
+//		type, enum, const,
+//		properties, required, additionalProperties,
+//		items, minItems, maxItems,
+//		minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+//		minLength, maxLength, pattern
+//
+// along with the annotations $schema, $id, $comment, title, description, default and
+// examples. Other keywords, such as $ref, are an error, so that a schema is never
+// partially applied.
+func JSONSchema(b []byte) (Schema, error) {
+	var raw interface{}
+	err := yaml.Unmarshal(b, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to parse JSON Schema: %w", err)
+	}
+	s, err := parseJSONSchema("", raw)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid JSON Schema: %w", err)
+	}
+	return s, nil
+}
+
+// jsonSchema is a parsed JSON Schema.
+type jsonSchema struct {
+	types      []string
+	enum       []interface{}
+	properties map[string]*jsonSchema
+	required   []string
+	additional *jsonSchema // additional is the schema of additional properties, or nil if any are allowed.
+	closed     bool        // closed is true if additional properties are not allowed.
+	items      *jsonSchema
+
+	minItems, maxItems   *int
+	minLength, maxLength *int
+	minimum, maximum     *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	pattern              *regexp.Regexp
+}
+
+// parseJSONSchema parses raw, the schema at keyword path at, as a JSON Schema.
+func parseJSONSchema(at string, raw interface{}) (*jsonSchema, error) {
+	if b, ok := raw.(bool); ok {
+		if b {
+			return &jsonSchema{}, nil
+		}
+		return &jsonSchema{enum: []interface{}{}}, nil
+	}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean", jsonSchemaAt(at))
+	}
+
+	s := &jsonSchema{}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := m[k]
+		var err error
+		switch k {
+		case "$schema", "$id", "$comment", "title", "description", "default", "examples":
+		case "type":
+			switch t := v.(type) {
+			case string:
+				s.types = []string{t}
+			case []interface{}:
+				for _, t := range t {
+					s.types = append(s.types, fmt.Sprint(t))
+				}
+			default:
+				err = fmt.Errorf("type must be a string or an array")
+			}
+		case "enum":
+			var ok bool
+			s.enum, ok = v.([]interface{})
+			if !ok {
+				err = fmt.Errorf("enum must be an array")
+			}
+		case "const":
+			s.enum = []interface{}{v}
+		case "properties":
+			props, ok := v.(map[string]interface{})
+			if !ok {
+				err = fmt.Errorf("properties must be an object")
+				break
+			}
+			s.properties = map[string]*jsonSchema{}
+			for name, p := range props {
+				s.properties[name], err = parseJSONSchema(at+"/properties/"+name, p)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			req, ok := v.([]interface{})
+			if !ok {
+				err = fmt.Errorf("required must be an array")
+			}
+			for _, r := range req {
+				s.required = append(s.required, fmt.Sprint(r))
+			}
+		case "additionalProperties":
+			if b, ok := v.(bool); ok {
+				s.closed = !b
+				break
+			}
+			s.additional, err = parseJSONSchema(at+"/additionalProperties", v)
+			if err != nil {
+				return nil, err
+			}
+		case "items":
+			s.items, err = parseJSONSchema(at+"/items", v)
+			if err != nil {
+				return nil, err
+			}
+		case "minItems":
+			s.minItems, err = jsonSchemaInt(v)
+		case "maxItems":
+			s.maxItems, err = jsonSchemaInt(v)
+		case "minLength":
+			s.minLength, err = jsonSchemaInt(v)
+		case "maxLength":
+			s.maxLength, err = jsonSchemaInt(v)
+		case "minimum":
+			s.minimum, err = jsonSchemaNumber(v)
+		case "maximum":
+			s.maximum, err = jsonSchemaNumber(v)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = jsonSchemaNumber(v)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = jsonSchemaNumber(v)
+		case "pattern":
+			s.pattern, err = regexp.Compile(fmt.Sprint(v))
+		default:
+			err = fmt.Errorf("unsupported keyword")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", jsonSchemaAt(at), k, err)
+		}
+	}
+	return s, nil
+}
+
+// jsonSchemaAt returns keyword path at for use in errors.
+func jsonSchemaAt(at string) string {
+	return "#" + at
+}
+
+func jsonSchemaInt(v interface{}) (*int, error) {
+	f, ok := jsonNumber(v)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+	i := int(f)
+	return &i, nil
+}
+
+func jsonSchemaNumber(v interface{}) (*float64, error) {
+	f, ok := jsonNumber(v)
+	if !ok {
+		return nil, fmt.Errorf("must be a number")
+	}
+	return &f, nil
+}
+
+// jsonNumber returns v as a float64 if it is a number decoded from JSON or YAML.
+func jsonNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func (s *jsonSchema) Check(n string, data []byte) []SchemaProblem {
+	var v interface{}
+	var err error
+	if path.Ext(n) == ".json" {
+		err = json.Unmarshal(data, &v)
+	} else {
+		err = yaml.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return []SchemaProblem{{Message: err.Error()}}
+	}
+
+	var problems []SchemaProblem
+	s.check("", v, &problems)
+	return problems
+}
+
+// check appends the problems of v, the value of field, to problems.
+func (s *jsonSchema) check(field string, v interface{}, problems *[]SchemaProblem) {
+	problem := func(format string, a ...interface{}) {
+		*problems = append(*problems, SchemaProblem{Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if len(s.types) > 0 && !s.hasType(v) {
+		problem("must be of type %s, not %s", strings.Join(s.types, " or "), jsonType(v))
+		return
+	}
+	if s.enum != nil && !jsonEnumContains(s.enum, v) {
+		if len(s.enum) == 0 {
+			problem("is not allowed")
+		} else {
+			problem("must be one of %s", jsonEnumString(s.enum))
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, r := range s.required {
+			if _, ok := v[r]; !ok {
+				*problems = append(*problems, SchemaProblem{Field: joinField(field, r), Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, ok := s.properties[k]; ok {
+				p.check(joinField(field, k), v[k], problems)
+			} else if s.additional != nil {
+				s.additional.check(joinField(field, k), v[k], problems)
+			} else if s.closed {
+				*problems = append(*problems, SchemaProblem{Field: joinField(field, k), Message: "is not an allowed field"})
+			}
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			problem("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			problem("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.check(joinField(field, fmt.Sprint(i)), item, problems)
+			}
+		}
+	case string:
+		l := len([]rune(v))
+		if s.minLength != nil && l < *s.minLength {
+			problem("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && l > *s.maxLength {
+			problem("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			problem("must match %q", s.pattern)
+		}
+	default:
+		f, ok := jsonNumber(v)
+		if !ok {
+			break
+		}
+		if s.minimum != nil && f < *s.minimum {
+			problem("must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && f > *s.maximum {
+			problem("must be at most %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+			problem("must be greater than %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+			problem("must be less than %v", *s.exclusiveMaximum)
+		}
+	}
+}
+
+// hasType reports whether v is of one of the types of s.
+func (s *jsonSchema) hasType(v interface{}) bool {
+	t := jsonType(v)
+	for _, want := range s.types {
+		if want == t || want == "number" && t == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of v.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if f, ok := jsonNumber(v); ok {
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// jsonEnumContains reports whether enum contains v.
+func jsonEnumContains(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		ef, eok := jsonNumber(e)
+		vf, vok := jsonNumber(v)
+		if eok && vok && ef == vf || reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEnumString(enum []interface{}) string {
+	b, _ := json.Marshal(enum)
+	return string(b)
+}
+
+// joinField returns the path of field k of field.
+func joinField(field, k string) string {
+	if field == "" {
+		return k
+	}
+	return field + "." + k
+}
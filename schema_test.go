@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaTestServer struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+}
+
+func (s *schemaTestServer) Validate() error {
+	if s.Port == 0 {
+		return fmt.Errorf("port is required")
+	}
+	return nil
+}
+
+const schemaTestLimits = `
+type: object
+required: [rate]
+additionalProperties: false
+properties:
+  rate: {type: integer, minimum: 1, maximum: 1000}
+  mode: {enum: [strict, lenient]}
+  tags:
+    type: array
+    maxItems: 2
+    items: {type: string, pattern: "^[a-z]+$"}
+`
+
+func init() {
+	RegisterSchema("schematest-server.yaml", StructSchema(schemaTestServer{}))
+
+	limits, err := JSONSchema([]byte(schemaTestLimits))
+	if err != nil {
+		panic(err)
+	}
+	RegisterSchema("schematest-limits.json", limits)
+}
+
+func TestRegisterSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []SchemaEntry // want are the entries of the *SchemaError, without paths.
+	}{
+		{name: "no values", files: map[string]string{"other": "x"}},
+		{
+			name: "valid",
+			files: map[string]string{
+				"schematest-server.yaml": "host: db\nport: 5432\n",
+				"schematest-limits.json": `{"rate": 10, "mode": "strict", "tags": ["a"]}`,
+			},
+		},
+		{
+			name: "invalid",
+			files: map[string]string{
+				"schematest-server.yaml": "host: db\nport: 5432\nprot: 1\n",
+				"schematest-limits.json": `{"rate": 1.5, "mode": "loose", "tags": ["a", "B", "c"], "extra": true}`,
+			},
+			want: []SchemaEntry{
+				{Name: "schematest-limits.json", Problems: []SchemaProblem{
+					{Field: "extra", Message: "is not an allowed field"},
+					{Field: "mode", Message: `must be one of ["strict","lenient"]`},
+					{Field: "rate", Message: "must be of type integer, not number"},
+					{Field: "tags", Message: "must have at most 2 items"},
+					{Field: "tags.1", Message: `must match "^[a-z]+$"`},
+				}},
+				{Name: "schematest-server.yaml", Problems: []SchemaProblem{
+					{Message: "yaml: unmarshal errors:\n  line 3: field prot not found in type config.schemaTestServer"},
+				}},
+			},
+		},
+		{
+			name:  "validate method",
+			files: map[string]string{"schematest-server.yaml": "host: db\n"},
+			want: []SchemaEntry{
+				{Name: "schematest-server.yaml", Problems: []SchemaProblem{{Message: "port is required"}}},
+			},
+		},
+		{
+			name:  "required",
+			files: map[string]string{"schematest-limits.json": `{}`},
+			want: []SchemaEntry{
+				{Name: "schematest-limits.json", Problems: []SchemaProblem{{Field: "rate", Message: "is required"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithPath(writeFiles(t, tt.files)))
+			err := c.Load()
+
+			var se *SchemaError
+			if (tt.want != nil) != errors.As(err, &se) {
+				t.Fatalf("Load() error = %v, want schema error %v", err, tt.want != nil)
+			}
+			if se == nil {
+				return
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("Load() error does not match ErrValidation")
+			}
+			for i := range se.Entries {
+				se.Entries[i].Path = ""
+			}
+			if !reflect.DeepEqual(se.Entries, tt.want) {
+				t.Errorf("Load() error entries = %#v, want %#v", se.Entries, tt.want)
+			}
+			problems := 0
+			for _, e := range tt.want {
+				problems += len(e.Problems)
+			}
+			if got := strings.Count(err.Error(), "\n\t"); got != problems {
+				t.Errorf("Load() error = %q, want %d problems, one per line", err, problems)
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{name: "boolean", schema: "true"},
+		{name: "annotations", schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "x", "type": ["string", "null"]}`},
+		{name: "unsupported keyword", schema: `{"$ref": "#/definitions/x"}`, wantErr: true},
+		{name: "nested unsupported keyword", schema: `{"properties": {"a": {"oneOf": []}}}`, wantErr: true},
+		{name: "invalid pattern", schema: `{"pattern": "("}`, wantErr: true},
+		{name: "invalid minItems", schema: `{"minItems": -1}`, wantErr: true},
+		{name: "not an object", schema: `[]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := JSONSchema([]byte(tt.schema))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("JSONSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
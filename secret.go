@@ -0,0 +1,31 @@
+package config
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultSecrets are the name patterns of the configuration values treated as secrets by
+// default. See WithSecrets.
+var DefaultSecrets = []string{"*password*", "*secret*", "*token*", "*.key", "*.pem"}
+
+// WithSecrets sets the patterns, as accepted by path.Match, of the names of configuration
+// values that hold secrets. Secrets are redacted by tools that print configuration values.
+// It defaults to DefaultSecrets; call it with no patterns to treat no values as secrets.
+func WithSecrets(patterns ...string) Option {
+	return func(o *options) {
+		o.secrets = append([]string{}, patterns...)
+	}
+}
+
+// IsSecret reports whether configuration value n holds a secret, according to the
+// patterns set with WithSecrets. Names are matched case-insensitively.
+func (c *Config) IsSecret(n string) bool {
+	n = strings.ToLower(c.prefix + n)
+	for _, p := range c.s.secrets {
+		if ok, _ := path.Match(strings.ToLower(p), n); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestConfig_IsSecret(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *Config
+		n    string
+		want bool
+	}{
+		{"default password", New(), "DB_PASSWORD", true},
+		{"default key", New(), "tls.key", true},
+		{"default plain", New(), "db_url", false},
+		{"scoped", New().Scoped("api_token/"), "value", false},
+		{"scoped prefix", New().Scoped("api_"), "token", true},
+		{"custom", New(WithSecrets("*_creds")), "aws_creds", true},
+		{"custom replaces defaults", New(WithSecrets("*_creds")), "db_password", false},
+		{"none", New(WithSecrets()), "db_password", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.IsSecret(tt.n); got != tt.want {
+				t.Errorf("IsSecret() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
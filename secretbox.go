@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// SecretBoxHeader starts every configuration value encrypted with SealSecretBox. It is
+// followed by the base64 encoding of a random XChaCha20-Poly1305 nonce and the ciphertext.
+const SecretBoxHeader = "config:secretbox:v1:"
+
+// WithSecretBox enables decryption of values encrypted with SealSecretBox. Every
+// configuration value that starts with SecretBoxHeader is decrypted in place after loading,
+// using the base64-encoded 32-byte key in the environment variable env. The variable is
+// read every time the Config is loaded. Decryption is added as a Transformer, so it runs
+// in order with those added by WithTransformer, and before template rendering.
+func WithSecretBox(env string) Option {
+	return WithTransformer(secretBoxTransformer(env))
+}
+
+// SealSecretBox encrypts plaintext with key, a 32-byte XChaCha20-Poly1305 key, for use with
+// WithSecretBox. The result is printable and can be stored anywhere a configuration value
+// can.
+func SealSecretBox(key, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid secretbox key: %w", err)
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return []byte(SecretBoxHeader + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// openSecretBox decrypts b, which was encrypted by SealSecretBox.
+func openSecretBox(key, b []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secretbox key: %w", err)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b[len(SecretBoxHeader):])))
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+}
+
+// secretBoxTransformer returns a Transformer that decrypts values starting with
+// SecretBoxHeader using the key in environment variable env.
+func secretBoxTransformer(env string) Transformer {
+	return func(_ string, b []byte) ([]byte, error) {
+		if !bytes.HasPrefix(b, []byte(SecretBoxHeader)) {
+			return b, nil
+		}
+
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			return nil, fmt.Errorf("secretbox key environment variable %s is not set", env)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secretbox key from %s: %w", env, err)
+		}
+
+		d, err := openSecretBox(key, b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+		return d, nil
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"testing"
+)
+
+func TestWithSecretBox(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	sealed, err := SealSecretBox(key, []byte("postgres://user:pass@db/app"))
+	if err != nil {
+		t.Fatalf("SealSecretBox() error = %v", err)
+	}
+	if bytes.Contains(sealed, []byte("pass")) {
+		t.Fatalf("SealSecretBox() = %q contains the plaintext", sealed)
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"db_url":  string(sealed) + "\n",
+		"timeout": "1s",
+	})
+
+	if err := os.Setenv("CONFIG_TEST_SECRETBOX_KEY", base64.StdEncoding.EncodeToString(key)); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("CONFIG_TEST_SECRETBOX_KEY")
+
+	c := New(WithPath(dir), WithSecretBox("CONFIG_TEST_SECRETBOX_KEY"))
+	if got, err := c.Url("db_url"); err != nil || got.String() != "postgres://user:pass@db/app" {
+		t.Errorf("Url() = %v, %v, want %v", got, err, "postgres://user:pass@db/app")
+	}
+	if got, err := c.String("timeout"); err != nil || got != "1s" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "1s")
+	}
+
+	if err := os.Setenv("CONFIG_TEST_SECRETBOX_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{8}, 32))); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() with wrong key error = %v, wantErr %v", err, true)
+	}
+
+	if err := New(WithPath(dir), WithSecretBox("CONFIG_TEST_SECRETBOX_MISSING")).Load(); err == nil {
+		t.Errorf("Load() without key error = %v, wantErr %v", err, true)
+	}
+
+	if _, err := SealSecretBox([]byte("short"), nil); err == nil {
+		t.Errorf("SealSecretBox() with short key error = %v, wantErr %v", err, true)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WithSnapshot persists the loaded values of the Config to the file at path, so the next
+// process to start can boot from them without waiting for its sources. This cuts the
+// cold-start latency of processes with many remote sources, such as serverless functions
+// that scale to zero.
+//
+// After every successful load, reload or commit, the values are written to path, in the
+// format of Export, replacing the file atomically. When the Config is first loaded and
+// path holds a snapshot, its values are imported instead, as Import does, and the sources
+// are then loaded in the background; their values replace those of the snapshot, and
+// subscribers are told about any differences with the trigger "Load". If the background
+// load fails, the snapshot values are kept and a WarnSnapshot Warning is produced. A
+// missing snapshot is ignored, and one that cannot be read or fails validation produces a
+// Warning and is loaded normally.
+//
+// The snapshot holds values after decryption and template rendering, so it is written
+// with permissions 0600 and must be protected like the secrets it contains. Failures to
+// write it produce a WarnSnapshot Warning, and do not fail the load.
+func WithSnapshot(path string) Option {
+	return func(o *options) {
+		o.snapshot = path
+	}
+}
+
+// warmStart commits the values of the snapshot of s, if it has one, and starts loading
+// its sources in the background. It reports whether the snapshot was committed.
+func (s *store) warmStart() bool {
+	if s.snapshot == "" || s.parent != nil {
+		return false
+	}
+
+	f, err := os.Open(s.snapshot)
+	if os.IsNotExist(err) {
+		return false
+	}
+	if err != nil {
+		s.warn(Warning{Kind: WarnSnapshot, Path: s.snapshot, Message: fmt.Sprintf("failed to read snapshot: %v", err)}, true)
+		return false
+	}
+	defer f.Close()
+
+	st, err := s.readArchive(f)
+	if err == nil {
+		err = st.commit("Snapshot")
+	}
+	if err != nil {
+		s.warn(Warning{Kind: WarnSnapshot, Path: s.snapshot, Message: fmt.Sprintf("ignored snapshot %s: %v", s.snapshot, err)}, true)
+		return false
+	}
+
+	go func() {
+		s.reloading()
+		err := s.reload(context.Background(), "Load")
+		s.reloaded(err)
+		if err != nil {
+			s.warn(Warning{Kind: WarnSnapshot, Path: s.snapshot, Message: fmt.Sprintf("serving values from snapshot %s because loading failed: %v", s.snapshot, err)}, true)
+		}
+	}()
+	return true
+}
+
+// saveSnapshot writes the loaded values of s to its snapshot file.
+func (s *store) saveSnapshot() {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+
+	s.mu.RLock()
+	val := s.val
+	s.mu.RUnlock()
+
+	err := writeSnapshot(s.snapshot, val)
+	if err != nil {
+		s.warn(Warning{Kind: WarnSnapshot, Path: s.snapshot, Message: fmt.Sprintf("failed to write snapshot: %v", err)}, true)
+	}
+}
+
+// writeSnapshot writes val to the file at path, replacing it atomically.
+func writeSnapshot(path string, val map[string]entry) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = writeArchive(f, val)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// gateSource is a Source that provides the value "remote" once gate is closed, or fails
+// with err if it is set.
+type gateSource struct {
+	gate chan struct{}
+	data string
+	err  error
+}
+
+func (s *gateSource) Files(context.Context) ([]File, error) {
+	<-s.gate
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []File{{Name: "remote", Path: "gate:remote", Data: []byte(s.data)}}, nil
+}
+
+// waitGeneration waits for c to reach generation g.
+func waitGeneration(t *testing.T, c *Config, g uint64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Generation() < g {
+		if time.Now().After(deadline) {
+			t.Fatalf("Generation() = %d, want %d", c.Generation(), g)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithSnapshot(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "cold"})
+	snapshot := filepath.Join(t.TempDir(), "config.snapshot")
+	open := make(chan struct{})
+	close(open)
+
+	cold := New(WithPath(dir), WithSource(&gateSource{gate: open, data: "v1"}), WithSnapshot(snapshot))
+	if err := cold.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	fi, err := os.Stat(snapshot)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("snapshot mode = %v, want 0600", fi.Mode().Perm())
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "name"), []byte("warm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gate := make(chan struct{})
+	warm := New(WithPath(dir), WithSource(&gateSource{gate: gate, data: "v2"}), WithSnapshot(snapshot))
+	events := make(chan ChangeEvent, 1)
+	warm.OnChange(func(e ChangeEvent) { events <- e })
+
+	// The source blocks, so the values can only come from the snapshot.
+	if got, err := warm.String("remote"); err != nil || got != "v1" {
+		t.Fatalf("String() before the sources load = %q, %v, want v1", got, err)
+	}
+	if o, err := warm.Origin("name"); err != nil || o.Path != filepath.Join(dir, "name") {
+		t.Errorf("Origin() = %+v, %v, want the snapshotted path", o, err)
+	}
+
+	close(gate)
+	waitGeneration(t, warm, 2)
+	for n, want := range map[string]string{"remote": "v2", "name": "warm"} {
+		if got, err := warm.String(n); err != nil || got != want {
+			t.Errorf("String(%q) after the sources load = %q, %v, want %q", n, got, err, want)
+		}
+	}
+	if e := <-events; e.Trigger != "Load" || len(e.Changed) != 2 {
+		t.Errorf("ChangeEvent = %+v, want both values changed by Load", e)
+	}
+
+	restarted := New(WithPath(t.Name()), WithSource(&gateSource{gate: make(chan struct{})}), WithSnapshot(snapshot))
+	if got, err := restarted.String("remote"); err != nil || got != "v2" {
+		t.Errorf("String() from the updated snapshot = %q, %v, want v2", got, err)
+	}
+}
+
+func TestWithSnapshot_failures(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "value"})
+	snapshot := filepath.Join(t.TempDir(), "config.snapshot")
+	if err := ioutil.WriteFile(snapshot, []byte("not an archive"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings []Warning
+	c := New(WithPath(dir), WithSnapshot(snapshot), WithWarnings(func(w Warning) { warnings = append(warnings, w) }))
+	if got, err := c.String("name"); err != nil || got != "value" {
+		t.Fatalf("String() with a corrupt snapshot = %q, %v, want value", got, err)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarnSnapshot {
+		t.Errorf("warnings = %v, want one WarnSnapshot", warnings)
+	}
+
+	failed := make(chan Warning, 1)
+	open := make(chan struct{})
+	close(open)
+	c = New(WithPath(dir), WithSnapshot(snapshot), WithSource(&gateSource{gate: open, err: errors.New("down")}), WithWarnings(func(w Warning) { failed <- w }))
+	if got, err := c.String("name"); err != nil || got != "value" {
+		t.Fatalf("String() from the snapshot = %q, %v, want value", got, err)
+	}
+	if w := <-failed; w.Kind != WarnSnapshot {
+		t.Errorf("Warning = %+v, want WarnSnapshot", w)
+	}
+	if got, err := c.String("name"); err != nil || got != "value" || c.Generation() != 1 {
+		t.Errorf("String() after the sources failed = %q, %v, generation %d, want the snapshot values", got, err, c.Generation())
+	}
+}
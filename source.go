@@ -0,0 +1,307 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// File is a configuration value provided by a Source.
+type File struct {
+	Name string // Name is the name the value is accessible by.
+	Path string // Path describes where the value was read from. It is used in errors and logs.
+	Data []byte // Data is the content of the value.
+}
+
+// Source provides configuration values to a Config. Each directory on the search path is a
+// Source, and additional ones can be added with WithSource.
+type Source interface {
+	// Files returns the configuration values provided by the source.
+	Files(ctx context.Context) ([]File, error)
+}
+
+// WithSource adds src to the sources of the Config with priority 0. Sources are read after
+// the search path directories, in the order they were added. Names provided by more than
+// one source are handled like files with the same name in different search path
+// directories; see Shadow.
+func WithSource(src Source) Option {
+	return WithSourcePriority(src, 0)
+}
+
+// WithSourcePriority adds src to the sources of the Config with the given priority. When a
+// name is provided by more than one directory or source, it is resolved as follows:
+//
+//		1. The value from the directory or source with the highest priority is used.
+//		2. Among those with equal priority, the first one read is used if shadowing is
+//		   enabled (see Shadow), and loading fails with a *DuplicateError otherwise.
+//
+// Search path directories have priority 0 unless changed with WithPathPriority, and are
+// read before sources. Use Origin to find where a value was resolved from.
+func WithSourcePriority(src Source, priority int) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, weightedSource{Source: src, priority: priority})
+	}
+}
+
+// WithPathPriority sets the priority of the search path entries of the Config. It
+// defaults to 0. See WithSourcePriority.
+func WithPathPriority(priority int) Option {
+	return func(o *options) {
+		o.pathPriority = priority
+	}
+}
+
+// ValueOrigin describes where a configuration value was resolved from.
+type ValueOrigin struct {
+	Path     string // Path is the file the value was read from.
+	Source   string // Source is the search path entry, or a description of the Source, containing Path.
+	Priority int    // Priority is the priority of Source; see WithSourcePriority.
+	Tenant   string // Tenant is the tenant whose directory provided the value, or "" for shared values.
+}
+
+// Origin calls c.Load() then returns where configuration value n was resolved from.
+func (c *Config) Origin(n string) (ValueOrigin, error) {
+	err := c.Load()
+	if err != nil {
+		return ValueOrigin{}, fmt.Errorf("config: failed to get value %q because there was a load error: %w", n, err)
+	}
+
+	full := c.fullName(n)
+	if v, ok := c.values[full]; ok {
+		return ValueOrigin{Source: v.e.source}, nil
+	}
+	for s := c.s; s != nil; s = s.parent {
+		s.mu.RLock()
+		e, ok := s.val[full]
+		s.mu.RUnlock()
+		if ok {
+			return ValueOrigin{Path: e.path, Source: e.source, Priority: e.priority, Tenant: s.tenant}, nil
+		}
+	}
+	return ValueOrigin{}, c.notFound(n)
+}
+
+// weightedSource is a Source along with its priority.
+type weightedSource struct {
+	Source
+	priority int
+}
+
+// dirSource is a Source that provides the files in a directory. Subdirectories and files
+// that cannot be read are ignored. If the path is a file rather than a directory, the
+// Source provides that file alone, named after its base name.
+type dirSource string
+
+func (d dirSource) Files(context.Context) ([]File, error) {
+	fs, _, err := readDir(string(d), 0, nil)
+	return fs, err
+}
+
+// readDir returns the files in directory p and in its subdirectories up to depth levels
+// deep (see walkDir), along with reports of the entries that were skipped. If p is a file,
+// it returns p alone.
+func readDir(p string, depth int, prune []string) ([]File, []FileReport, error) {
+	if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+		d, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("config: error reading file %q: %w", p, err)
+		}
+		return []File{{Name: filepath.Base(p), Path: p, Data: d}}, nil, nil
+	}
+
+	var result []File
+	var skipped []FileReport
+	err := walkDir(p, depth, prune, func(n, f string, fi os.FileInfo, skip string) {
+		if skip != "" {
+			skipped = append(skipped, FileReport{Name: n, Path: f, Skipped: skip})
+			return
+		}
+
+		d, err := ioutil.ReadFile(f)
+		if err != nil {
+			skipped = append(skipped, FileReport{Name: n, Path: f, Skipped: err.Error(), err: err})
+			return
+		}
+
+		result = append(result, File{Name: n, Path: f, Data: d})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, skipped, nil
+}
+
+// mapSource is a Source that provides a fixed set of values. Values are named by the keys
+// of vals, and their paths are the name of the source followed by a colon and their name.
+type mapSource struct {
+	name string
+	vals map[string][]byte
+}
+
+// newMapSource returns a mapSource named name providing a copy of vals.
+func newMapSource(name string, vals map[string][]byte) *mapSource {
+	result := &mapSource{name: name, vals: make(map[string][]byte, len(vals))}
+	for n, b := range vals {
+		result.vals[n] = append([]byte(nil), b...)
+	}
+	return result
+}
+
+func (s *mapSource) Files(context.Context) ([]File, error) {
+	result := make([]File, 0, len(s.vals))
+	for n, b := range s.vals {
+		result = append(result, File{Name: n, Path: s.name + ":" + n, Data: b})
+	}
+	return result, nil
+}
+
+func (s *mapSource) String() string {
+	return s.name
+}
+
+// describeSource returns a description of src for reports.
+func describeSource(src Source) string {
+	if s, ok := src.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", src)
+}
+
+func init() {
+	pathSources["file"] = newFileSource
+}
+
+// pathSources maps the URL schemes that can be used in search path entries to the
+// constructors of their sources. Entries without a registered scheme are directories (or
+// files), so existing search paths keep working as more schemes are added. Other packages
+// add schemes with RegisterSource; it is guarded by pathSourcesMu.
+var pathSources = map[string]SourceFactory{}
+
+// opaquePathSources holds the schemes in pathSources whose entries are not followed by
+// "//", such as "exec:./fetch-config.sh".
+var opaquePathSources = map[string]bool{}
+
+// pathSource returns the Source for search path entry p.
+func pathSource(p string) (Source, error) {
+	if p == StdinPath {
+		return stdin, nil
+	}
+
+	scheme, ok := pathScheme(p)
+	if !ok {
+		return dirSource(p), nil
+	}
+
+	u, err := url.Parse(p)
+	if err != nil {
+		return nil, fmt.Errorf("config: invalid search path entry %q: %w", p, err)
+	}
+	factory, _, _ := pathSourceFactory(scheme)
+	return factory(u)
+}
+
+// entrySources holds the Sources built for search path entries, keyed by entry, so that
+// their state, such as the caches of HTTPSource and change notifications, survives
+// reloads.
+type entrySources struct {
+	mu sync.Mutex
+	m  map[string]Source
+}
+
+// get returns the Source for search path entry p, building it the first time p is seen.
+// If es is nil, a new Source is built every time.
+func (es *entrySources) get(p string) (Source, error) {
+	if es == nil {
+		return pathSource(p)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if src, ok := es.m[p]; ok {
+		return src, nil
+	}
+	src, err := pathSource(p)
+	if err != nil {
+		return nil, err
+	}
+	if es.m == nil {
+		es.m = map[string]Source{}
+	}
+	es.m[p] = src
+	return src, nil
+}
+
+// lookup returns the Source built for search path entry p, if there is one.
+func (es *entrySources) lookup(p string) (Source, bool) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	src, ok := es.m[p]
+	return src, ok
+}
+
+// newFileSource returns the Source for a search path entry of the form
+// "file:///etc/config", which is equivalent to the bare entry "/etc/config".
+func newFileSource(u *url.URL) (Source, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("config: search path entry %q must refer to the local host", u.String())
+	}
+	return dirSource(filepath.FromSlash(u.Path)), nil
+}
+
+// optionalPath reports whether search path entry p is optional, returning p without its
+// trailing "?" if it is. Only directory (and file) entries can be optional, since "?"
+// begins the query of a URL entry.
+func optionalPath(p string) (string, bool) {
+	if _, ok := pathScheme(p); ok || len(p) < 2 || !strings.HasSuffix(p, "?") {
+		return p, false
+	}
+	return strings.TrimSuffix(p, "?"), true
+}
+
+// pathScheme returns the scheme of search path entry p, if it is a URL with a scheme in
+// pathSources.
+func pathScheme(p string) (string, bool) {
+	i := strings.Index(p, ":")
+	if i <= 0 {
+		return "", false
+	}
+
+	scheme := p[:i]
+	_, opaque, ok := pathSourceFactory(scheme)
+	if !ok {
+		return "", false
+	}
+	return scheme, opaque || strings.HasPrefix(p[i:], "://")
+}
+
+// splitPath splits search path p into its entries like filepath.SplitList. Since the list
+// separator is a colon on most systems, URL entries (e.g. "azblob://container") are split
+// after their scheme, and before the port of their host, if any (e.g.
+// "http://config:8080/app.yaml"); such entries are joined back together.
+func splitPath(p string) []string {
+	ps := filepath.SplitList(p)
+	var result []string
+	for i := 0; i < len(ps); i++ {
+		if _, opaque, ok := pathSourceFactory(ps[i]); ok && i+1 < len(ps) && (opaque || strings.HasPrefix(ps[i+1], "//")) {
+			e := ps[i] + ":" + ps[i+1]
+			i++
+			if !opaque && !strings.ContainsAny(ps[i][2:], "/?#") && i+1 < len(ps) && portRegexp.MatchString(ps[i+1]) {
+				e += ":" + ps[i+1]
+				i++
+			}
+			result = append(result, e)
+			continue
+		}
+		result = append(result, ps[i])
+	}
+	return result
+}
+
+// portRegexp matches the remainder of a URL entry that starts with the port of its host.
+var portRegexp = regexp.MustCompile(`^[0-9]+(?:[/?#]|$)`)
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// staticSource is a Source that provides a fixed set of values.
+type staticSource map[string]string
+
+func (s staticSource) Files(context.Context) ([]File, error) {
+	var result []File
+	for n, d := range s {
+		result = append(result, File{Name: n, Path: "static:" + n, Data: []byte(d)})
+	}
+	return result, nil
+}
+
+func TestWithSourcePriority(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"name":  "dir",
+		"port":  "80",
+		"extra": "dir",
+	})
+	c := New(
+		WithPath(dir),
+		WithSourcePriority(staticSource{"name": "remote", "port": "8080"}, 10),
+		WithSourcePriority(staticSource{"port": "9090"}, 20),
+		WithSourcePriority(staticSource{"extra": "fallback", "only": "fallback"}, -1),
+	)
+
+	tests := []struct {
+		name         string
+		want         string
+		wantPath     string
+		wantPriority int
+	}{
+		{name: "name", want: "remote", wantPath: "static:name", wantPriority: 10},
+		{name: "port", want: "9090", wantPath: "static:port", wantPriority: 20},
+		{name: "extra", want: "dir", wantPath: filepath.Join(dir, "extra"), wantPriority: 0},
+		{name: "only", want: "fallback", wantPath: "static:only", wantPriority: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.String(tt.name)
+			if err != nil || got != tt.want {
+				t.Errorf("String() = %q, %v, want %q", got, err, tt.want)
+			}
+
+			o, err := c.Origin(tt.name)
+			if err != nil || o.Path != tt.wantPath || o.Priority != tt.wantPriority {
+				t.Errorf("Origin() = %+v, %v, want path %q priority %d", o, err, tt.wantPath, tt.wantPriority)
+			}
+		})
+	}
+
+	if _, err := c.Origin("missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Origin() error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	var de *DuplicateError
+	err := New(WithPath(dir), WithPathPriority(5), WithSourcePriority(staticSource{"name": "remote"}, 5)).Load()
+	if !errors.As(err, &de) {
+		t.Errorf("Load() error = %v, want *DuplicateError", err)
+	}
+}
+
+func TestConfig_Origin_tenant(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"name":            "shared",
+		"tenants/a/name":  "a",
+		"tenants/a/.keep": "",
+		"tenants/b/.keep": "",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		tenant     string
+		wantTenant string
+		wantPath   string
+	}{
+		{tenant: "a", wantTenant: "a", wantPath: filepath.Join(dir, "tenants", "a", "name")},
+		{tenant: "b", wantTenant: "", wantPath: filepath.Join(dir, "name")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tenant, func(t *testing.T) {
+			o, err := c.ForTenant(tt.tenant).Origin("name")
+			if err != nil || o.Tenant != tt.wantTenant || o.Path != tt.wantPath {
+				t.Errorf("Origin() = %+v, %v, want tenant %q path %q", o, err, tt.wantTenant, tt.wantPath)
+			}
+		})
+	}
+}
+
+func Test_pathSource(t *testing.T) {
+	abs, err := filepath.Abs("testdata/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		p       string
+		want    Source
+		wantErr bool
+	}{
+		{name: "bare", p: "testdata/1", want: dirSource("testdata/1")},
+		{name: "file", p: "file://" + filepath.ToSlash(abs), want: dirSource(abs)},
+		{name: "file localhost", p: "file://localhost" + filepath.ToSlash(abs), want: dirSource(abs)},
+		{name: "file remote", p: "file://example.com/etc/config", wantErr: true},
+		{name: "http", p: "http://example.com/app.yaml", want: HTTP("http://example.com/app.yaml")},
+		{name: "https", p: "https://example.com/app.yaml", want: HTTP("https://example.com/app.yaml")},
+		{name: "https no host", p: "https:///app.yaml", wantErr: true},
+		{name: "unregistered", p: "s3://bucket/prefix", want: dirSource("s3://bucket/prefix")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathSource(tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pathSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) && !tt.wantErr {
+				t.Errorf("pathSource() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_splitPath_url(t *testing.T) {
+	want := []string{"testdata/1", "https://example.com/app.yaml", "file:///etc/config", "http://config:8080/app.yaml", "http://config:8080", "8080"}
+	p := strings.Join(want, string(os.PathListSeparator))
+	if got := splitPath(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("splitPath() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SpringCloudSource is a Source that provides the properties served by a Spring Cloud
+// Config server for an application, profile and label. Each property is provided under its
+// own name (e.g. "spring.datasource.url"). When a property is defined by more than one of
+// the server's property sources, the one with the highest precedence is used.
+type SpringCloudSource struct {
+	// URI is the base URI of the config server, e.g. "http://config:8888".
+	URI string
+	// Application is the application name.
+	Application string
+	// Profile is a comma-separated list of active profiles. It defaults to "default".
+	Profile string
+	// Label is the label (e.g. git branch) to request. It defaults to the server's default.
+	Label string
+
+	// Username and Password are used for HTTP basic authentication if Username is set.
+	Username string
+	Password string
+
+	// Client is used to make requests. It defaults to http.DefaultClient.
+	Client *http.Client
+	// Auth authenticates requests, if it is not nil. See WithAuth.
+	Auth Auth
+}
+
+// SpringCloud returns a SpringCloudSource for application and profile served from uri.
+func SpringCloud(uri, application, profile string) *SpringCloudSource {
+	return &SpringCloudSource{URI: uri, Application: application, Profile: profile}
+}
+
+// springCloudEnvironment is the response body of the config server's environment endpoint.
+type springCloudEnvironment struct {
+	PropertySources []struct {
+		Name   string                     `json:"name"`
+		Source map[string]json.RawMessage `json:"source"`
+	} `json:"propertySources"`
+}
+
+func (s *SpringCloudSource) Files(ctx context.Context) ([]File, error) {
+	profile := s.Profile
+	if profile == "" {
+		profile = "default"
+	}
+
+	u := strings.TrimSuffix(s.URI, "/") + "/" + url.PathEscape(s.Application) + "/" + url.PathEscape(profile)
+	if s.Label != "" {
+		// The server expects slashes in labels to be written as "(_)".
+		u += "/" + strings.Replace(url.PathEscape(s.Label), "%2F", "(_)", -1)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if s.Username != "" {
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+
+	client, err := authClient(s.Client, s.Auth)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("config: spring cloud config request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("config: spring cloud config request for %q failed: %s", u, resp.Status)
+	}
+
+	var env springCloudEnvironment
+	err = json.NewDecoder(resp.Body).Decode(&env)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to decode spring cloud config response from %q: %w", u, err)
+	}
+
+	props := map[string]File{}
+	for _, ps := range env.PropertySources {
+		for k, v := range ps.Source {
+			if _, ok := props[k]; ok {
+				continue
+			}
+			props[k] = File{Name: k, Path: ps.Name + "#" + k, Data: springCloudValue(v)}
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for n := range props {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	result := make([]File, 0, len(names))
+	for _, n := range names {
+		result = append(result, props[n])
+	}
+	return result, nil
+}
+
+// springCloudValue returns the data of property value v. Strings are unquoted; other
+// values are kept as JSON.
+func springCloudValue(v json.RawMessage) []byte {
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return []byte(s)
+	}
+	return v
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSpringCloudSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != "/billing/prod/release(_)v2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{
+			"name": "billing",
+			"profiles": ["prod"],
+			"propertySources": [
+				{"name": "git:billing-prod.yml", "source": {"server.port": 9090, "db.url": "postgres://prod"}},
+				{"name": "git:billing.yml", "source": {"server.port": 8080, "db.pool": {"max": 10}, "feature.enabled": true}}
+			]
+		}`)
+	}))
+	defer srv.Close()
+
+	src := SpringCloud(srv.URL, "billing", "prod")
+	src.Label = "release/v2"
+	src.Username, src.Password = "user", "pass"
+	c := New(WithPath(""), WithSource(src))
+
+	for n, want := range map[string]string{
+		"server.port":     "9090",
+		"db.url":          "postgres://prod",
+		"db.pool":         `{"max": 10}`,
+		"feature.enabled": "true",
+	} {
+		if got, err := c.String(n); err != nil || got != want {
+			t.Errorf("String(%q) = %q, %v, want %q", n, got, err, want)
+		}
+	}
+
+	src.Password = "wrong"
+	if err := c.Reload(); err == nil {
+		t.Errorf("Reload() error = %v, wantErr %v", err, true)
+	}
+}
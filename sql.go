@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// DefaultSQLQuery is the query used by SQLSource if none is configured.
+const DefaultSQLQuery = "SELECT name, data FROM config"
+
+// SQLSource is a Source that provides the rows returned by a database query. The query
+// must return two columns: the name of each configuration value and its data.
+type SQLSource struct {
+	// DB is the database to query. If it is nil, a database is opened with Driver and
+	// DSN when the source is first read. The driver must be registered by the caller,
+	// typically by importing it.
+	DB     *sql.DB
+	Driver string
+	DSN    string
+
+	// Query is the query that returns the configuration values. It defaults to
+	// DefaultSQLQuery.
+	Query string
+
+	open sync.Once
+	err  error
+}
+
+// SQL returns an SQLSource that runs query against db.
+func SQL(db *sql.DB, query string) *SQLSource {
+	return &SQLSource{DB: db, Query: query}
+}
+
+func (s *SQLSource) Files(ctx context.Context) ([]File, error) {
+	s.open.Do(func() {
+		if s.DB == nil {
+			s.DB, s.err = sql.Open(s.Driver, s.DSN)
+		}
+	})
+	if s.err != nil {
+		return nil, fmt.Errorf("config: failed to open %s database: %w", s.Driver, s.err)
+	}
+
+	q := s.Query
+	if q == "" {
+		q = DefaultSQLQuery
+	}
+
+	rows, err := s.DB.QueryContext(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to query configuration values: %w", err)
+	}
+	defer rows.Close()
+
+	var result []File
+	for rows.Next() {
+		var f File
+		err := rows.Scan(&f.Name, &f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to scan configuration value: %w", err)
+		}
+		f.Path = "sql:" + f.Name
+		result = append(result, f)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to query configuration values: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+func init() {
+	sql.Register("configtest", fakeDriver{})
+}
+
+// fakeDriver is a database/sql driver whose every query returns the rows of the
+// database named by the DSN.
+type fakeDriver struct{}
+
+var fakeDatabases = map[string][][]driver.Value{
+	"config": {
+		{"db_url", []byte("postgres://sql")},
+		{"timeout", []byte("3s")},
+	},
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	rows, ok := fakeDatabases[dsn]
+	if !ok {
+		return nil, errors.New("no such database")
+	}
+	return fakeConn(rows), nil
+}
+
+type fakeConn [][]driver.Value
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt(c), nil }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeStmt [][]driver.Value
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return 0 }
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) { return &fakeRows{rows: s}, nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	i    int
+}
+
+func (r *fakeRows) Columns() []string { return []string{"name", "data"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+func TestSQLSource(t *testing.T) {
+	c := New(WithPath(""), WithSource(&SQLSource{Driver: "configtest", DSN: "config"}))
+	if got, err := c.String("db_url"); err != nil || got != "postgres://sql" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "postgres://sql")
+	}
+
+	db, err := sql.Open("configtest", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	c = New(WithPath(""), WithSource(SQL(db, "SELECT key, value FROM settings")))
+	if got, err := c.Duration("timeout"); err != nil || got.String() != "3s" {
+		t.Errorf("Duration() = %v, %v, want %v", got, err, "3s")
+	}
+
+	c = New(WithPath(""), WithSource(&SQLSource{Driver: "configtest", DSN: "missing"}))
+	if err := c.Load(); err == nil {
+		t.Errorf("Load() error = %v, wantErr %v", err, true)
+	}
+}
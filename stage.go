@@ -0,0 +1,351 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validator checks configuration value n before it is committed. See WithValidator.
+type Validator func(n string, b []byte) error
+
+// WithRequired marks the named configuration values as required. Loading or staging a
+// configuration set that lacks any of them fails with a *ValidationError, and the
+// previously loaded values are kept. Tenant views do not require the values themselves,
+// since they fall back to the shared ones.
+func WithRequired(names ...string) Option {
+	return func(o *options) {
+		o.required = append(o.required, names...)
+	}
+}
+
+// WithValidator registers v to check configuration value n whenever a new set of values
+// is loaded or staged. If n is missing, v is not called; combine it with WithRequired to
+// require n. Validators see values after decryption and template rendering.
+func WithValidator(n string, v Validator) Option {
+	return func(o *options) {
+		if o.validators == nil {
+			o.validators = map[string][]Validator{}
+		}
+		o.validators[n] = append(o.validators[n], v)
+	}
+}
+
+// ValidationError is returned when a configuration value is missing or rejected by a
+// Validator while loading or staging a configuration set.
+type ValidationError struct {
+	Name string // Name is the name of the configuration value.
+	Path string // Path is the file the configuration value was read from, if it exists.
+	Err  error  // Err is os.ErrNotExist for missing required values, otherwise the Validator's error.
+}
+
+func (e *ValidationError) Error() string {
+	if errors.Is(e.Err, os.ErrNotExist) {
+		return fmt.Sprintf("config: required config entry %q not found", e.Name)
+	}
+	return fmt.Sprintf("config: invalid config entry %q (%s): %v", e.Name, e.Path, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Staged is a configuration set that has been read, decrypted, rendered and validated but
+// not yet made visible to readers. It is created with Stage and made live with Commit.
+type Staged struct {
+	s         *store
+	path      string
+	val       map[string]entry
+	report    *LoadReport
+	schedule  []scheduledEntry
+	committed bool
+}
+
+// Stage reads the search path and prepares a new set of configuration values without
+// replacing the ones currently loaded. If reading, decrypting, rendering or validating the
+// values fails, the error is returned and readers are unaffected. Otherwise the values
+// become live when Commit is called on the result.
+//
+// This allows a service to check a configuration push before acting on it:
+//
+//		staged, err := c.Stage()
+//		if err != nil {
+//			log.Printf("rejected config: %v", err)
+//			return
+//		}
+//		err = staged.Commit()
+//
+// Load and Reload are equivalent to Stage followed immediately by Commit.
+func (c *Config) Stage() (*Staged, error) {
+	s := c.s
+	if s.parent != nil {
+		err := (&Config{s: s.parent}).Load()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	st, err := s.stage(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("config: encountered while staging config: %w", err)
+	}
+	return st, nil
+}
+
+// Names returns the sorted names of the staged configuration values.
+func (st *Staged) Names() []string {
+	result := make([]string, 0, len(st.val))
+	for n := range st.val {
+		result = append(result, n)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Commit replaces the loaded configuration values with the staged ones, discarding
+// everything cached from the previous ones, and reloads the tenant views. A Staged can
+// only be committed once.
+func (st *Staged) Commit() error {
+	s := st.s
+	s.mu.Lock()
+	if st.committed {
+		s.mu.Unlock()
+		return errors.New("config: staged config already committed")
+	}
+	st.committed = true
+	s.mu.Unlock()
+
+	// Committing counts as the initial load, if it has not happened yet, unless the
+	// commit fails.
+	loaded := s.once.setLoaded(true)
+	err := st.commit("Commit")
+	if err != nil {
+		s.once.setLoaded(loaded)
+		return fmt.Errorf("config: encountered while committing config: %w", err)
+	}
+	return nil
+}
+
+// stage reads the search path of s with ctx and prepares and validates its values, applying the
+// removal policies of s to the values that are gone.
+func (s *store) stage(ctx context.Context) (*Staged, error) {
+	p, result, report, err := s.read(ctx)
+	var schedule []scheduledEntry
+	if err == nil {
+		schedule = splitSchedule(report, result, timeNow())
+		err = s.applyRemovals(result)
+		report.Err = err
+	}
+	if err == nil {
+		err = s.validate(result)
+		report.Err = err
+	}
+	if err == nil {
+		err = s.checkSchedule(schedule)
+		report.Err = err
+	}
+	if report != nil {
+		s.mu.Lock()
+		s.report = report
+		s.mu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Staged{s: s, path: p, val: result, report: report, schedule: schedule}, nil
+}
+
+// read reads the search path of s with ctx and prepares its values, returning the search path it
+// read along with the values and a report of the attempt.
+func (s *store) read(ctx context.Context) (string, map[string]entry, *LoadReport, error) {
+	p, err := s.searchPath()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	result, report, err := loadPath(ctx, p, s.withDefaults(), &s.entries)
+	if err == nil {
+		err = s.prepare(report, result)
+		report.Err = err
+	}
+	return p, result, report, err
+}
+
+// prepare applies the error policy of s, if one was set, to the unreadable files in
+// report, then transforms, decrypts and renders result.
+func (s *store) prepare(report *LoadReport, result map[string]entry) error {
+	for _, sr := range report.Sources {
+		for _, f := range sr.Files {
+			if f.err == nil || !s.errorPolicySet {
+				continue
+			}
+			err := s.fileFailed(report, result, f.Name, entry{path: f.Path}, f.err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := s.transform(report, result)
+	if err != nil {
+		return err
+	}
+
+	if s.pgp != nil {
+		err := s.decryptPGP(report, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.migrate(report, result)
+	if err != nil {
+		return err
+	}
+
+	if s.templates {
+		err := s.render(report, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.extends != "" {
+		err := s.resolveExtends(report, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.overrideKeys(report, result)
+	if err != nil {
+		return err
+	}
+
+	err = s.annotateExpiry(report, result)
+	if err != nil {
+		return err
+	}
+
+	s.seal(result)
+	return nil
+}
+
+// validate checks result against the required values and validators of s, and the
+// registered schemas.
+func (s *store) validate(result map[string]entry) error {
+	for _, n := range s.required {
+		if _, ok := result[n]; !ok {
+			return &ValidationError{Name: n, Err: os.ErrNotExist}
+		}
+	}
+	return s.check(result)
+}
+
+// check runs the validators of s and the registered schemas on the values in result.
+func (s *store) check(result map[string]entry) error {
+	names := make([]string, 0, len(s.validators))
+	for n := range s.validators {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		e, ok := result[n]
+		if !ok {
+			continue
+		}
+		for _, v := range s.validators[n] {
+			err := v(n, e.data)
+			if err != nil {
+				return &ValidationError{Name: n, Path: e.path, Err: err}
+			}
+		}
+	}
+	return checkSchemas(result)
+}
+
+// commit replaces the loaded values of st.s with the staged ones and reloads the tenants
+// derived from it. Subscribers are told that trigger caused the change.
+func (st *Staged) commit(trigger string) error {
+	s := st.s
+	s.mu.Lock()
+	if s.frozen {
+		s.mu.Unlock()
+		return ErrFrozen
+	}
+	prev, prevResolved, prevSchedule, prevErr, prevAt := s.val, s.resolved, s.schedule, s.err, s.loadedAt
+	s.pack(st.val)
+	s.resolved = st.path
+	s.val = st.val
+	s.setSchedule(st.schedule)
+	s.err = nil
+	s.generation++
+	s.loadedAt = time.Now()
+	generation, at := s.generation, s.loadedAt
+	hooks := append([]loadHook(nil), s.onLoad...)
+	tenants := make([]*store, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		tenants = append(tenants, t)
+	}
+	subs := make([]func(ChangeEvent), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.mu.Unlock()
+	s.resetCaches()
+
+	if len(hooks) > 0 {
+		loaded := s.once.setLoaded(true)
+		err := runLoadHooks(hooks)
+		if err != nil {
+			s.once.setLoaded(loaded)
+			s.mu.Lock()
+			if s.generation == generation {
+				s.val, s.resolved, s.err, s.loadedAt = prev, prevResolved, prevErr, prevAt
+				s.setSchedule(prevSchedule)
+				s.generation--
+			}
+			s.mu.Unlock()
+			s.resetCaches()
+			return err
+		}
+	}
+
+	if s.parent == nil {
+		log.Printf("config: files loaded: %v", strings.Join(st.report.loaded(), ", "))
+	}
+	s.warnExpiry(st.val)
+	s.audit(trigger, prev, st, generation, at)
+	if s.snapshot != "" && s.parent == nil && trigger != "Snapshot" {
+		s.saveSnapshot()
+	}
+
+	if prev != nil {
+		if changed := changedNames(prev, st.val); len(changed) > 0 {
+			ev := ChangeEvent{Time: time.Now(), Trigger: trigger, Changed: changed}
+			for _, fn := range subs {
+				fn(ev)
+			}
+		}
+	}
+
+	for _, t := range tenants {
+		err := t.reload(context.Background(), trigger)
+		if err != nil {
+			return fmt.Errorf("config: failed to reload tenant %q: %w", t.tenant, err)
+		}
+	}
+
+	return nil
+}
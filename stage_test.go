@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func validJson(_ string, b []byte) error {
+	var v interface{}
+	return json.Unmarshal(b, &v)
+}
+
+func TestConfig_Stage(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"name":     "old",
+		"app.json": `{"a": 1}`,
+	})
+	c := New(WithPath(dir), WithRequired("name"), WithValidator("app.json", validJson))
+
+	st, err := c.Stage()
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if got, want := st.Names(), []string{"app.json", "name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	err = st.Commit()
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got, _ := c.String("name"); got != "old" {
+		t.Errorf("String() = %q, want %q", got, "old")
+	}
+	if err := st.Commit(); err == nil {
+		t.Errorf("Commit() twice error = nil, want error")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "name"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, err = c.Stage()
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if got, _ := c.String("name"); got != "old" {
+		t.Errorf("String() before Commit() = %q, want %q", got, "old")
+	}
+	if err := st.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got, _ := c.String("name"); got != "new" {
+		t.Errorf("String() after Commit() = %q, want %q", got, "new")
+	}
+}
+
+func TestConfig_Stage_initial(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "staged"})
+	c := New(WithPath(dir))
+
+	st, err := c.Stage()
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "name"), []byte("later"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got, _ := c.String("name"); got != "staged" {
+		t.Errorf("String() = %q, want %q; Load() must not replace committed values", got, "staged")
+	}
+}
+
+func TestConfig_Stage_initialRejected(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"name": "staged"})
+	c := New(WithPath(dir))
+	reject := true
+	c.OnLoad(func(*Config) error {
+		if reject {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	st, err := c.Stage()
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	var hookErr *HookError
+	if err := st.Commit(); !errors.As(err, &hookErr) {
+		t.Fatalf("Commit() error = %v, want a *HookError", err)
+	}
+
+	reject = false
+	if err := ioutil.WriteFile(filepath.Join(dir, "name"), []byte("loaded"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.String("name"); err != nil || got != "loaded" {
+		t.Errorf("String() = %q, %v, want %q; a rejected Commit() must not count as the initial load", got, err, "loaded")
+	}
+}
+
+func TestConfig_Stage_invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "missing",
+			files:    map[string]string{"app.json": `{}`},
+			wantName: "name",
+			wantErr:  os.ErrNotExist,
+		},
+		{
+			name:     "rejected",
+			files:    map[string]string{"name": "x", "app.json": `{`},
+			wantName: "app.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"name": "good", "app.json": `{}`})
+			c := New(WithPath(dir), WithRequired("name"), WithValidator("app.json", validJson))
+			if err := c.Load(); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			for _, n := range []string{"name", "app.json"} {
+				os.Remove(filepath.Join(dir, n))
+			}
+			for n, d := range tt.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, n), []byte(d), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := c.Stage()
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Name != tt.wantName {
+				t.Fatalf("Stage() error = %v, want *ValidationError for %q", err, tt.wantName)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Stage() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err := c.Reload(); !errors.As(err, &ve) {
+				t.Errorf("Reload() error = %v, want *ValidationError", err)
+			}
+			if got, _ := c.String("name"); got != "good" {
+				t.Errorf("String() = %q, want previous value %q", got, "good")
+			}
+		})
+	}
+}
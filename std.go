@@ -0,0 +1,359 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	htmltemplate "html/template"
+	"io"
+	"math/big"
+	"net/url"
+	"sync"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	stdOnce sync.Once
+	std     *Config
+)
+
+// Default returns the *Config used by the package-level functions. It is created with New()
+// on first use.
+func Default() *Config {
+	stdOnce.Do(func() {
+		std = New()
+	})
+	return std
+}
+
+// Load calls Default().Load()
+func Load() error {
+	return Default().Load()
+}
+
+// Reload calls Default().Reload()
+func Reload() error {
+	return Default().Reload()
+}
+
+// Generation calls Default().Generation()
+func Generation() uint64 {
+	return Default().Generation()
+}
+
+// LoadedAt calls Default().LoadedAt()
+func LoadedAt() time.Time {
+	return Default().LoadedAt()
+}
+
+// Stage calls Default().Stage()
+func Stage() (*Staged, error) {
+	return Default().Stage()
+}
+
+// Freeze calls Default().Freeze(trim)
+func Freeze(trim bool) error {
+	return Default().Freeze(trim)
+}
+
+// Verify calls Default().Verify()
+func Verify() (Drift, error) {
+	return Default().Verify()
+}
+
+// Dump calls Default().Dump(w, opts...)
+func Dump(w io.Writer, opts ...DumpOption) error {
+	return Default().Dump(w, opts...)
+}
+
+// WriteEncrypted calls Default().WriteEncrypted(n, v, key)
+func WriteEncrypted(n string, v interface{}, key KeyRef) error {
+	return Default().WriteEncrypted(n, v, key)
+}
+
+// Export calls Default().Export(w)
+func Export(w io.Writer) error {
+	return Default().Export(w)
+}
+
+// Import calls Default().Import(r)
+func Import(r io.Reader) error {
+	return Default().Import(r)
+}
+
+// Expiries calls Default().Expiries()
+func Expiries() ([]Expiry, error) {
+	return Default().Expiries()
+}
+
+// Health calls Default().Health()
+func Health() error {
+	return Default().Health()
+}
+
+// Schedule calls Default().Schedule()
+func Schedule() ([]ScheduledValue, error) {
+	return Default().Schedule()
+}
+
+// Watch calls Default().Watch(ctx)
+func Watch(ctx context.Context) error {
+	return Default().Watch(ctx)
+}
+
+// Report calls Default().Report()
+func Report() LoadReport {
+	return Default().Report()
+}
+
+// ForTenant calls Default().ForTenant(id)
+func ForTenant(id string) *Config {
+	return Default().ForTenant(id)
+}
+
+// Scoped calls Default().Scoped(prefix)
+func Scoped(prefix string) *Config {
+	return Default().Scoped(prefix)
+}
+
+// WithContext calls Default().WithContext(ctx)
+func WithContext(ctx context.Context) *Config {
+	return Default().WithContext(ctx)
+}
+
+// SetDefault calls Default().SetDefault(n, data)
+func SetDefault(n string, data []byte) {
+	Default().SetDefault(n, data)
+}
+
+// SetDefaultString calls Default().SetDefaultString(n, v)
+func SetDefaultString(n string, v string) {
+	Default().SetDefaultString(n, v)
+}
+
+// SetDefaultJson calls Default().SetDefaultJson(n, v)
+func SetDefaultJson(n string, v interface{}) error {
+	return Default().SetDefaultJson(n, v)
+}
+
+// Bytes calls Default().Bytes(n)
+func Bytes(n string) ([]byte, error) {
+	return Default().Bytes(n)
+}
+
+// BytesFresh calls Default().BytesFresh(ctx, n, maxStale)
+func BytesFresh(ctx context.Context, n string, maxStale time.Duration) ([]byte, Freshness, error) {
+	return Default().BytesFresh(ctx, n, maxStale)
+}
+
+// StringFresh calls Default().StringFresh(ctx, n, maxStale)
+func StringFresh(ctx context.Context, n string, maxStale time.Duration) (string, Freshness, error) {
+	return Default().StringFresh(ctx, n, maxStale)
+}
+
+// Register calls Default().Register(n, target, opts...)
+func Register(n string, target interface{}, opts ...BindOption) (*Binding, error) {
+	return Default().Register(n, target, opts...)
+}
+
+// FirstOf calls Default().FirstOf(names...)
+func FirstOf(names ...string) (string, error) {
+	return Default().FirstOf(names...)
+}
+
+// Origin calls Default().Origin(n)
+func Origin(n string) (ValueOrigin, error) {
+	return Default().Origin(n)
+}
+
+// String calls Default().String(n)
+func String(n string) (string, error) {
+	return Default().String(n)
+}
+
+// Int calls Default().Int(n)
+func Int(n string) (int, error) {
+	return Default().Int(n)
+}
+
+// IntInRange calls Default().IntInRange(n, min, max)
+func IntInRange(n string, min, max int) (int, error) {
+	return Default().IntInRange(n, min, max)
+}
+
+// Port calls Default().Port(n)
+func Port(n string) (int, error) {
+	return Default().Port(n)
+}
+
+// Decimal calls Default().Decimal(n)
+func Decimal(n string) (*big.Rat, error) {
+	return Default().Decimal(n)
+}
+
+// Percent calls Default().Percent(n)
+func Percent(n string) (float64, error) {
+	return Default().Percent(n)
+}
+
+// Duration calls Default().Duration(n)
+func Duration(n string) (time.Duration, error) {
+	return Default().Duration(n)
+}
+
+// Endpoints calls Default().Endpoints(n)
+func Endpoints(n string) ([]string, error) {
+	return Default().Endpoints(n)
+}
+
+// RateLimit calls Default().RateLimit(n)
+func RateLimit(n string) (Rate, error) {
+	return Default().RateLimit(n)
+}
+
+// CachePolicy calls Default().CachePolicy(n)
+func CachePolicy(n string) (CacheSpec, error) {
+	return Default().CachePolicy(n)
+}
+
+// PoolSettings calls Default().PoolSettings(n)
+func PoolSettings(n string) (PoolSpec, error) {
+	return Default().PoolSettings(n)
+}
+
+// CORS calls Default().CORS(n)
+func CORS(n string) (CORSPolicy, error) {
+	return Default().CORS(n)
+}
+
+// HTTPServer calls Default().HTTPServer(n)
+func HTTPServer(n string) (HTTPServerSpec, error) {
+	return Default().HTTPServer(n)
+}
+
+// GRPCClient calls Default().GRPCClient(n)
+func GRPCClient(n string) (GRPCClientSpec, error) {
+	return Default().GRPCClient(n)
+}
+
+// Broker calls Default().Broker(n)
+func Broker(n string) (BrokerSpec, error) {
+	return Default().Broker(n)
+}
+
+// MIMETypes calls Default().MIMETypes(n)
+func MIMETypes(n string) (map[string]bool, error) {
+	return Default().MIMETypes(n)
+}
+
+// Enum calls Default().Enum(n, allowed...)
+func Enum(n string, allowed ...string) (string, error) {
+	return Default().Enum(n, allowed...)
+}
+
+// EnumFold calls Default().EnumFold(n, allowed...)
+func EnumFold(n string, allowed ...string) (string, error) {
+	return Default().EnumFold(n, allowed...)
+}
+
+// ExportEnv calls Default().ExportEnv(prefix, names...)
+func ExportEnv(prefix string, names ...string) error {
+	return Default().ExportEnv(prefix, names...)
+}
+
+// Environ calls Default().Environ(prefix, names...)
+func Environ(prefix string, names ...string) ([]string, error) {
+	return Default().Environ(prefix, names...)
+}
+
+// IsSecret calls Default().IsSecret(n)
+func IsSecret(n string) bool {
+	return Default().IsSecret(n)
+}
+
+// IsStale calls Default().IsStale(n)
+func IsStale(n string) bool {
+	return Default().IsStale(n)
+}
+
+// Labels calls Default().Labels(n)
+func Labels(n string) (map[string]string, error) {
+	return Default().Labels(n)
+}
+
+// Userinfo calls Default().Userinfo(n)
+func Userinfo(n string) (*url.Userinfo, error) {
+	return Default().Userinfo(n)
+}
+
+// Url calls Default().Url(n)
+func Url(n string) (*url.URL, error) {
+	return Default().Url(n)
+}
+
+// InterfaceJson calls Default().InterfaceJson(n, v)
+func InterfaceJson(n string, v interface{}) error {
+	return Default().InterfaceJson(n, v)
+}
+
+// RawJson calls Default().RawJson(n, path)
+func RawJson(n string, path string) (json.RawMessage, error) {
+	return Default().RawJson(n, path)
+}
+
+// CSV calls Default().CSV(n)
+func CSV(n string) ([][]string, error) {
+	return Default().CSV(n)
+}
+
+// InterfaceCsv calls Default().InterfaceCsv(n, v)
+func InterfaceCsv(n string, v interface{}) error {
+	return Default().InterfaceCsv(n, v)
+}
+
+// JSONLines calls Default().JSONLines(n, fn)
+func JSONLines(n string, fn func(json.RawMessage) error) error {
+	return Default().JSONLines(n, fn)
+}
+
+// InterfaceYaml calls Default().InterfaceYaml(n, v)
+func InterfaceYaml(n string, v interface{}) error {
+	return Default().InterfaceYaml(n, v)
+}
+
+// Profile calls Default().Profile(n, profile, v)
+func Profile(n, profile string, v interface{}) error {
+	return Default().Profile(n, profile, v)
+}
+
+// Eval calls Default().Eval(n, path, expr)
+func Eval(n, path, expr string) (interface{}, error) {
+	return Default().Eval(n, path, expr)
+}
+
+// EvalInt calls Default().EvalInt(n, path, expr)
+func EvalInt(n, path, expr string) (int, error) {
+	return Default().EvalInt(n, path, expr)
+}
+
+// Group calls Default().Group(pattern)
+func Group(pattern string) *ValueGroup {
+	return Default().Group(pattern)
+}
+
+// Template calls Default().Template(n)
+func Template(n string) (*template.Template, error) {
+	return Default().Template(n)
+}
+
+// HTMLTemplate calls Default().HTMLTemplate(n)
+func HTMLTemplate(n string) (*htmltemplate.Template, error) {
+	return Default().HTMLTemplate(n)
+}
+
+// YamlNode calls Default().YamlNode(n)
+func YamlNode(n string) (*yaml.Node, error) {
+	return Default().YamlNode(n)
+}
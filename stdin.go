@@ -0,0 +1,128 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// StdinPath is the search path entry that reads configuration values from standard input
+// with a StdinSource. For example, CONFIG_PATH="-:/etc/config" overlays the values piped
+// into the process on top of those in /etc/config.
+const StdinPath = "-"
+
+// stdin is the StdinSource read by StdinPath entries. It is shared so that reloading
+// reuses the values read the first time.
+var stdin = Stdin("")
+
+// StdinSource is a Source that provides configuration values read from standard input.
+// Input is read in full the first time the source is read; since it cannot be read again,
+// later reads (e.g. by Reload) provide the same values.
+//
+// Input is either a tar archive, whose regular files are the values, or, if Name is
+// empty, a JSON object, whose members are the values (strings are used as-is, anything
+// else as JSON). Kubernetes ConfigMap and Secret objects, as printed by
+// `kubectl get configmap -o json`, provide their data. If Name is not empty, input that is
+// not a tar archive is the single value named Name.
+type StdinSource struct {
+	// Name is the name of the value if input is a single file.
+	Name string
+	// Reader is read instead of os.Stdin if it is not nil.
+	Reader io.Reader
+
+	once sync.Once
+	fs   []File
+	err  error
+}
+
+// Stdin returns a StdinSource that provides input that is not a bundle as the value named
+// name.
+func Stdin(name string) *StdinSource {
+	return &StdinSource{Name: name}
+}
+
+// WithStdin adds a StdinSource that provides input that is not a bundle as the value named
+// name. See WithSource.
+func WithStdin(name string) Option {
+	return WithSource(Stdin(name))
+}
+
+func (s *StdinSource) Files(context.Context) ([]File, error) {
+	s.once.Do(func() {
+		r := s.Reader
+		if r == nil {
+			r = os.Stdin
+		}
+
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			s.err = fmt.Errorf("config: failed to read %s: %w", s, err)
+			return
+		}
+
+		s.fs, err = parseStdin(s.Name, b)
+		if err != nil {
+			s.err = fmt.Errorf("config: failed to parse %s: %w", s, err)
+			return
+		}
+		for i := range s.fs {
+			s.fs[i].Path = s.String() + "#" + s.fs[i].Name
+		}
+	})
+	return append([]File(nil), s.fs...), s.err
+}
+
+func (s *StdinSource) String() string {
+	return "stdin"
+}
+
+// parseStdin parses b, the input of a StdinSource named n.
+func parseStdin(n string, b []byte) ([]File, error) {
+	switch {
+	case isTar(b):
+		return parseExecOutput(b)
+	case n != "":
+		return []File{{Name: n, Data: b}}, nil
+	}
+
+	var obj struct {
+		Kind       string            `json:"kind"`
+		Data       map[string]string `json:"data"`
+		BinaryData map[string]string `json:"binaryData"`
+	}
+	if json.Unmarshal(b, &obj) != nil || (obj.Kind != "ConfigMap" && obj.Kind != "Secret") {
+		return parseExecOutput(b)
+	}
+
+	var result []File
+	for k, v := range obj.Data {
+		d := []byte(v)
+		if obj.Kind == "Secret" {
+			var err error
+			d, err = base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid data %q in %s: %w", k, obj.Kind, err)
+			}
+		}
+		result = append(result, File{Name: k, Data: d})
+	}
+	for k, v := range obj.BinaryData {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binaryData %q in %s: %w", k, obj.Kind, err)
+		}
+		result = append(result, File{Name: k, Data: d})
+	}
+	return result, nil
+}
+
+// isTar reports whether b begins with a POSIX or GNU tar header.
+func isTar(b []byte) bool {
+	return len(b) >= 262 && bytes.Equal(b[257:262], []byte("ustar"))
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStdinSource(t *testing.T) {
+	var tarball bytes.Buffer
+	w := tar.NewWriter(&tarball)
+	for _, n := range []string{"a", "b"} {
+		if err := w.WriteHeader(&tar.Header{Name: n, Mode: 0644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		n       string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "tar", input: tarball.String(), want: map[string]string{"a": "a", "b": "b"}},
+		{name: "named tar", n: "app.yaml", input: tarball.String(), want: map[string]string{"a": "a", "b": "b"}},
+		{name: "named", n: "app.yaml", input: "port: 80\n", want: map[string]string{"app.yaml": "port: 80\n"}},
+		{name: "json", input: `{"name": "app", "port": 80}`, want: map[string]string{"name": "app", "port": "80"}},
+		{name: "configmap", input: `{"kind": "ConfigMap", "data": {"name": "app"}, "binaryData": {"logo": "AAE="}}`, want: map[string]string{"name": "app", "logo": "\x00\x01"}},
+		{name: "secret", input: `{"kind": "Secret", "data": {"password": "aHVudGVyMg=="}}`, want: map[string]string{"password": "hunter2"}},
+		{name: "secret invalid", input: `{"kind": "Secret", "data": {"password": "hunter2"}}`, wantErr: true},
+		{name: "unnamed", input: "port: 80\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StdinSource{Name: tt.n, Reader: strings.NewReader(tt.input)}
+			for i := 0; i < 2; i++ {
+				fs, err := s.Files(context.Background())
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Files() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr {
+					return
+				}
+
+				got := map[string]string{}
+				for _, f := range fs {
+					got[f.Name] = string(f.Data)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Files() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStdinSource_pathEntry(t *testing.T) {
+	defer func(s *StdinSource) { stdin = s }(stdin)
+	stdin = &StdinSource{Reader: strings.NewReader(`{"name": "piped"}`)}
+
+	c := New(WithPath(StdinPath))
+	if got, err := c.String("name"); err != nil || got != "piped" {
+		t.Fatalf("String() = %q, %v, want %q", got, err, "piped")
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, err := c.String("name"); err != nil || got != "piped" {
+		t.Errorf("String() after Reload() = %q, %v, want %q", got, err, "piped")
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of names suggested by a *NotFoundError.
+const maxSuggestions = 3
+
+// NotFoundError is returned when there is no configuration value with the requested name.
+// It matches ErrNotFound and wraps os.ErrNotExist, so it can be detected with errors.Is
+// and either of them.
+type NotFoundError struct {
+	Name        string   // Name is the name that was requested.
+	Suggestions []string // Suggestions are loaded names similar to Name, most similar first.
+}
+
+func (e *NotFoundError) Error() string {
+	msg := fmt.Sprintf("config: no config entry with name %q", e.Name)
+	if len(e.Suggestions) == 0 {
+		return msg
+	}
+
+	quoted := make([]string, len(e.Suggestions))
+	for i, s := range e.Suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return msg + "; did you mean " + strings.Join(quoted, " or ") + "?"
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return os.ErrNotExist
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// notFound returns a *NotFoundError for n, suggesting the names visible to c that are
+// within a small edit distance of it.
+func (c *Config) notFound(n string) error {
+	type candidate struct {
+		name string
+		dist int
+	}
+
+	limit := len(n) / 3
+	if limit < 1 {
+		limit = 1
+	}
+
+	var candidates []candidate
+	for _, name := range c.s.names() {
+		if !strings.HasPrefix(name, c.prefix) {
+			continue
+		}
+		name = strings.TrimPrefix(name, c.prefix)
+		if d := editDistance(strings.ToLower(n), strings.ToLower(name)); d <= limit {
+			candidates = append(candidates, candidate{name: name, dist: d})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dist < candidates[j].dist
+	})
+
+	result := &NotFoundError{Name: n}
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		result.Suggestions = append(result.Suggestions, candidates[i].name)
+	}
+	return result
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestNotFoundError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"user.json":       "{}",
+		"users.json":      "{}",
+		"db.url":          "postgres://db",
+		"billing.retries": "3",
+	})
+	c := New(WithPath(dir))
+
+	tests := []struct {
+		name string
+		c    *Config
+		n    string
+		want []string
+	}{
+		{name: "typo", c: c, n: "usr.json", want: []string{"user.json", "users.json"}},
+		{name: "case", c: c, n: "DB.URL", want: []string{"db.url"}},
+		{name: "scoped", c: c.Scoped("billing."), n: "retry", want: nil},
+		{name: "scoped typo", c: c.Scoped("billing."), n: "retires", want: []string{"retries"}},
+		{name: "unrelated", c: c, n: "password", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.c.Bytes(tt.n)
+			if !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("Bytes() error = %v, want %v", err, os.ErrNotExist)
+			}
+			var nf *NotFoundError
+			if !errors.As(err, &nf) {
+				t.Fatalf("Bytes() error = %v, want %T", err, nf)
+			}
+			if !reflect.DeepEqual(nf.Suggestions, tt.want) {
+				t.Errorf("Bytes() suggestions = %q, want %q", nf.Suggestions, tt.want)
+			}
+		})
+	}
+
+	_, err := c.Bytes("usr.json")
+	if want := `config: no config entry with name "usr.json"; did you mean "user.json" or "users.json"?`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"user.json", "usr.json", 1},
+		{"héllo", "hello", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// TemplateExt is the file name extension of configuration values that are rendered when
+// template rendering is enabled with WithTemplates.
+const TemplateExt = ".tmpl"
+
+// WithTemplates enables template rendering. Every configuration value whose name ends in
+// TemplateExt is executed as a text/template after loading, and the result is provided
+// under the name without the extension. The unrendered template remains available under
+// its own name.
+//
+// Templates can use the functions in funcs in addition to a set compatible with
+// consul-template and gomplate:
+//		env "NAME"                   the environment variable NAME
+//		file "/path"                 the contents of a file on disk
+//		key "name"                   the configuration value name
+//		keyOrDefault "name" "value"  the configuration value name, or value if there is none
+//		secret "name"                the configuration value name, with surrounding whitespace trimmed
+//		GetPrivateIP                 the first private IPv4 address of the host
+//		GetInterfaceIP "eth0"        the first IPv4 address of a network interface
+//		toLower, toUpper, trimSpace, contains, split, join, replaceAll,
+//		base64Encode, base64Decode, toJSON, parseJSON
+func WithTemplates(funcs template.FuncMap) Option {
+	return func(o *options) {
+		o.templates = true
+		o.templateFuncs = funcs
+	}
+}
+
+// Template parses configuration value n as a text/template named n. If n is a pattern as
+// accepted by path.Match (e.g. "email_*.tmpl"), every matching value is parsed as an
+// associated template named after the value, and the returned template is empty; use
+// ExecuteTemplate to execute them. Fails with an error wrapping os.ErrNotExist if no
+// values match.
+//
+// The result is cached until the matching values change or c is reloaded, so it is shared
+// between callers and must not be modified. Use Clone to add functions or templates.
+func (c *Config) Template(n string) (*template.Template, error) {
+	fs, src, err := c.templateFiles(n)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.s.cachedParse("template", c.fullName(n), src, func(string) (interface{}, error) {
+		result := template.New(n)
+		for _, f := range fs {
+			t := result
+			if f.Name != n {
+				t = result.New(f.Name)
+			}
+			_, err := t.Parse(string(f.Data))
+			if err != nil {
+				return nil, errorf(ErrDecode, "config: failed to parse template %s: %w", f.Name, err)
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*template.Template), nil
+}
+
+// HTMLTemplate is like Template, but parses the values as html/template templates, which
+// escape the data they are executed with. Use Clone on the result before adding to it;
+// html/template cannot clone templates that have been executed, so clone before executing
+// when both are needed.
+func (c *Config) HTMLTemplate(n string) (*htmltemplate.Template, error) {
+	fs, src, err := c.templateFiles(n)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.s.cachedParse("htmltemplate", c.fullName(n), src, func(string) (interface{}, error) {
+		result := htmltemplate.New(n)
+		for _, f := range fs {
+			t := result
+			if f.Name != n {
+				t = result.New(f.Name)
+			}
+			_, err := t.Parse(string(f.Data))
+			if err != nil {
+				return nil, errorf(ErrDecode, "config: failed to parse template %s: %w", f.Name, err)
+			}
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*htmltemplate.Template), nil
+}
+
+// templateFiles returns the configuration values matched by pattern n, along with a
+// string identifying their current contents.
+func (c *Config) templateFiles(n string) ([]File, string, error) {
+	names := []string{n}
+	if strings.ContainsAny(n, `*?[\`) {
+		_, err := path.Match(n, "")
+		if err != nil {
+			return nil, "", fmt.Errorf("config: invalid template pattern %q: %w", n, err)
+		}
+
+		err = c.Load()
+		if err != nil {
+			return nil, "", fmt.Errorf("config: failed to get value %q because there was a load error: %w", n, err)
+		}
+
+		names = nil
+		for _, name := range c.s.names() {
+			if !strings.HasPrefix(name, c.prefix) {
+				continue
+			}
+			if ok, _ := path.Match(n, name[len(c.prefix):]); ok {
+				names = append(names, name[len(c.prefix):])
+			}
+		}
+		if len(names) == 0 {
+			return nil, "", errorf(ErrNotFound, "config: no config entries match %q: %w", n, os.ErrNotExist)
+		}
+	}
+
+	var result []File
+	var src strings.Builder
+	for _, name := range names {
+		e, err := c.lookup(name)
+		if err != nil {
+			return nil, "", err
+		}
+		result = append(result, File{Name: name, Path: e.path, Data: e.data})
+		fmt.Fprintf(&src, "%d:%s%d:%s", len(name), name, len(e.data), e.data)
+	}
+	return result, src.String(), nil
+}
+
+// render renders the templates in result, adding the rendered values to it. Values
+// referenced by templates are looked up in result, then in s.parent. Templates that cannot
+// be rendered are handled according to the error policy of s.
+func (s *store) render(report *LoadReport, result map[string]entry) error {
+	lookup := func(n string) (entry, error) {
+		if e, ok := result[n]; ok {
+			return e, nil
+		}
+		if e, ok := s.parent.get(n); ok {
+			return e, nil
+		}
+		return entry{}, errorf(ErrNotFound, "config: template references missing value %q: %w", n, os.ErrNotExist)
+	}
+
+	funcs := templateFuncs(lookup)
+	for k, v := range s.templateFuncs {
+		funcs[k] = v
+	}
+
+	rendered := map[string]entry{}
+	for n, e := range result {
+		if !strings.HasSuffix(n, TemplateExt) {
+			continue
+		}
+
+		name := strings.TrimSuffix(n, TemplateExt)
+		t, err := template.New(n).Funcs(funcs).Parse(string(e.data))
+		if err != nil {
+			err = s.fileFailed(report, rendered, name, e, fmt.Errorf("failed to parse template: %w", err))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		var buf bytes.Buffer
+		err = t.Execute(&buf, nil)
+		if err != nil {
+			err = s.fileFailed(report, rendered, name, e, fmt.Errorf("failed to render template: %w", err))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if prev, ok := result[name]; ok {
+			return &DuplicateError{
+				Name:    name,
+				Paths:   [2]string{prev.path, e.path},
+				Indices: [2]int{prev.index, e.index},
+			}
+		}
+		rendered[name] = entry{data: buf.Bytes(), path: e.path, index: e.index}
+	}
+
+	for n, e := range rendered {
+		result[n] = e
+	}
+	return nil
+}
+
+// templateFuncs returns the built-in template functions, resolving configuration values
+// with lookup.
+func templateFuncs(lookup func(n string) (entry, error)) template.FuncMap {
+	return template.FuncMap{
+		"env": os.Getenv,
+		"file": func(p string) (string, error) {
+			b, err := ioutil.ReadFile(p)
+			return string(b), err
+		},
+		"key": func(n string) (string, error) {
+			e, err := lookup(n)
+			return string(e.data), err
+		},
+		"keyOrDefault": func(n, def string) string {
+			e, err := lookup(n)
+			if err != nil {
+				return def
+			}
+			return string(e.data)
+		},
+		"secret": func(n string) (string, error) {
+			e, err := lookup(n)
+			return strings.TrimSpace(string(e.data)), err
+		},
+		"GetPrivateIP":   getPrivateIP,
+		"GetInterfaceIP": getInterfaceIP,
+		"toLower":        strings.ToLower,
+		"toUpper":        strings.ToUpper,
+		"trimSpace":      strings.TrimSpace,
+		"contains":       func(substr, s string) bool { return strings.Contains(s, substr) },
+		"split":          func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":           func(sep string, a []string) string { return strings.Join(a, sep) },
+		"replaceAll":     func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+		"base64Encode":   func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"base64Decode": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+		"toJSON": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"parseJSON": func(s string) (interface{}, error) {
+			var result interface{}
+			err := json.Unmarshal([]byte(s), &result)
+			return result, err
+		},
+	}
+}
+
+// privateNetworks are the RFC 1918 and RFC 6598 address blocks.
+var privateNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, result, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func getPrivateIP() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || ipnet.IP.To4() == nil {
+			continue
+		}
+		for _, n := range privateNetworks {
+			if n.Contains(ipnet.IP) {
+				return ipnet.IP.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no private IP address found")
+}
+
+func getInterfaceIP(name string) (string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return "", err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			return ipnet.IP.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no IPv4 address found on interface %q", name)
+}
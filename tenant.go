@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TenantDir is the directory, within each search path directory, that holds the
+// configuration values of individual tenants. The values of tenant id are read from
+// TenantDir/id.
+const TenantDir = "tenants"
+
+// ForTenant returns a view of c for tenant id. Values are resolved from the TenantDir/id
+// directory of each search path directory, falling back to the shared values of c. Tenant
+// directories are optional; a tenant without any is served entirely from c.
+//
+// Tenant views are cached, so repeated calls with the same id share loaded values and
+// caches. They are reloaded whenever c is reloaded. If id is not a valid directory name,
+// loading the returned view fails.
+func (c *Config) ForTenant(id string) *Config {
+	s := c.s
+	t := &store{options: s.options, parent: s, tenant: id}
+	t.sources = nil
+	t.required = nil
+	if !validTenant(id) {
+		return &Config{s: t, prefix: c.prefix, values: c.values}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.tenants[id]; ok {
+		t = cached
+	} else {
+		if s.tenants == nil {
+			s.tenants = map[string]*store{}
+		}
+		s.tenants[id] = t
+	}
+
+	return &Config{s: t, prefix: c.prefix, values: c.values}
+}
+
+// tenantPath returns the search path for tenant id, consisting of the tenant directories
+// that exist within the directories s was loaded from.
+func (s *store) tenantPath(id string) (string, error) {
+	if !validTenant(id) {
+		return "", fmt.Errorf("config: invalid tenant id %q", id)
+	}
+
+	s.mu.RLock()
+	resolved := s.resolved
+	s.mu.RUnlock()
+
+	var dirs []string
+	for _, p := range splitPath(resolved) {
+		d := filepath.Join(p, TenantDir, id)
+		if fi, err := os.Stat(d); err == nil && fi.IsDir() {
+			dirs = append(dirs, d)
+		}
+	}
+	return strings.Join(dirs, string(os.PathListSeparator)), nil
+}
+
+// validTenant reports whether id can be used as a single directory name.
+func validTenant(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfig_ForTenant(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/db_url":              "postgres://shared",
+		"a/timeout":             "1s",
+		"a/tenants/acme/db_url": "postgres://acme",
+		"b/tenants/acme/theme":  "red",
+		"b/other":               "other",
+	})
+	p := strings.Join([]string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, string(os.PathListSeparator))
+	c := New(WithPath(p))
+
+	acme := c.ForTenant("acme")
+	tests := []struct {
+		c    *Config
+		n    string
+		want string
+	}{
+		{acme, "db_url", "postgres://acme"},
+		{acme, "theme", "red"},
+		{acme, "timeout", "1s"},
+		{acme, "other", "other"},
+		{c.ForTenant("globex"), "db_url", "postgres://shared"},
+		{c, "db_url", "postgres://shared"},
+	}
+	for _, tt := range tests {
+		got, err := tt.c.String(tt.n)
+		if err != nil || got != tt.want {
+			t.Errorf("String(%q) = %q, %v, want %q", tt.n, got, err, tt.want)
+		}
+	}
+
+	if _, err := c.Bytes("theme"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Bytes() error = %v, want %v", err, os.ErrNotExist)
+	}
+	if got := c.ForTenant("acme"); got.s != acme.s {
+		t.Errorf("ForTenant() did not reuse the cached tenant")
+	}
+	if err := c.ForTenant("../a").Load(); err == nil {
+		t.Errorf("Load() error = %v, wantErr %v", err, true)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "a", "tenants", "acme", "db_url"), []byte("postgres://acme2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, err := acme.String("db_url"); err != nil || got != "postgres://acme2" {
+		t.Errorf("String() after Reload() = %q, %v, want %q", got, err, "postgres://acme2")
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// tlsEntries is the tls block of structured entries such as HTTPServer and GRPCClient. Cert,
+// Key and CA are the names of the configuration values holding the PEM encoded certificate
+// chain, private key and CA certificates.
+type tlsEntries struct {
+	Cert       string `yaml:"cert"`
+	Key        string `yaml:"key"`
+	CA         string `yaml:"ca"`
+	ServerName string `yaml:"serverName"`
+}
+
+// tlsConfig builds a *tls.Config from the configuration values named by t. The certificate
+// and key must be given together. The CA certificates, if any, replace the system roots.
+func (c *Config) tlsConfig(t tlsEntries) (*tls.Config, error) {
+	result := &tls.Config{ServerName: t.ServerName}
+
+	if (t.Cert == "") != (t.Key == "") {
+		return nil, fmt.Errorf("tls needs both cert and key")
+	}
+	if t.Cert != "" {
+		cert, err := c.Bytes(t.Cert)
+		if err != nil {
+			return nil, err
+		}
+		key, err := c.Bytes(t.Key)
+		if err != nil {
+			return nil, err
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		result.Certificates = []tls.Certificate{pair}
+	}
+
+	if t.CA != "" {
+		ca, err := c.Bytes(t.CA)
+		if err != nil {
+			return nil, err
+		}
+		result.RootCAs = x509.NewCertPool()
+		if !result.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("tls: no certificates found in %s", t.CA)
+		}
+	}
+	return result, nil
+}
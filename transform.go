@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sort"
+)
+
+// Transformer rewrites the data of configuration value name after it is read. It returns
+// the new data, or an error if the value cannot be transformed. Transformers must return
+// b unchanged for values they do not apply to.
+type Transformer func(name string, b []byte) ([]byte, error)
+
+// WithTransformer adds t to the transformers of the Config. Every loaded value is passed
+// through the transformers in the order they were added, before PGP decryption, template
+// rendering and validation, and before anything is cached. Values that a transformer fails
+// on are handled according to the ErrorPolicy.
+//
+// Transformers compose, so decompression, decryption and normalization can be layered:
+//
+//		c := config.New(
+//			config.WithSecretBox("CONFIG_KEY"),
+//			config.WithTransformer(config.Gunzip),
+//		)
+func WithTransformer(t Transformer) Option {
+	return func(o *options) {
+		o.transformers = append(o.transformers, t)
+	}
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Gunzip is a Transformer that decompresses gzip-compressed values. Values that do not
+// start with the gzip header are returned unchanged.
+func Gunzip(_ string, b []byte) ([]byte, error) {
+	if !bytes.HasPrefix(b, gzipMagic) {
+		return b, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+	d, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+	return d, nil
+}
+
+// transform passes the values of result through the transformers of s. Values that cannot
+// be transformed are handled according to the error policy of s.
+func (s *store) transform(report *LoadReport, result map[string]entry) error {
+	if len(s.transformers) == 0 {
+		return nil
+	}
+
+	// Transform in a fixed order so that failures are reported consistently.
+	names := make([]string, 0, len(result))
+	for n := range result {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		e := result[n]
+		d := e.data
+		var err error
+		for _, t := range s.transformers {
+			d, err = t(n, d)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			err = s.fileFailed(report, result, n, e, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		e.data = d
+		result[n] = e
+	}
+	return nil
+}
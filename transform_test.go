@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func gzipString(t *testing.T, s string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestWithTransformer(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compressed": gzipString(t, "  Hello  "),
+		"plain":      "World",
+	})
+
+	var seen []string
+	upper := func(n string, b []byte) ([]byte, error) {
+		seen = append(seen, n)
+		return bytes.ToUpper(b), nil
+	}
+	c := New(WithPath(dir), WithTrimSpace(false), WithTransformer(Gunzip), WithTransformer(upper))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "compressed", want: "  HELLO  "},
+		{name: "plain", want: "WORLD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.String(tt.name)
+			if err != nil || got != tt.want {
+				t.Errorf("String() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	if strings.Join(seen, ",") != "compressed,plain" {
+		t.Errorf("transformer called for %v, want [compressed plain]", seen)
+	}
+}
+
+func TestWithTransformer_error(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"bad":  "\x1f\x8bnot gzip",
+		"good": "good",
+	})
+
+	_, err := New(WithPath(dir), WithTransformer(Gunzip)).String("good")
+	var fe *FileError
+	if !errors.As(err, &fe) || fe.Name != "bad" {
+		t.Errorf("String() error = %v, want *FileError for %q", err, "bad")
+	}
+
+	c := New(WithPath(dir), WithTransformer(Gunzip), WithErrorPolicy(SkipOnError))
+	if got, err := c.String("good"); err != nil || got != "good" {
+		t.Errorf("String() = %q, %v, want %q", got, err, "good")
+	}
+	if _, err := c.Bytes("bad"); err == nil {
+		t.Errorf("Bytes() error = %v, wantErr %v", err, true)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TTLSource is a Source that caches the files of another Source for TTL. Reads within TTL
+// of the last successful fetch are served from the cache, so an expensive source, such as
+// a secret store, is not fetched again when a reload is triggered by a change elsewhere.
+// Once TTL has passed, Changed notifies Watch, which reloads the Config and so fetches
+// the source again. Other sources, such as the search path directories, are unaffected
+// and reload only on their own changes.
+//
+// If the wrapped Source implements Notifier, its notifications are passed through, and
+// also invalidate the cache. A TTL that is not positive disables caching.
+type TTLSource struct {
+	Source Source
+	TTL    time.Duration
+
+	mu        sync.Mutex
+	cached    []File
+	fetchedAt time.Time // fetchedAt is when the source was last fetched, successfully or not.
+	ok        bool      // ok is true if cached holds the files of a successful fetch.
+
+	now func() time.Time
+}
+
+// TTL returns a TTLSource that caches the files of src for ttl.
+func TTL(src Source, ttl time.Duration) *TTLSource {
+	return &TTLSource{Source: src, TTL: ttl}
+}
+
+func (s *TTLSource) Files(ctx context.Context) ([]File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ok && s.clock().Sub(s.fetchedAt) < s.TTL {
+		return append([]File(nil), s.cached...), nil
+	}
+
+	fs, err := s.Source.Files(ctx)
+	s.fetchedAt = s.clock()
+	if err != nil {
+		return nil, err
+	}
+	s.cached, s.ok = append([]File(nil), fs...), true
+	return fs, nil
+}
+
+// Expire discards the cached files, so the next read fetches the source again.
+func (s *TTLSource) Expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ok = false
+}
+
+// expireOlderThan discards the cached files if they were fetched more than d ago.
+func (s *TTLSource) expireOlderThan(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clock().Sub(s.fetchedAt) > d {
+		s.ok = false
+	}
+}
+
+// Changed implements Notifier. The channel is closed once the cached files expire, or when
+// the wrapped source reports a change, if it implements Notifier. After a failed fetch, it
+// is closed TTL after the failure, so the source is retried at the same rate.
+func (s *TTLSource) Changed() <-chan struct{} {
+	var inner <-chan struct{}
+	if n, ok := s.Source.(Notifier); ok {
+		inner = n.Changed()
+	}
+
+	s.mu.Lock()
+	remaining := s.TTL - s.clock().Sub(s.fetchedAt)
+	s.mu.Unlock()
+	if s.TTL <= 0 {
+		return inner
+	}
+
+	ch := make(chan struct{})
+	go func() {
+		defer close(ch)
+		t := time.NewTimer(remaining)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-inner:
+			s.Expire()
+		}
+	}()
+	return ch
+}
+
+func (s *TTLSource) String() string {
+	return describeSource(s.Source)
+}
+
+func (s *TTLSource) clock() time.Time {
+	if s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTTLSource(t *testing.T) {
+	src := &flakySource{}
+	now := time.Unix(0, 0)
+	s := &TTLSource{Source: src, TTL: time.Minute, now: func() time.Time { return now }}
+
+	read := func(wantErr bool, wantReads int) {
+		t.Helper()
+		_, err := s.Files(context.Background())
+		if (err != nil) != wantErr || src.reads != wantReads {
+			t.Errorf("Files() error = %v, reads = %d, wantErr %v, want %d reads", err, src.reads, wantErr, wantReads)
+		}
+	}
+
+	read(false, 1)
+	now = now.Add(30 * time.Second)
+	read(false, 1)
+	now = now.Add(30 * time.Second)
+	read(false, 2)
+
+	s.Expire()
+	src.err = errors.New("down")
+	read(true, 3)
+	read(true, 4)
+	src.err = nil
+	read(false, 5)
+}
+
+func TestTTLSource_Changed(t *testing.T) {
+	s := TTL(&flakySource{}, 20*time.Millisecond)
+	if _, err := s.Files(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-s.Changed():
+	case <-time.After(time.Second):
+		t.Fatal("Changed() was not notified after the TTL")
+	}
+
+	if n, ok := interface{}(TTL(&flakySource{}, 0)).(Notifier); !ok || n.Changed() != nil {
+		t.Errorf("Changed() with caching disabled = %v, want nil", n)
+	}
+}
+
+func TestTTLSource_Watch(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"local": "1"})
+	src := &flakySource{}
+	c := New(WithPath(dir), WithSource(TTL(src, 50*time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_ = c.Watch(ctx)
+
+	if src.reads < 3 {
+		t.Errorf("source was read %d times, want it to be refetched every TTL", src.reads)
+	}
+}
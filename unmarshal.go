@@ -0,0 +1,483 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The interfaces that decide how values are decoded. See unmarshalText and isTextType.
+var (
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	flagValueType         = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	jsonUnmarshalerType   = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	yamlUnmarshalerType   = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	timeType              = reflect.TypeOf(time.Time{})
+)
+
+// unmarshalText sets v, which must be addressable, from s with the first of the
+// encoding.TextUnmarshaler, flag.Value and encoding.BinaryUnmarshaler methods it has. It
+// reports false if it has none of them.
+func unmarshalText(v reflect.Value, s string) (bool, error) {
+	switch u := v.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return true, u.UnmarshalText([]byte(s))
+	case flag.Value:
+		return true, u.Set(s)
+	case encoding.BinaryUnmarshaler:
+		return true, u.UnmarshalBinary([]byte(s))
+	}
+	return false, nil
+}
+
+// isTextType reports whether values of type t are decoded with unmarshalText in format
+// ("json" or "yaml") rather than by the decoder of the format.
+func isTextType(t reflect.Type, format string) bool {
+	p := reflect.PtrTo(t)
+	if !p.Implements(textUnmarshalerType) && !p.Implements(flagValueType) && !p.Implements(binaryUnmarshalerType) {
+		return false
+	}
+	switch format {
+	case "json":
+		return !p.Implements(jsonUnmarshalerType)
+	case "yaml":
+		return !p.Implements(yamlUnmarshalerType) && t != timeType
+	}
+	return true
+}
+
+type textTypeKey struct {
+	typ    reflect.Type
+	format string
+}
+
+// hasTextTypes caches the results of hasTextType by textTypeKey.
+var hasTextTypes sync.Map
+
+// hasTextType reports whether decoding a value of type t in format involves values that
+// are decoded with unmarshalText.
+func hasTextType(t reflect.Type, format string) bool {
+	k := textTypeKey{typ: t, format: format}
+	if v, ok := hasTextTypes.Load(k); ok {
+		return v.(bool)
+	}
+	result := findTextType(t, format, map[reflect.Type]bool{})
+	hasTextTypes.Store(k, result)
+	return result
+}
+
+func findTextType(t reflect.Type, format string, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	if isTextType(t, format) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(t, format) {
+			if findTextType(f.typ, format, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		return findTextType(t.Elem(), format, seen)
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && findTextType(t.Elem(), format, seen)
+	}
+	return false
+}
+
+// namedField is a field of a struct as it is named in a format.
+type namedField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+}
+
+// structFields returns the fields of struct type t as they are named in format, including
+// the promoted fields of embedded structs, shallowest first.
+func structFields(t reflect.Type, format string) []namedField {
+	var result, embedded []namedField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(format)
+		name := strings.Split(tag, ",")[0]
+		if tag == "-" || f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		inline := format == "yaml" && strings.Contains(tag, ",inline") || format == "json" && f.Anonymous && name == ""
+		if inline && ft.Kind() == reflect.Struct {
+			for _, sf := range structFields(ft, format) {
+				sf.index = append([]int{i}, sf.index...)
+				embedded = append(embedded, sf)
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+			if format == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+		result = append(result, namedField{name: name, index: []int{i}, typ: f.Type})
+	}
+	return append(result, embedded...)
+}
+
+// lookupField returns the field of fields that a key of a document in format sets.
+func lookupField(fields []namedField, key, format string) (namedField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	if format == "json" {
+		for _, f := range fields {
+			if strings.EqualFold(f.name, key) {
+				return f, true
+			}
+		}
+	}
+	return namedField{}, false
+}
+
+// textValue is a value of a document that is decoded with unmarshalText.
+type textValue struct {
+	path []pathElem
+	set  func(v reflect.Value) error
+}
+
+// pathElem is a step from a value to one it contains: a struct field, a slice element or
+// a map entry.
+type pathElem struct {
+	field []int
+	index int
+	key   *string
+}
+
+// textExtractor removes the values decoded with unmarshalText from a generic document.
+type textExtractor struct {
+	format string
+	values []textValue
+}
+
+// extract records the values of doc, which is decoded into a value of type t found at path,
+// that are decoded with unmarshalText, removing them from doc. It reports whether doc is
+// itself such a value, in which case the caller must remove it.
+func (x *textExtractor) extract(doc interface{}, t reflect.Type, path []pathElem, name string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if doc == nil {
+		return false
+	}
+
+	if isTextType(t, x.format) {
+		x.record(path, name, func(v reflect.Value) error { return setText(v, doc) })
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		fields := structFields(t, x.format)
+		for k, item := range m {
+			f, ok := lookupField(fields, k, x.format)
+			if !ok {
+				continue
+			}
+			if x.extract(item, f.typ, append(path[:len(path):len(path)], pathElem{field: f.index}), joinField(name, k)) {
+				delete(m, k)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := doc.([]interface{})
+		if !ok {
+			return false
+		}
+		if elem := derefType(t.Elem()); isTextType(elem, x.format) {
+			x.record(path, name, func(v reflect.Value) error { return setTextSlice(v, items) })
+			return true
+		}
+		for i, item := range items {
+			x.extract(item, t.Elem(), append(path[:len(path):len(path)], pathElem{index: i}), joinField(name, strconv.Itoa(i)))
+		}
+	case reflect.Map:
+		m, ok := doc.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			return false
+		}
+		if elem := derefType(t.Elem()); isTextType(elem, x.format) {
+			x.record(path, name, func(v reflect.Value) error { return setTextMap(v, m) })
+			return true
+		}
+		for k, item := range m {
+			k := k
+			x.extract(item, t.Elem(), append(path[:len(path):len(path)], pathElem{key: &k}), joinField(name, k))
+		}
+	}
+	return false
+}
+
+// record records a value found at path, named name, that set decodes.
+func (x *textExtractor) record(path []pathElem, name string, set func(v reflect.Value) error) {
+	x.values = append(x.values, textValue{path: path, set: func(v reflect.Value) error {
+		err := set(v)
+		if err != nil && name != "" {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return err
+	}})
+}
+
+// derefType returns the type that t points to, following all pointers.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// scalarText returns the text of scalar doc.
+func scalarText(doc interface{}) (string, error) {
+	switch d := doc.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+		return "", fmt.Errorf("cannot decode %s into a text value", jsonType(d))
+	case string:
+		return d, nil
+	case time.Time:
+		return d.Format(time.RFC3339Nano), nil
+	}
+	return fmt.Sprint(doc), nil
+}
+
+// setText sets v, allocating pointers as needed, from scalar doc.
+func setText(v reflect.Value, doc interface{}) error {
+	s, err := scalarText(doc)
+	if err != nil {
+		return err
+	}
+	v = allocPtr(v)
+	_, err = unmarshalText(v, s)
+	return err
+}
+
+// setTextSlice sets slice or array v from the scalars of items.
+func setTextSlice(v reflect.Value, items []interface{}) error {
+	v = allocPtr(v)
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+	}
+	for i, item := range items {
+		if i >= v.Len() {
+			break
+		}
+		if item == nil {
+			continue
+		}
+		err := setText(v.Index(i), item)
+		if err != nil {
+			return fmt.Errorf("%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// setTextMap sets map v from the scalars of m.
+func setTextMap(v reflect.Value, m map[string]interface{}) error {
+	v = allocPtr(v)
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(m)))
+	}
+	for k, item := range m {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if item != nil {
+			err := setText(elem, item)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+		v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), elem)
+	}
+	return nil
+}
+
+// allocPtr follows the pointers from v, allocating the nil ones, and returns the value
+// they point to.
+func allocPtr(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// setPath calls set with the value at path from v, which must be addressable, allocating
+// pointers and map entries along the way.
+func setPath(v reflect.Value, path []pathElem, set func(v reflect.Value) error) error {
+	if len(path) == 0 {
+		return set(v)
+	}
+
+	v = allocPtr(v)
+	p := path[0]
+	switch {
+	case p.field != nil:
+		for _, i := range p.field {
+			v = allocPtr(v).Field(i)
+		}
+		return setPath(v, path[1:], set)
+	case p.key != nil:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		k := reflect.ValueOf(*p.key).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if prev := v.MapIndex(k); prev.IsValid() {
+			elem.Set(prev)
+		}
+		err := setPath(elem, path[1:], set)
+		v.SetMapIndex(k, elem)
+		return err
+	default:
+		if p.index >= v.Len() {
+			return nil
+		}
+		return setPath(v.Index(p.index), path[1:], set)
+	}
+}
+
+// decodeText decodes doc, a generic document in format, into v, a non-nil pointer, with
+// unmarshalText for the values that need it and with decode for the rest, which is given
+// the remaining document.
+func decodeText(doc interface{}, v interface{}, format string, decode func(doc interface{}) error) error {
+	x := textExtractor{format: format}
+	rv := reflect.ValueOf(v)
+	if !x.extract(doc, rv.Type().Elem(), nil, "") {
+		err := decode(doc)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tv := range x.values {
+		err := setPath(rv.Elem(), tv.path, tv.set)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTextTarget reports whether decoding into v in format involves values that are decoded
+// with unmarshalText. v must be a non-nil pointer for it to be true.
+func isTextTarget(v interface{}, format string) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && !rv.IsNil() && hasTextType(rv.Type(), format)
+}
+
+// decodeJsonText decodes e, the entry for configuration value n, into v as JSON with
+// decodeText.
+func (c *Config) decodeJsonText(n string, e entry, v interface{}) error {
+	data := e.data
+	if c.s.conditions != nil {
+		var err error
+		data, err = filterJson(e.data, c.s.conditions)
+		if err != nil {
+			return newJsonError(n, e, v, err)
+		}
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err := dec.Decode(&doc)
+	if err == nil && !json.Valid(data) {
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return newJsonError(n, e, v, err)
+	}
+
+	err = decodeText(doc, v, "json", func(doc interface{}) error {
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, v)
+	})
+	if err != nil {
+		// Offsets into the re-encoded document do not correspond to the file.
+		return newDecodeError(n, e, v, 0, 0, err)
+	}
+	return nil
+}
+
+// decodeYamlText decodes e, the entry for configuration value n, into v as YAML with
+// decodeText.
+func (c *Config) decodeYamlText(n string, e entry, v interface{}) error {
+	node := &yaml.Node{}
+	if _, ok := c.anchors(n); ok || c.s.conditions != nil {
+		var err error
+		node, err = c.yamlNode(n, e, v)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := yaml.Unmarshal(e.data, node)
+		if err != nil {
+			return newYamlError(n, e, v, err)
+		}
+	}
+
+	var doc interface{}
+	if node.Kind != 0 {
+		err := node.Decode(&doc)
+		if err != nil {
+			return newYamlError(n, e, v, err)
+		}
+	}
+
+	err := decodeText(doc, v, "yaml", func(doc interface{}) error {
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		return yaml.Unmarshal(b, v)
+	})
+	if err != nil {
+		// Lines of the re-encoded document do not correspond to the file.
+		return newDecodeError(n, e, v, 0, 0, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unmarshalLevel implements encoding.TextUnmarshaler.
+type unmarshalLevel int
+
+func (l *unmarshalLevel) UnmarshalText(b []byte) error {
+	switch strings.ToLower(string(b)) {
+	case "debug", "0":
+		*l = 0
+	case "info", "1":
+		*l = 1
+	case "error", "2":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", b)
+	}
+	return nil
+}
+
+// unmarshalID implements flag.Value.
+type unmarshalID struct {
+	prefix string
+	n      string
+}
+
+func (id *unmarshalID) String() string { return id.prefix + "-" + id.n }
+
+func (id *unmarshalID) Set(s string) error {
+	i := strings.IndexByte(s, '-')
+	if i < 0 {
+		return errors.New("missing prefix")
+	}
+	id.prefix, id.n = s[:i], s[i+1:]
+	return nil
+}
+
+// unmarshalBlob implements encoding.BinaryUnmarshaler.
+type unmarshalBlob []byte
+
+func (b *unmarshalBlob) UnmarshalBinary(data []byte) error {
+	*b = append(unmarshalBlob(nil), data...)
+	return nil
+}
+
+type unmarshalBase struct {
+	Owner unmarshalID `json:"owner" yaml:"owner"`
+}
+
+type unmarshalConfig struct {
+	unmarshalBase `yaml:",inline"`
+
+	Name     string                     `json:"name" yaml:"name"`
+	Level    unmarshalLevel             `json:"level" yaml:"level"`
+	Levels   []unmarshalLevel           `json:"levels" yaml:"levels"`
+	ByName   map[string]*unmarshalLevel `json:"byName" yaml:"byName"`
+	ID       *unmarshalID               `json:"id" yaml:"id"`
+	Blob     unmarshalBlob              `json:"blob" yaml:"blob"`
+	Since    time.Time                  `json:"since" yaml:"since"`
+	Services []struct {
+		ID   unmarshalID `json:"id" yaml:"id"`
+		Port int         `json:"port" yaml:"port"`
+	} `json:"services" yaml:"services"`
+}
+
+func TestConfig_Interface_textTypes(t *testing.T) {
+	info, errLevel := unmarshalLevel(1), unmarshalLevel(2)
+	want := unmarshalConfig{
+		unmarshalBase: unmarshalBase{Owner: unmarshalID{prefix: "team", n: "7"}},
+		Name:          "svc",
+		Level:         1,
+		Levels:        []unmarshalLevel{0, 2},
+		ByName:        map[string]*unmarshalLevel{"a": &info, "b": &errLevel},
+		ID:            &unmarshalID{prefix: "svc", n: "42"},
+		Blob:          unmarshalBlob("raw"),
+		Since:         time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	want.Services = append(want.Services, struct {
+		ID   unmarshalID `json:"id" yaml:"id"`
+		Port int         `json:"port" yaml:"port"`
+	}{ID: unmarshalID{prefix: "db", n: "1"}, Port: 5432})
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		want    unmarshalConfig
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: "c.yaml",
+			data: "owner: team-7\nname: svc\nlevel: INFO\nlevels: [debug, 2]\nbyName: {a: 1, b: error}\nid: svc-42\nblob: raw\nsince: 2026-01-02\nservices:\n  - {id: db-1, port: 5432}\n",
+			want: want,
+		},
+		{
+			name: "json",
+			file: "c.json",
+			data: `{"owner": "team-7", "name": "svc", "level": 1, "levels": ["debug", 2], "byName": {"a": "info", "b": 2}, "id": "svc-42", "blob": "raw", "since": "2026-01-02T00:00:00Z", "services": [{"id": "db-1", "port": 5432}]}`,
+			want: want,
+		},
+		{name: "yaml error", file: "c.yaml", data: "levels: [debug, loud]\n", wantErr: `levels: 1: unknown level "loud"`},
+		{name: "json error", file: "c.json", data: `{"services": [{"id": "nodash"}]}`, wantErr: "services.0.id: missing prefix"},
+		{name: "not a scalar", file: "c.yaml", data: "level: {a: 1}\n", wantErr: "level: cannot decode object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithPath(writeFiles(t, map[string]string{tt.file: tt.data})))
+			var got unmarshalConfig
+			var err error
+			if strings.HasSuffix(tt.file, ".json") {
+				err = c.InterfaceJson(tt.file, &got)
+			} else {
+				err = c.InterfaceYaml(tt.file, &got)
+			}
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrDecode) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Interface() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Interface() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Interface() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_InterfaceCsv_flagValue(t *testing.T) {
+	c := New(WithPath(writeFiles(t, map[string]string{"ids.csv": "id,level\nsvc-1,info\n"})))
+	var got []struct {
+		ID    unmarshalID
+		Level unmarshalLevel
+	}
+	if err := c.InterfaceCsv("ids.csv", &got); err != nil {
+		t.Fatalf("InterfaceCsv() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != (unmarshalID{prefix: "svc", n: "1"}) || got[0].Level != 1 {
+		t.Errorf("InterfaceCsv() got = %+v", got)
+	}
+}
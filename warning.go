@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// WarningKind classifies a Warning.
+type WarningKind string
+
+const (
+	WarnSkipped       WarningKind = "skipped"        // WarnSkipped is a file or directory that was not loaded, such as a subdirectory.
+	WarnShadowed      WarningKind = "shadowed"       // WarnShadowed is a file hidden by one with the same name; see Shadow.
+	WarnFileError     WarningKind = "file-error"     // WarnFileError is a *FileError tolerated because of the ErrorPolicy.
+	WarnNearDuplicate WarningKind = "near-duplicate" // WarnNearDuplicate is a pair of names that differ only in case or in a .yml/.yaml extension.
+	WarnOverride      WarningKind = "override"       // WarnOverride is an environment override that matches no value.
+	WarnExpiry        WarningKind = "expiry"         // WarnExpiry is a value that has expired or will soon; see Expiries.
+	WarnDeprecated    WarningKind = "deprecated"     // WarnDeprecated is a read of a deprecated value; see WithDeprecated.
+	WarnStale         WarningKind = "stale"          // WarnStale is a value kept after its file disappeared; see KeepMissing.
+	WarnMigrated      WarningKind = "migrated"       // WarnMigrated is a value found under its old name; see WithMigration.
+	WarnSnapshot      WarningKind = "snapshot"       // WarnSnapshot is a snapshot that could not be read or written; see WithSnapshot.
+)
+
+// Warning is a non-fatal issue found while loading or reading a Config. See WithWarnings.
+type Warning struct {
+	Kind    WarningKind
+	Name    string // Name is the name of the configuration value concerned, if any.
+	Path    string // Path is the file concerned, if any.
+	Message string // Message describes the issue.
+}
+
+func (w Warning) String() string {
+	return w.Message
+}
+
+// WithWarnings registers fn to be called with each Warning, so an application can route
+// them to its own logging or alerting. fn may be called concurrently and while loading, so
+// it should not block or use the Config. Without a handler, the warnings that the package
+// has always logged, such as shadowed files, are logged with the log package, and the
+// others are dropped; with one, nothing is logged.
+func WithWarnings(fn func(Warning)) Option {
+	return func(o *options) {
+		o.warnings = append(o.warnings, fn)
+	}
+}
+
+// WithDeprecated marks configuration value n as deprecated. The first read of n after each
+// load produces a WarnDeprecated Warning with message msg, such as "use db.yaml instead".
+func WithDeprecated(n, msg string) Option {
+	return func(o *options) {
+		if o.deprecated == nil {
+			o.deprecated = map[string]string{}
+		}
+		o.deprecated[n] = msg
+	}
+}
+
+// warn passes w to the warning handlers of o. If there are none, w is logged if logged is
+// true.
+func (o *options) warn(w Warning, logged bool) {
+	if len(o.warnings) == 0 {
+		if logged {
+			log.Printf("config: %s", w.Message)
+		}
+		return
+	}
+	for _, fn := range o.warnings {
+		fn(w)
+	}
+}
+
+// warnSkipped reports the files skipped by a search path directory. Unreadable files are
+// reported by the ErrorPolicy instead.
+func (o *options) warnSkipped(fs []FileReport) {
+	for _, f := range fs {
+		if f.Skipped != "" && f.err == nil {
+			o.warn(Warning{Kind: WarnSkipped, Name: f.Name, Path: f.Path, Message: fmt.Sprintf("skipped %s: %s", f.Path, f.Skipped)}, false)
+		}
+	}
+}
+
+// warnNearDuplicates reports the names in result that differ only in case or in a .yml or
+// .yaml extension, which usually means one of them is a mistake.
+func (o *options) warnNearDuplicates(result map[string]entry) {
+	groups := map[string][]string{}
+	for n := range result {
+		k := strings.ToLower(n)
+		if strings.HasSuffix(k, ".yml") {
+			k = strings.TrimSuffix(k, ".yml") + ".yaml"
+		}
+		groups[k] = append(groups[k], n)
+	}
+
+	for _, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		o.warn(Warning{
+			Kind:    WarnNearDuplicate,
+			Name:    names[0],
+			Path:    result[names[0]].path,
+			Message: fmt.Sprintf("config entries %s are near-duplicates", strings.Join(names, ", ")),
+		}, false)
+	}
+}
+
+// warnDeprecated reports the first read of configuration value n after each load, if it
+// is deprecated.
+func (s *store) warnDeprecated(n string) {
+	msg, ok := s.deprecated[n]
+	if !ok {
+		return
+	}
+	if _, warned := s.deprecatedRead.LoadOrStore(n, true); warned {
+		return
+	}
+	s.warn(Warning{Kind: WarnDeprecated, Name: n, Message: fmt.Sprintf("%s is deprecated: %s", n, msg)}, false)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWithWarnings(t *testing.T) {
+	first := writeFiles(t, map[string]string{
+		"db.yml":      "host: a",
+		"DB.yaml":     "host: b",
+		"old.json":    "{}",
+		"shared":      "first",
+		"sub/ignored": "x",
+	})
+	second := writeFiles(t, map[string]string{"shared": "second"})
+
+	if err := os.Setenv("CFGWARN__MISSING__KEY", "1"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("CFGWARN__MISSING__KEY")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	var got []Warning
+	c := New(
+		WithPath(first+string(os.PathListSeparator)+second),
+		WithShadow(true),
+		WithOverridePrefix("CFGWARN"),
+		WithDeprecated("old.json", "use new.json instead"),
+		WithWarnings(func(w Warning) { got = append(got, w) }),
+	)
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Bytes("old.json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var kinds []string
+	for _, w := range got {
+		kinds = append(kinds, string(w.Kind))
+	}
+	sort.Strings(kinds)
+	want := []string{"deprecated", "near-duplicate", "override", "shadowed", "skipped"}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("warning kinds = %v, want %v (warnings: %+v)", kinds, want, got)
+	}
+	if strings.Contains(buf.String(), "shadows") {
+		t.Errorf("log = %q, want warnings to go to the handler only", buf.String())
+	}
+
+	buf.Reset()
+	if err := New(WithPath(first+string(os.PathListSeparator)+second), WithShadow(true)).Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "shadows") || strings.Contains(buf.String(), "near-duplicate") {
+		t.Errorf("log = %q, want only the logged warnings without a handler", buf.String())
+	}
+}
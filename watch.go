@@ -0,0 +1,330 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notifier is implemented by sources that can report changes to their files.
+type Notifier interface {
+	// Changed returns a channel that is closed or receives a value when the files returned
+	// by the most recent call to Files may have changed.
+	Changed() <-chan struct{}
+}
+
+// WithPollInterval makes Watch check the directories on the search path for changes every
+// d, in addition to waiting for notifications from sources. Changes are detected by
+// comparing the names, sizes and modification times of the files, following symbolic
+// links. Polling is disabled if d is not positive, which is the default.
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = d
+	}
+}
+
+// ChangeEvent describes a change to the loaded configuration values.
+type ChangeEvent struct {
+	Time time.Time // Time is when the new values were committed.
+
+	// Trigger describes what caused the change: the description of the Source that
+	// reported it, "poll" if it was detected by polling the search path, "Schedule" if a
+	// scheduled value took effect, "Refresh" if BytesFresh or StringFresh reloaded stale
+	// values, or the name of the method (Reload or Commit) that was called.
+	Trigger string
+
+	// Changed are the sorted names of the values that were added, removed or modified.
+	Changed []string
+}
+
+// OnChange registers fn to be called whenever a reload changes the values of c, however
+// the reload was triggered: by Watch, in response to a Notifier or polling, or by calling
+// Reload or Commit. The initial load is not reported. For views created with Scoped, only
+// values within the view are reported, by their names within it. fn is called
+// synchronously after the new values are visible, so it should not block. Call the
+// returned function to stop receiving events.
+func (c *Config) OnChange(fn func(ChangeEvent)) (cancel func()) {
+	prefix := c.prefix
+	sub := func(ev ChangeEvent) {
+		var changed []string
+		for _, n := range ev.Changed {
+			if strings.HasPrefix(n, prefix) {
+				changed = append(changed, n[len(prefix):])
+			}
+		}
+		if len(changed) > 0 {
+			ev.Changed = changed
+			fn(ev)
+		}
+	}
+
+	s := c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subs == nil {
+		s.subs = map[int]func(ChangeEvent){}
+	}
+	id := s.nextSub
+	s.nextSub++
+	s.subs[id] = sub
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+// changedNames returns the sorted names of the values that differ between prev and next.
+func changedNames(prev, next map[string]entry) []string {
+	var result []string
+	for n, e := range next {
+		if p, ok := prev[n]; !ok || !bytes.Equal(p.data, e.data) || !bytes.Equal(p.packed, e.packed) {
+			result = append(result, n)
+		}
+	}
+	for n := range prev {
+		if _, ok := next[n]; !ok {
+			result = append(result, n)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Watch reloads c whenever one of its sources that implements Notifier reports a change,
+// or, if a poll interval is set with WithPollInterval, whenever polling detects a change
+// to the directories on the search path. It blocks until ctx is done and then returns
+// ctx.Err(), or ErrFrozen once a change is detected after c is frozen. Reload errors are
+// logged, the previously loaded values are kept, and the reload is retried with
+// exponential backoff until it succeeds. Watch also switches to scheduled versions of
+// values as they take effect; see Schedule. Use OnChange to be told about the changes.
+func (c *Config) Watch(ctx context.Context) error {
+	err := c.Load()
+	if err != nil {
+		log.Printf("config: %v", err)
+	}
+
+	var tick <-chan time.Time
+	var sig string
+	if c.s.pollInterval > 0 {
+		t := time.NewTicker(c.s.pollInterval)
+		defer t.Stop()
+		tick = t.C
+		sig = c.s.dirSignature()
+	}
+
+	// A reload can fail before a source has replaced the channel that reported its
+	// change, so channels that fired for a failed reload are not selected on again until
+	// a reload succeeds. The reload is retried after a delay instead.
+	fired := map[<-chan struct{}]bool{}
+	var retry *time.Timer
+	var retryTrigger string
+	var backoff time.Duration
+	defer func() {
+		if retry != nil {
+			retry.Stop()
+		}
+	}()
+
+	for {
+		var timer *time.Timer
+		var scheduled <-chan time.Time
+		if next := c.s.nextSchedule(); !next.IsZero() {
+			timer = time.NewTimer(next.Sub(timeNow()))
+			scheduled = timer.C
+		}
+		var retried <-chan time.Time
+		if retry != nil {
+			retried = retry.C
+		}
+
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(tick)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(scheduled)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(retried)},
+		}
+		var triggers []string
+		var changed []<-chan struct{}
+		for _, src := range c.s.notifiers() {
+			ch := src.(Notifier).Changed()
+			if fired[ch] {
+				ch = nil
+			}
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+			triggers = append(triggers, describeSource(src))
+			changed = append(changed, ch)
+		}
+
+		i, _, _ := reflect.Select(cases)
+		if timer != nil {
+			timer.Stop()
+		}
+		trigger := "poll"
+		switch i {
+		case 0:
+			return ctx.Err()
+		case 1:
+			next := c.s.dirSignature()
+			if next == sig {
+				continue
+			}
+			sig = next
+		case 2:
+			err := c.s.switchSchedule()
+			if errors.Is(err, ErrFrozen) {
+				return ErrFrozen
+			}
+			if err != nil {
+				log.Printf("%v", err)
+			}
+			continue
+		case 3:
+			retry, trigger = nil, retryTrigger
+		default:
+			trigger = triggers[i-4]
+		}
+
+		err := c.reload(trigger)
+		if errors.Is(err, ErrFrozen) {
+			return ErrFrozen
+		}
+		if err == nil {
+			fired, backoff = map[<-chan struct{}]bool{}, 0
+			if retry != nil {
+				retry.Stop()
+				retry = nil
+			}
+			continue
+		}
+
+		log.Printf("config: %v", err)
+		if i >= 4 {
+			fired[changed[i-4]] = true
+		}
+		if retry == nil {
+			backoff = nextBackoff(backoff)
+			retry, retryTrigger = time.NewTimer(backoff), trigger
+		}
+	}
+}
+
+// Delays between the retries of a failed reload by Watch.
+var (
+	watchMinBackoff = 100 * time.Millisecond
+	watchMaxBackoff = time.Minute
+)
+
+// nextBackoff returns the delay before retrying a failed reload that follows a delay of d.
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d < watchMinBackoff {
+		d = watchMinBackoff
+	}
+	if d > watchMaxBackoff {
+		d = watchMaxBackoff
+	}
+	return d
+}
+
+// notifiers returns the Sources of the search path entries and the sources of s that
+// implement Notifier.
+func (s *store) notifiers() []Source {
+	s.mu.RLock()
+	resolved := s.resolved
+	s.mu.RUnlock()
+
+	var result []Source
+	for _, p := range splitPath(resolved) {
+		p, _ = optionalPath(p)
+		if src, ok := s.entries.lookup(p); ok {
+			if _, ok := src.(Notifier); ok {
+				result = append(result, src)
+			}
+		}
+	}
+	for _, src := range s.sources {
+		if _, ok := src.Source.(Notifier); ok {
+			result = append(result, src.Source)
+		}
+	}
+	return result
+}
+
+// dirSignature returns a string that changes whenever the files in the directories, or
+// the files themselves, on the search path that s was loaded from change.
+func (s *store) dirSignature() string {
+	s.mu.RLock()
+	resolved := s.resolved
+	s.mu.RUnlock()
+
+	var sb strings.Builder
+	for _, p := range splitPath(resolved) {
+		p, _ = optionalPath(p)
+		src, err := pathSource(p)
+		d, ok := src.(dirSource)
+		if err != nil || !ok {
+			continue
+		}
+		p = string(d)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			fmt.Fprintf(&sb, "%s %d %d\n", p, fi.Size(), fi.ModTime().UnixNano())
+			continue
+		}
+		err = walkDir(p, s.maxDepth, s.prune, func(n, _ string, fi os.FileInfo, _ string) {
+			fmt.Fprintf(&sb, "%s/%s %d %d\n", p, n, fi.Size(), fi.ModTime().UnixNano())
+		})
+		if err != nil {
+			fmt.Fprintf(&sb, "%s: %v\n", p, err)
+		}
+	}
+	return sb.String()
+}
+
+// changeNotifier implements Notifier for sources that learn about changes asynchronously.
+// Sources call reset before reading their files and notify when a change is observed.
+type changeNotifier struct {
+	mu     sync.Mutex
+	ch     chan struct{}
+	closed bool
+}
+
+// reset replaces the channel returned by Changed with a new one. Changes observed after
+// reset are reported on the new channel.
+func (n *changeNotifier) reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.ch, n.closed = make(chan struct{}), false
+}
+
+// notify closes the channel returned by Changed, if it is not already closed.
+func (n *changeNotifier) notify() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.ch != nil && !n.closed {
+		close(n.ch)
+		n.closed = true
+	}
+}
+
+func (n *changeNotifier) Changed() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+	return n.ch
+}
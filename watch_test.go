@@ -0,0 +1,222 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pushSource is a Source that reports changes to its values through Notifier, like a
+// remote provider with push notifications.
+type pushSource struct {
+	mu   sync.Mutex
+	vals map[string]string
+	changeNotifier
+}
+
+func (s *pushSource) Files(context.Context) ([]File, error) {
+	s.reset()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return staticSource(s.vals).Files(context.Background())
+}
+
+func (s *pushSource) String() string {
+	return "push"
+}
+
+func (s *pushSource) set(n, v string) {
+	s.mu.Lock()
+	s.vals[n] = v
+	s.mu.Unlock()
+	s.notify()
+}
+
+// pushEntries holds the pushSources of "push-test" search path entries, keyed by host.
+var pushEntries sync.Map
+
+func init() {
+	RegisterSource("push-test", func(u *url.URL) (Source, error) {
+		src, _ := pushEntries.LoadOrStore(u.Host, &pushSource{vals: map[string]string{}})
+		return src.(*pushSource), nil
+	})
+}
+
+func TestConfig_OnChange(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"db/url":  "postgres://a",
+		"timeout": "1s",
+		"removed": "x",
+	})
+	c := New(WithPath(filepath.Join(dir, "db")+string(os.PathListSeparator)+dir), WithShadow(true))
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var got []ChangeEvent
+	cancel := c.OnChange(func(ev ChangeEvent) { got = append(got, ev) })
+	var scoped []ChangeEvent
+	c.Scoped("time").OnChange(func(ev ChangeEvent) { scoped = append(scoped, ev) })
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "timeout"), []byte("2s"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "removed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if len(got) != 1 || got[0].Trigger != "Reload" || !reflect.DeepEqual(got[0].Changed, []string{"removed", "timeout"}) {
+		t.Errorf("OnChange() events = %+v, want one Reload event changing [removed timeout]", got)
+	}
+	if len(scoped) != 1 || !reflect.DeepEqual(scoped[0].Changed, []string{"out"}) {
+		t.Errorf("Scoped().OnChange() events = %+v, want one event changing [out]", scoped)
+	}
+
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	cancel()
+	if err := ioutil.WriteFile(filepath.Join(dir, "url"), []byte("postgres://b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("OnChange() events = %+v, want no events for unchanged values or after cancel", got)
+	}
+}
+
+func TestConfig_Watch_events(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"timeout": "1s"})
+	push := &pushSource{vals: map[string]string{"db_url": "postgres://a"}}
+	c := New(WithPath(dir), WithSource(push), WithPollInterval(10*time.Millisecond))
+
+	events := make(chan ChangeEvent, 10)
+	c.OnChange(func(ev ChangeEvent) { events <- ev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Watch(ctx) }()
+
+	waitFor := func(wantTrigger, wantName string) {
+		t.Helper()
+		select {
+		case ev := <-events:
+			if ev.Trigger != wantTrigger || !reflect.DeepEqual(ev.Changed, []string{wantName}) {
+				t.Errorf("OnChange() event = %+v, want trigger %q changing [%s]", ev, wantTrigger, wantName)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("OnChange() not called for %s", wantName)
+		}
+	}
+
+	// Wait for Watch to load the values before changing them.
+	for deadline := time.Now().Add(5 * time.Second); c.Report().Sources == nil; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not load the values")
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	push.set("db_url", "postgres://b")
+	waitFor("push", "db_url")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "timeout"), []byte("2s\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("poll", "timeout")
+}
+
+// countingHook is a ReloadHook that counts failed reloads.
+type countingHook struct {
+	mu     sync.Mutex
+	failed int
+}
+
+func (h *countingHook) Reloading() {}
+
+func (h *countingHook) Reloaded(err error) {
+	if err != nil {
+		h.mu.Lock()
+		h.failed++
+		h.mu.Unlock()
+	}
+}
+
+func (h *countingHook) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failed
+}
+
+func TestConfig_Watch_failedReload(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"timeout": "1s"})
+	push := &pushSource{vals: map[string]string{"db_url": "postgres://a"}}
+	hook := &countingHook{}
+	c := New(WithPath(dir), WithSource(push), WithReloadHook(hook))
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Watch(ctx) }()
+
+	// The search path is read before push, so push does not replace its channel when
+	// the reload fails.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	push.set("db_url", "postgres://b")
+	time.Sleep(250 * time.Millisecond)
+	if got := hook.count(); got < 1 || got > 5 {
+		t.Errorf("Watch() failed reloads = %d, want between 1 and 5", got)
+	}
+
+	// Once the search path is readable again, the retry succeeds.
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if got, _ := c.String("db_url"); got == "postgres://b" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not retry the failed reload")
+		}
+	}
+}
+
+func TestConfig_Watch_pathEntry(t *testing.T) {
+	push := &pushSource{vals: map[string]string{"db_url": "postgres://a"}}
+	pushEntries.Store("watch", push)
+	c := New(WithPath("push-test://watch"))
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Watch(ctx) }()
+	time.Sleep(20 * time.Millisecond)
+
+	push.set("db_url", "postgres://b")
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if got, _ := c.String("db_url"); got == "postgres://b" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not reload when a search path entry changed")
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyRef encrypts configuration values written by WriteEncrypted. SecretBoxKey and
+// PGPRecipients are provided; other schemes, such as a cloud KMS or age, can be supported
+// by implementing KeyRef along with a Transformer (see WithTransformer) that decrypts the
+// values again when they are loaded.
+type KeyRef interface {
+	// Seal encrypts plaintext.
+	Seal(plaintext []byte) ([]byte, error)
+	// Ext returns the extension added to the names of the files holding values sealed with
+	// the key, such as ".asc", or "" if values keep their names.
+	Ext() string
+}
+
+// SecretBoxKey returns a KeyRef that encrypts values with SealSecretBox, using the
+// base64-encoded 32-byte key in environment variable env. Values written with it are
+// decrypted by WithSecretBox(env).
+func SecretBoxKey(env string) KeyRef {
+	return secretBoxKey(env)
+}
+
+type secretBoxKey string
+
+func (k secretBoxKey) Seal(plaintext []byte) ([]byte, error) {
+	v, ok := os.LookupEnv(string(k))
+	if !ok {
+		return nil, fmt.Errorf("config: secretbox key environment variable %s is not set", string(k))
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to decode secretbox key from %s: %w", string(k), err)
+	}
+	return SealSecretBox(key, plaintext)
+}
+
+func (k secretBoxKey) Ext() string {
+	return ""
+}
+
+// PGPRecipients returns a KeyRef that encrypts values to every public key in keyringFile,
+// a binary or ASCII armored OpenPGP keyring, producing ASCII armored messages. Values
+// written with it are stored with the ".asc" extension and decrypted by WithPGP.
+func PGPRecipients(keyringFile string) KeyRef {
+	return pgpRecipients(keyringFile)
+}
+
+type pgpRecipients string
+
+func (k pgpRecipients) Seal(plaintext []byte) ([]byte, error) {
+	b, err := ioutil.ReadFile(string(k))
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read pgp keyring: %w", err)
+	}
+	to, err := readKeyring(b)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to parse pgp keyring %q: %w", string(k), err)
+	}
+
+	var buf bytes.Buffer
+	aw, err := armor.Encode(&buf, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := openpgp.Encrypt(aw, to, nil, nil, nil)
+	if err == nil {
+		_, err = pw.Write(plaintext)
+	}
+	if err == nil {
+		err = pw.Close()
+	}
+	if err == nil {
+		err = aw.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to encrypt with pgp keyring %q: %w", string(k), err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (k pgpRecipients) Ext() string {
+	return ".asc"
+}
+
+// WriteEncrypted encrypts v with key and writes it as configuration value n to the first
+// directory of the search path of c, so tools can store secrets without writing the
+// plaintext to disk. Strings and byte slices are written as they are; other values are
+// encoded as JSON if n ends in ".json", and as YAML otherwise. The file is named n
+// followed by key.Ext(), is only readable by its owner, and replaces any previous version
+// atomically. The loaded values of c are not changed until it is reloaded.
+func (c *Config) WriteEncrypted(n string, v interface{}, key KeyRef) error {
+	n = c.prefix + n
+	if n == "" || path.IsAbs(n) || path.Clean(n) != n || n == ".." || strings.HasPrefix(n, "../") {
+		return fmt.Errorf("config: cannot write config entry %q: invalid name", n)
+	}
+
+	dir, err := c.s.writeDir()
+	if err != nil {
+		return fmt.Errorf("config: cannot write config entry %q: %w", n, err)
+	}
+
+	var plaintext []byte
+	switch v := v.(type) {
+	case []byte:
+		plaintext = v
+	case string:
+		plaintext = []byte(v)
+	default:
+		if path.Ext(n) == ".json" {
+			plaintext, err = json.Marshal(v)
+		} else {
+			plaintext, err = yaml.Marshal(v)
+		}
+		if err != nil {
+			return fmt.Errorf("config: failed to encode config entry %q: %w", n, err)
+		}
+	}
+
+	sealed, err := key.Seal(plaintext)
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(dir, filepath.FromSlash(n+key.Ext()))
+	err = writeFileAtomic(f, sealed)
+	if err != nil {
+		return fmt.Errorf("config: failed to write config entry %q: %w", n, err)
+	}
+	return nil
+}
+
+// writeDir returns the first directory of the search path of s.
+func (s *store) writeDir() (string, error) {
+	p, err := s.searchPath()
+	if err != nil {
+		return "", err
+	}
+	for _, e := range splitPath(p) {
+		e, _ = optionalPath(e)
+		if _, ok := pathScheme(e); ok || e == StdinPath {
+			continue
+		}
+		if fi, err := os.Stat(e); err == nil && fi.IsDir() {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("no directory in search path %q", p)
+}
+
+// writeFileAtomic writes b to a temporary file next to f, readable only by its owner, then
+// renames it to f.
+func writeFileAtomic(f string, b []byte) error {
+	err := os.MkdirAll(filepath.Dir(f), 0755)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(f), "."+filepath.Base(f)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Chmod(0600)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f)
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func TestConfig_WriteEncrypted(t *testing.T) {
+	partner, err := openpgp.NewEntity("partner", "", "partner@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := writeFiles(t, map[string]string{"partner.asc": armoredPrivateKey(t, partner)})
+	keyring := filepath.Join(keys, "partner.asc")
+
+	if err := os.Setenv("CONFIG_TEST_WRITE_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("CONFIG_TEST_WRITE_KEY")
+
+	type creds struct {
+		Username string `json:"username" yaml:"username"`
+		Password string `json:"password" yaml:"password"`
+	}
+
+	tests := []struct {
+		name     string
+		entry    string
+		v        interface{}
+		key      KeyRef
+		opt      Option
+		wantFile string
+	}{
+		{name: "secretbox string", entry: "db_password", v: "hunter2", key: SecretBoxKey("CONFIG_TEST_WRITE_KEY"), opt: WithSecretBox("CONFIG_TEST_WRITE_KEY"), wantFile: "db_password"},
+		{name: "secretbox json", entry: "creds.json", v: creds{"app", "hunter2"}, key: SecretBoxKey("CONFIG_TEST_WRITE_KEY"), opt: WithSecretBox("CONFIG_TEST_WRITE_KEY"), wantFile: "creds.json"},
+		{name: "pgp yaml", entry: "nested/creds.yaml", v: creds{"app", "hunter2"}, key: PGPRecipients(keyring), opt: WithPGP(PGPKeys{KeyringFile: keyring}), wantFile: "nested/creds.yaml.asc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, nil)
+			c := New(WithPath("http://unused.invalid:"+dir), WithMaxDepth(1), tt.opt)
+			if err := c.WriteEncrypted(tt.entry, tt.v, tt.key); err != nil {
+				t.Fatalf("WriteEncrypted() error = %v", err)
+			}
+
+			f := filepath.Join(dir, filepath.FromSlash(tt.wantFile))
+			b, err := ioutil.ReadFile(f)
+			if err != nil {
+				t.Fatalf("WriteEncrypted() did not write %s: %v", tt.wantFile, err)
+			}
+			if bytes.Contains(b, []byte("hunter2")) {
+				t.Fatalf("WriteEncrypted() wrote the plaintext: %q", b)
+			}
+			if fi, err := os.Stat(f); err != nil || fi.Mode().Perm() != 0600 {
+				t.Errorf("WriteEncrypted() mode = %v, %v, want %v", fi.Mode().Perm(), err, os.FileMode(0600))
+			}
+
+			c = New(WithPath(dir), WithMaxDepth(1), tt.opt)
+			switch v := tt.v.(type) {
+			case string:
+				if got, err := c.String(tt.entry); err != nil || got != v {
+					t.Errorf("String() = %q, %v, want %q", got, err, v)
+				}
+			default:
+				var got creds
+				if err := c.InterfaceYaml(tt.entry, &got); err != nil || got != v {
+					t.Errorf("InterfaceYaml() = %v, %v, want %v", got, err, v)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig_WriteEncrypted_errors(t *testing.T) {
+	dir := writeFiles(t, nil)
+	key := SecretBoxKey("CONFIG_TEST_WRITE_MISSING")
+
+	tests := []struct {
+		name  string
+		path  string
+		entry string
+	}{
+		{name: "escapes directory", path: dir, entry: "../secret"},
+		{name: "absolute", path: dir, entry: "/etc/secret"},
+		{name: "no directory", path: filepath.Join(dir, "missing"), entry: "secret"},
+		{name: "missing key", path: dir, entry: "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithPath(tt.path))
+			if err := c.WriteEncrypted(tt.entry, "x", key); err == nil {
+				t.Error("WriteEncrypted() error = nil")
+			}
+		})
+	}
+	if fs, _ := ioutil.ReadDir(dir); len(fs) != 0 {
+		t.Errorf("WriteEncrypted() left files behind: %v", fs)
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+)
+
+// ZooKeeperConn is the subset of a ZooKeeper client used by ZooKeeperSource. Each method
+// sets a one-shot watch and returns a channel that receives a value when it fires. For
+// github.com/go-zookeeper/zk, an adapter looks like this:
+//		type zkConn struct{ *zk.Conn }
+//
+//		func (c zkConn) ChildrenW(p string) ([]string, <-chan struct{}, error) {
+//			children, _, events, err := c.Conn.ChildrenW(p)
+//			return children, notify(events), err
+//		}
+//
+//		func (c zkConn) GetW(p string) ([]byte, bool, <-chan struct{}, error) {
+//			data, _, events, err := c.Conn.GetW(p)
+//			if err == zk.ErrNoNode {
+//				return nil, false, nil, nil
+//			}
+//			return data, err == nil, notify(events), err
+//		}
+//
+//		func notify(events <-chan zk.Event) <-chan struct{} {
+//			result := make(chan struct{})
+//			go func() { <-events; close(result) }()
+//			return result
+//		}
+type ZooKeeperConn interface {
+	// ChildrenW returns the names of the children of the znode at p.
+	ChildrenW(p string) ([]string, <-chan struct{}, error)
+	// GetW returns the data of the znode at p, and false if it does not exist.
+	GetW(p string) ([]byte, bool, <-chan struct{}, error)
+}
+
+// ZooKeeperSource is a Source that provides the data of the children of the znode at Root,
+// named by their znode names. Grandchildren are ignored, like subdirectories of search path
+// directories. Children deleted while they are read are skipped. ZooKeeperSource
+// implements Notifier, so Watch reloads the Config when a child is added, removed or
+// changed.
+type ZooKeeperSource struct {
+	Conn ZooKeeperConn
+	Root string
+
+	mu      sync.Mutex
+	changed chan struct{}
+	stop    chan struct{}
+}
+
+// ZooKeeper returns a ZooKeeperSource for the children of root.
+func ZooKeeper(conn ZooKeeperConn, root string) *ZooKeeperSource {
+	return &ZooKeeperSource{Conn: conn, Root: root}
+}
+
+func (s *ZooKeeperSource) Files(ctx context.Context) ([]File, error) {
+	children, w, err := s.Conn.ChildrenW(s.Root)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to list zookeeper node %q: %w", s.Root, err)
+	}
+	watches := []<-chan struct{}{w}
+
+	sort.Strings(children)
+	result := make([]File, 0, len(children))
+	for _, n := range children {
+		p := path.Join(s.Root, n)
+		d, ok, w, err := s.Conn.GetW(p)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to read zookeeper node %q: %w", p, err)
+		}
+		if !ok {
+			continue
+		}
+		watches = append(watches, w)
+		result = append(result, File{Name: n, Path: "zk://" + p, Data: d})
+	}
+
+	s.watch(watches)
+	return result, nil
+}
+
+// watch replaces the channel returned by Changed with one that is closed when any of
+// watches fires.
+func (s *ZooKeeperSource) watch(watches []<-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+	}
+	changed, stop := make(chan struct{}), make(chan struct{})
+	s.changed, s.stop = changed, stop
+
+	var once sync.Once
+	for _, w := range watches {
+		go func(w <-chan struct{}) {
+			select {
+			case <-w:
+				once.Do(func() { close(changed) })
+			case <-stop:
+			}
+		}(w)
+	}
+}
+
+func (s *ZooKeeperSource) Changed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.changed
+}
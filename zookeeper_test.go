@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeZooKeeper is an in-memory ZooKeeperConn. ChildrenW also lists the children in
+// deleted, as if they were deleted before GetW is called.
+type fakeZooKeeper struct {
+	mu      sync.Mutex
+	nodes   map[string][]byte
+	deleted []string
+	watches []chan struct{}
+}
+
+func (z *fakeZooKeeper) ChildrenW(p string) ([]string, <-chan struct{}, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	var result []string
+	for n := range z.nodes {
+		if path.Dir(n) == p {
+			result = append(result, path.Base(n))
+		}
+	}
+	for _, n := range z.deleted {
+		if path.Dir(n) == p {
+			result = append(result, path.Base(n))
+		}
+	}
+	return result, z.newWatch(), nil
+}
+
+func (z *fakeZooKeeper) GetW(p string) ([]byte, bool, <-chan struct{}, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	d, ok := z.nodes[p]
+	if !ok {
+		return nil, false, nil, nil
+	}
+	return d, true, z.newWatch(), nil
+}
+
+func (z *fakeZooKeeper) newWatch() chan struct{} {
+	w := make(chan struct{}, 1)
+	z.watches = append(z.watches, w)
+	return w
+}
+
+func (z *fakeZooKeeper) set(p string, d []byte) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	z.nodes[p] = d
+	for _, w := range z.watches {
+		w <- struct{}{}
+	}
+	z.watches = nil
+}
+
+func TestZooKeeperSource(t *testing.T) {
+	zk := &fakeZooKeeper{nodes: map[string][]byte{
+		"/app/config/db_url":   []byte("postgres://zk"),
+		"/app/config/timeout":  []byte("1s"),
+		"/app/other/unrelated": []byte("x"),
+	}}
+	c := New(WithPath(""), WithSource(ZooKeeper(zk, "/app/config")))
+
+	if got, err := c.String("db_url"); err != nil || got != "postgres://zk" {
+		t.Fatalf("String() = %q, %v, want %q", got, err, "postgres://zk")
+	}
+	if _, err := c.Bytes("unrelated"); err == nil {
+		t.Errorf("Bytes() error = %v, wantErr %v", err, true)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.Watch(ctx) }()
+
+	zk.set("/app/config/timeout", []byte("2s"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := c.Duration("timeout")
+		if err == nil && got == 2*time.Second {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Duration() = %v, %v, want %v after change", got, err, 2*time.Second)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestZooKeeperSource_deletedChild(t *testing.T) {
+	zk := &fakeZooKeeper{
+		nodes:   map[string][]byte{"/app/config/db_url": []byte("postgres://zk")},
+		deleted: []string{"/app/config/timeout"},
+	}
+	c := New(WithPath(""), WithSource(ZooKeeper(zk, "/app/config")))
+
+	if got, err := c.String("db_url"); err != nil || got != "postgres://zk" {
+		t.Fatalf("String() = %q, %v, want %q", got, err, "postgres://zk")
+	}
+	if _, err := c.Bytes("timeout"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Bytes() error = %v, want %v for a deleted child", err, ErrNotFound)
+	}
+}